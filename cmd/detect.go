@@ -12,6 +12,7 @@ import (
 	"github.com/markusressel/fan2go/internal/fans"
 	"github.com/markusressel/fan2go/internal/hwmon"
 	"github.com/markusressel/fan2go/internal/nvidia"
+	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/mgutz/ansi"
 	"github.com/spf13/cobra"
@@ -74,11 +75,17 @@ var detectCmd = &cobra.Command{
 			fanSlice := controller.Fans
 			sensorMap := controller.Sensors
 
-			if len(fanSlice) <= 0 && len(sensorMap) <= 0 {
+			if len(fanSlice) <= 0 && len(sensorMap) <= 0 && len(controller.Inputs) <= 0 {
 				continue
 			}
 
 			ui.Printfln("> Platform: %s", controller.Platform)
+			if len(controller.Modalias) > 0 {
+				ui.Printfln("  Modalias: %s", controller.Modalias)
+			}
+			if len(controller.PciPath) > 0 {
+				ui.Printfln("  PCI Path: %s", controller.PciPath)
+			}
 
 			var fanRows [][]string
 			for _, fan := range fanSlice {
@@ -154,7 +161,46 @@ var detectCmd = &cobra.Command{
 				Rows:    sensorRows,
 			}
 
-			printTables([]table.Table{fanTable, sensorTable})
+			inputTypes := make([]string, 0, len(controller.Inputs))
+			for inputType := range controller.Inputs {
+				inputTypes = append(inputTypes, inputType)
+			}
+			sort.Strings(inputTypes)
+
+			var inputRows [][]string
+			for _, inputType := range inputTypes {
+				inputMap := controller.Inputs[inputType]
+				inputMapKeys := make([]int, 0, len(inputMap))
+				for k := range inputMap {
+					inputMapKeys = append(inputMapKeys, k)
+				}
+				sort.Ints(inputMapKeys)
+
+				unit := configuration.SensorConfig{HwMon: &configuration.HwMonSensorConfig{Type: inputType}}.GetUnit()
+				for _, channel := range inputMapKeys {
+					sensor := inputMap[channel]
+					value, err := sensor.GetValue()
+					valueText := "N/A"
+					if err == nil {
+						valueText = fmt.Sprintf("%.2f%s", value/configuration.UnitScale(unit), configuration.UnitSymbol(unit))
+					}
+
+					_, file := filepath.Split(sensor.Input)
+					labelAndFile := fmt.Sprintf("%s (%s)", sensor.Label, file)
+
+					inputRows = append(inputRows, []string{
+						"", inputType, strconv.Itoa(sensor.Channel), labelAndFile, valueText,
+					})
+				}
+			}
+			var inputHeaders = []string{"Inputs ", "Type", "Channel", "Label", "Value"}
+
+			inputTable := table.Table{
+				Headers: inputHeaders,
+				Rows:    inputRows,
+			}
+
+			printTables([]table.Table{fanTable, sensorTable, inputTable})
 		}
 
 		nvControllers := nvidia.GetDevices()
@@ -223,6 +269,32 @@ var detectCmd = &cobra.Command{
 
 			printTables([]table.Table{fanTable, sensorTable})
 		}
+
+		thermalZones := sensors.GetThermalZones()
+
+		if len(thermalZones) > 0 {
+			ui.Println("======== thermal zones: ========\n")
+
+			var zoneRows [][]string
+			for _, zone := range thermalZones {
+				value, err := zone.GetValue()
+				valueText := "N/A"
+				if err == nil {
+					valueText = strconv.Itoa(int(value))
+				}
+
+				zoneRows = append(zoneRows, []string{
+					"", zone.Type, filepath.Base(zone.Path), valueText,
+				})
+			}
+			var zoneHeaders = []string{"Sensors", "Type", "Zone", "Value"}
+			zoneTable := table.Table{
+				Headers: zoneHeaders,
+				Rows:    zoneRows,
+			}
+
+			printTables([]table.Table{zoneTable})
+		}
 	},
 }
 
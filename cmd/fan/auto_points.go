@@ -0,0 +1,135 @@
+package fan
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/markusressel/fan2go/cmd/global"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/registry"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+	"github.com/tomlazar/table"
+)
+
+var autoPointsEnable bool
+
+var autoPointsCmd = &cobra.Command{
+	Use:     "autopoints",
+	Aliases: []string{"auto-points"},
+	Short:   "Program the curve of a fan into the trip points of its hwmon chip and verify them",
+	Long: `Translates the linear curve of a hwmon fan into the pwmN_auto_pointM_temp/pwm trip points of its chip.
+With --enable, the chip is switched to its automatic mode afterwards, which can be used f.ex. in the
+ExecStopPost of a systemd unit to hand control over to the chip if fan2go was killed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fan, err := getFan(fanId)
+		if err != nil {
+			return err
+		}
+
+		var curveConfig *configuration.CurveConfig
+		for _, config := range configuration.CurrentConfig.Curves {
+			if config.ID == fan.GetCurveId() {
+				curveConfig = &config
+				break
+			}
+		}
+		if curveConfig == nil {
+			return fmt.Errorf("no curve with id found: %s", fan.GetCurveId())
+		}
+		curve, err := curves.NewSpeedCurve(*curveConfig)
+		if err != nil {
+			return err
+		}
+		if curveConfig.Linear != nil {
+			bindCurveSensor(curve, curveConfig.Linear.Sensor)
+		}
+
+		p := persistence.NewPersistence(configuration.CurrentConfig.DbPath)
+		fanController := controller.NewFanController(p, fan, curve, nil, configuration.CurrentConfig.FanController.AdjustmentTickRate, false)
+		points, err := fanController.ProgramAutoPoints()
+		if len(points) > 0 {
+			printAutoPoints(points)
+		}
+		if err != nil {
+			return err
+		}
+
+		if autoPointsEnable {
+			if cfg := fan.GetConfig().ControlMode; cfg != nil && cfg.OnExit != nil && cfg.OnExit.AutoPoints != nil && cfg.OnExit.AutoPoints.Mode > 0 {
+				fan.(*fans.HwMonFan).SetAutomaticControlMode(cfg.OnExit.AutoPoints.Mode)
+			}
+			err = fan.SetControlMode(fans.ControlModeAutomatic)
+			if err != nil {
+				return err
+			}
+		}
+
+		ui.Success("Done!")
+		return nil
+	},
+}
+
+// bindCurveSensor makes the hwmon sensor with the given id available to the given curve,
+// which is used to verify the temperature source of the trip points
+func bindCurveSensor(curve curves.SpeedCurve, sensorId string) {
+	for _, config := range configuration.CurrentConfig.Sensors {
+		if config.ID != sensorId || config.HwMon == nil {
+			continue
+		}
+		err := hwmon.UpdateSensorConfigFromHwMonControllers(hwmon.GetChips(), &config)
+		if err != nil {
+			ui.Warning("Unable to find sensor %s: %v", sensorId, err)
+			return
+		}
+		sensor, err := sensors.NewSensor(config)
+		if err != nil {
+			ui.Warning("Unable to create sensor %s: %v", sensorId, err)
+			return
+		}
+		reg := registry.NewRegistry()
+		reg.RegisterSensor(sensor)
+		reg.RegisterCurve(curve)
+		return
+	}
+}
+
+func printAutoPoints(points []fans.AutoPoint) {
+	tab := table.Table{
+		Headers: []string{"Point", "Temp", "PWM"},
+	}
+	for i, point := range points {
+		tab.Rows = append(tab.Rows, []string{
+			strconv.Itoa(i + 1), fmt.Sprintf("%d°C", point.Temp/1000), strconv.Itoa(point.Pwm),
+		})
+	}
+	var buf bytes.Buffer
+	tableErr := tab.WriteTable(&buf, &table.Config{
+		ShowIndex:       false,
+		Color:           !global.NoColor,
+		AlternateColors: true,
+		TitleColorCode:  ansi.ColorCode("white+buf"),
+		AltColorCodes: []string{
+			ansi.ColorCode("white"),
+			ansi.ColorCode("white:236"),
+		},
+	})
+	if tableErr != nil {
+		panic(tableErr)
+	}
+	ui.Println(buf.String())
+}
+
+func init() {
+	autoPointsCmd.Flags().BoolVar(&autoPointsEnable, "enable", false, "Switch the chip to its automatic mode after programming the trip points")
+	Command.AddCommand(autoPointsCmd)
+}
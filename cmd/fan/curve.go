@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/guptarohit/asciigraph"
 	"github.com/markusressel/fan2go/cmd/global"
@@ -38,6 +39,10 @@ var curveCmd = &cobra.Command{
 
 		var fanList []fans.Fan
 		for _, config := range configuration.CurrentConfig.Fans {
+			if config.Group != nil {
+				// fan groups don't measure curve data of their own
+				continue
+			}
 			fan, err := fans.NewFan(config)
 			if err != nil {
 				ui.Fatal("Unable to process fan configuration: %s", config.ID)
@@ -63,6 +68,12 @@ var curveCmd = &cobra.Command{
 				measuredMaxPwm = strconv.Itoa(maxPwm)
 			}
 
+			minPwmOffset := "-"
+			minPwmOffsetState, minPwmOffsetErr := persistence.LoadFanMinPwmOffset(fan.GetId())
+			if minPwmOffsetErr == nil {
+				minPwmOffset = strconv.Itoa(minPwmOffsetState.Offset)
+			}
+
 			if idx > 0 {
 				ui.Printfln("")
 				ui.Printfln("")
@@ -78,6 +89,7 @@ var curveCmd = &cobra.Command{
 					{"Max PWM", strconv.Itoa(fan.GetMaxPwm())},
 					{"Measured Start PWM", measuredStartPwm},
 					{"Measured Max PWM", measuredMaxPwm},
+					{"Min PWM Offset", minPwmOffset},
 				},
 			}
 			var buf bytes.Buffer
@@ -97,6 +109,14 @@ var curveCmd = &cobra.Command{
 			tableString := buf.String()
 			ui.Println(tableString)
 
+			if minPwmOffsetErr == nil && len(minPwmOffsetState.History) > 0 {
+				ui.Println("Min PWM Offset history:")
+				for _, change := range minPwmOffsetState.History {
+					ui.Printfln("  %s: %d (%s)", change.Time.Format(time.DateTime), change.Offset, change.Reason)
+				}
+				ui.Println("")
+			}
+
 			// print graph
 			if fanCurveErr != nil {
 				ui.Println("No fan curve data yet...")
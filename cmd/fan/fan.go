@@ -3,9 +3,11 @@ package fan
 import (
 	"fmt"
 
+	"github.com/markusressel/fan2go/internal"
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/fans"
 	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/persistence"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -48,6 +50,9 @@ func getFan(id string) (fans.Fan, error) {
 	for _, config := range configuration.CurrentConfig.Fans {
 		availableFanIds = append(availableFanIds, config.ID)
 		if config.ID == id {
+			if config.Group != nil {
+				return getGroupFan(config, controllers)
+			}
 			if config.HwMon != nil {
 				_ = hwmon.UpdateFanConfigFromHwMonControllers(controllers, &config)
 			}
@@ -63,3 +68,26 @@ func getFan(id string) (fans.Fan, error) {
 
 	return nil, fmt.Errorf("no fan with id found: %s, options: %s", id, availableFanIds)
 }
+
+func getGroupFan(config configuration.FanConfig, controllers []*hwmon.HwMonController) (fans.Fan, error) {
+	pers := persistence.NewPersistence(configuration.CurrentConfig.DbPath)
+	members := map[string]fans.Fan{}
+	pwmMaps := map[string][]int{}
+	for _, member := range config.Group.Members {
+		for _, memberConfig := range configuration.CurrentConfig.Fans {
+			if memberConfig.ID != member.Fan {
+				continue
+			}
+			if memberConfig.HwMon != nil {
+				_ = hwmon.UpdateFanConfigFromHwMonControllers(controllers, &memberConfig)
+			}
+			fan, err := fans.NewFan(memberConfig)
+			if err != nil {
+				return nil, err
+			}
+			members[member.Fan] = fan
+			pwmMaps[member.Fan] = internal.LoadGroupMemberData(pers, fan)
+		}
+	}
+	return fans.NewGroupFan(config, members, pwmMaps)
+}
@@ -35,7 +35,7 @@ var initCmd = &cobra.Command{
 		ui.Info("Using persistence at: %s", dbPath)
 
 		p := persistence.NewPersistence(dbPath)
-		_, reg, err := internal.InitializeObjects()
+		_, reg, err := internal.InitializeObjects(p)
 		if err != nil {
 			return err
 		}
@@ -60,6 +60,12 @@ var initCmd = &cobra.Command{
 		if err = p.DeleteFanPwmMap(fan.GetId()); err != nil {
 			return err
 		}
+		if err = p.DeleteFanMinPwmOffset(fan.GetId()); err != nil {
+			return err
+		}
+		if err = p.DeleteFanRpmDataReference(fan.GetId()); err != nil {
+			return err
+		}
 
 		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 		defer stop()
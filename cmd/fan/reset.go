@@ -2,16 +2,21 @@ package fan
 
 import (
 	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
 	"github.com/markusressel/fan2go/internal/persistence"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+var resetRestoreOriginal bool
+
 var resetCmd = &cobra.Command{
 	Use:   "reset",
 	Short: "Reset all data associated with a given fan",
-	Long:  ``,
-	Args:  cobra.NoArgs,
+	Long: `Reset all data associated with a given fan.
+With --restore-original, only the state the fan was in before fan2go took control of it is restored instead,
+which is necessary if fan2go was killed or crashed and isn't started again.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fan, err := getFan(fanId)
 		if err != nil {
@@ -22,6 +27,19 @@ var resetCmd = &cobra.Command{
 		ui.Info("Using persistence at: %s", dbPath)
 
 		p := persistence.NewPersistence(dbPath)
+		if resetRestoreOriginal {
+			restored, err := controller.RestoreOriginalFanState(p, fan)
+			if err != nil {
+				return err
+			}
+			if restored {
+				ui.Success("Restored original state of fan %s", fan.GetId())
+			} else {
+				ui.Info("No original state of fan %s persisted, fan2go was shut down cleanly", fan.GetId())
+			}
+			return nil
+		}
+
 		err = p.DeleteFanRpmData(fan)
 		if err != nil {
 			return err
@@ -41,5 +59,6 @@ var resetCmd = &cobra.Command{
 }
 
 func init() {
+	resetCmd.Flags().BoolVar(&resetRestoreOriginal, "restore-original", false, "Restore the state the fan was in before fan2go took control of it, after an unclean shutdown")
 	Command.AddCommand(resetCmd)
 }
@@ -2,12 +2,14 @@ package sensor
 
 import (
 	"fmt"
-	"regexp"
+	"io"
 
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/registry"
 	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/markusressel/fan2go/internal/util"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
@@ -23,12 +25,16 @@ var Command = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		pterm.DisableOutput()
 
-		sensor, err := getSensor(sensorId)
+		reg := registry.NewRegistry()
+		// stop background processes, like the command of a streaming cmd sensor, before exiting
+		defer closeSensors(reg)
+
+		sensor, err := getSensor(reg, sensorId)
 		if err != nil {
 			return err
 		}
 
-		value, err := sensor.GetValue()
+		value, err := sensors.SampleAndReadValue(sensor)
 		if err != nil {
 			return err
 		}
@@ -47,7 +53,8 @@ func init() {
 	_ = Command.MarkPersistentFlagRequired("id")
 }
 
-func getSensor(id string) (sensors.Sensor, error) {
+// getSensor creates the sensor with the given id, all created sensors are registered in the given registry
+func getSensor(reg *registry.Registry, id string) (sensors.Sensor, error) {
 	configPath := configuration.DetectAndReadConfigFile()
 	ui.Info("Using configuration file at: %s", configPath)
 	var err error
@@ -66,33 +73,101 @@ func getSensor(id string) (sensors.Sensor, error) {
 	for _, config := range configuration.CurrentConfig.Sensors {
 		availableSensorIds = append(availableSensorIds, config.ID)
 		if config.ID == id {
-			if config.HwMon != nil {
-				for _, controller := range controllers {
-					matched, err := regexp.MatchString("(?i)"+config.HwMon.Platform, controller.Platform)
-					if err != nil {
-						return nil, fmt.Errorf("failed to match platform regex of %s (%s) against controller platform %s", config.ID, config.HwMon.Platform, controller.Platform)
+			if config.Expression != nil || config.Derivative != nil {
+				return createDerivedSensor(controllers, reg, config)
+			}
+			sensor, err := createSensor(controllers, config)
+			if err != nil {
+				return nil, err
+			}
+			reg.RegisterSensor(sensor)
+			return sensor, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no sensor with id found: %s, options: %s", id, availableSensorIds)
+}
+
+func createSensor(controllers []*hwmon.HwMonController, config configuration.SensorConfig) (sensors.Sensor, error) {
+	if config.HwMon != nil {
+		err := hwmon.UpdateSensorConfigFromHwMonControllers(controllers, &config)
+		if err != nil {
+			return nil, err
+		}
+		if len(config.HwMon.TempInput) <= 0 {
+			return nil, fmt.Errorf("unable to find temp input for sensor %s", config.ID)
+		}
+	}
+	if config.Aggregate != nil && config.Aggregate.Source == configuration.AggregateSourceHwMon {
+		err := hwmon.UpdateAggregateSensorConfigFromHwMonControllers(controllers, &config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sensors.NewSensor(config)
+}
+
+// createDerivedSensor creates the given expression or derivative sensor, as well as all sensors
+// it (transitively) depends on, initialized with their current value.
+func createDerivedSensor(controllers []*hwmon.HwMonController, reg *registry.Registry, config configuration.SensorConfig) (sensors.Sensor, error) {
+	var register func(config configuration.SensorConfig) (sensors.Sensor, error)
+	register = func(config configuration.SensorConfig) (sensors.Sensor, error) {
+		if sensor, exists := reg.GetSensor(config.ID); exists {
+			return sensor, nil
+		}
+
+		var sensor sensors.Sensor
+		var err error
+		if config.Expression != nil || config.Derivative != nil {
+			sensor, err = sensors.NewSensor(config)
+			if err != nil {
+				return nil, err
+			}
+			var dependencyIds []string
+			if config.Expression != nil {
+				expression, err := util.ParseExpression(config.Expression.Formula)
+				if err != nil {
+					return nil, err
+				}
+				dependencyIds = expression.Variables()
+			} else {
+				dependencyIds = []string{config.Derivative.Sensor}
+			}
+			for _, dependencyId := range dependencyIds {
+				for _, dependencyConfig := range configuration.CurrentConfig.Sensors {
+					if dependencyConfig.ID != dependencyId {
+						continue
 					}
-					if matched {
-						sensor, exists := controller.Sensors[config.HwMon.Index]
-						if exists {
-							if len(sensor.Input) <= 0 {
-								return nil, fmt.Errorf("unable to find temp input for sensor %s", id)
-							}
-							config.HwMon.TempInput = sensor.Input
-							break
-						}
+					if _, err := register(dependencyConfig); err != nil {
+						return nil, err
 					}
 				}
 			}
-
-			sensor, err := sensors.NewSensor(config)
+		} else {
+			sensor, err = createSensor(controllers, config)
 			if err != nil {
 				return nil, err
 			}
+		}
 
-			return sensor, nil
+		reg.RegisterSensor(sensor)
+		value, err := sensors.SampleAndReadValue(sensor)
+		if err != nil {
+			return nil, err
 		}
+		sensor.SetMovingAvg(value)
+		return sensor, nil
 	}
 
-	return nil, fmt.Errorf("no sensor with id found: %s, options: %s", id, availableSensorIds)
+	return register(config)
+}
+
+// closeSensors stops the background processes of all sensors in the given registry
+func closeSensors(reg *registry.Registry) {
+	for _, sensor := range reg.SnapshotSensors() {
+		if closer, ok := sensor.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
 }
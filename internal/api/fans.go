@@ -3,8 +3,11 @@ package api
 import (
 	"errors"
 	"net/http"
+	"os"
 
 	"github.com/labstack/echo/v4"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/persistence"
 	"github.com/markusressel/fan2go/internal/registry"
 	"github.com/qdm12/reprint"
 )
@@ -18,6 +21,7 @@ func registerFanEndpoints(rest *echo.Echo, reg *registry.Registry) {
 	group.GET("/:"+urlParamId+"/", func(c echo.Context) error {
 		return getFan(c, reg)
 	})
+	group.GET("/:"+urlParamId+"/minpwmoffset/", getFanMinPwmOffset)
 	group.POST("/", createFan)
 	group.DELETE("/:"+urlParamId+"/", deleteFan)
 }
@@ -38,6 +42,19 @@ func getFan(c echo.Context, reg *registry.Registry) error {
 	}
 }
 
+// returns the learned minPwm offset of a fan, including the history of its changes
+func getFanMinPwmOffset(c echo.Context) error {
+	id := c.Param(urlParamId)
+	p := persistence.NewPersistence(configuration.CurrentConfig.DbPath)
+	data, err := p.LoadFanMinPwmOffset(id)
+	if errors.Is(err, os.ErrNotExist) {
+		return returnNotFound(c, id)
+	} else if err != nil {
+		return returnError(c, err)
+	}
+	return c.JSONPretty(http.StatusOK, data, indentationChar)
+}
+
 func deleteFan(c echo.Context) error {
 	return returnError(c, errors.New("not yet supported"))
 }
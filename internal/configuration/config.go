@@ -121,6 +121,9 @@ func setDefaultValues() {
 	viper.SetDefault("ControllerAdjustmentTickRate", 0*time.Millisecond)
 	viper.SetDefault("FanController.AdjustmentTickRate", 200*time.Millisecond)
 	viper.SetDefault("FanController.PwmSetDelay", 5*time.Millisecond)
+	viper.SetDefault("FanController.MinPwmReprobeInterval", 6*time.Hour)
+	viper.SetDefault("FanController.CurveRefinementInterval", 15*time.Minute)
+	viper.SetDefault("FanController.CurveDriftThreshold", 0.2)
 
 	viper.SetDefault("sensors", []SensorConfig{})
 	viper.SetDefault("fans", []FanConfig{})
@@ -271,15 +274,17 @@ func (c *PwmMapConfig) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// UnmarshalText handles string shorthands for OnExitConfig: "restore" and "none".
+// UnmarshalText handles string shorthands for OnExitConfig: "restore", "none" and "autoPoints".
 func (c *OnExitConfig) UnmarshalText(text []byte) error {
 	switch strings.ToLower(string(text)) {
 	case "restore":
 		*c = OnExitConfig{Restore: &OnExitRestoreConfig{}}
 	case "none":
 		*c = OnExitConfig{None: &OnExitNoneConfig{}}
+	case "autopoints":
+		*c = OnExitConfig{AutoPoints: &OnExitAutoPointsConfig{}}
 	default:
-		return fmt.Errorf("unknown onExit value %q (expected: restore, none, or a map with controlMode/speed keys)", string(text))
+		return fmt.Errorf("unknown onExit value %q (expected: restore, none, autoPoints, or a map with controlMode/speed keys)", string(text))
 	}
 	return nil
 }
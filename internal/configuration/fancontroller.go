@@ -8,4 +8,13 @@ type FanControllerConfig struct {
 	PwmSetDelay time.Duration `json:"pwmSetDelay"`
 	// Time interval between each fan speed update cycle.
 	AdjustmentTickRate time.Duration `json:"adjustmentTickRate"`
+	// Time interval between attempts to lower the minPwm offset learned for fans that should never stop,
+	// after it was increased because the fan stalled. 0 disables these attempts.
+	MinPwmReprobeInterval time.Duration `json:"minPwmReprobeInterval"`
+	// Time interval between saves of the fan curve data, which is refined with the RPM observed while a fan
+	// runs at a steady PWM value. 0 disables the refinement.
+	CurveRefinementInterval time.Duration `json:"curveRefinementInterval"`
+	// Relative loss of RPM at the same PWM value, compared to the initially measured fan curve data,
+	// at which a warning about a possibly failing fan is logged. 0 disables the warning.
+	CurveDriftThreshold float64 `json:"curveDriftThreshold"`
 }
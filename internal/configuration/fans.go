@@ -68,17 +68,28 @@ type ControlModeValue string
 // Valid combinations:
 //   - restore (alone): restore original control mode — default
 //   - none (alone): do nothing, leave fan at last fan2go speed
+//   - autoPoints (alone): program the curve into the trip points of the chip and switch to its automatic mode
 //   - controlMode and/or speed: set explicit values on exit
 type OnExitConfig struct {
-	Restore     *OnExitRestoreConfig `json:"restore,omitempty"`
-	None        *OnExitNoneConfig    `json:"none,omitempty"`
-	ControlMode *ControlModeValue    `mapstructure:"mode" json:"mode,omitempty"`
-	Speed       *int                 `json:"speed,omitempty"`
+	Restore     *OnExitRestoreConfig    `json:"restore,omitempty"`
+	None        *OnExitNoneConfig       `json:"none,omitempty"`
+	AutoPoints  *OnExitAutoPointsConfig `json:"autoPoints,omitempty"`
+	ControlMode *ControlModeValue       `mapstructure:"mode" json:"mode,omitempty"`
+	Speed       *int                    `json:"speed,omitempty"`
 }
 
 // OnExitRestoreConfig restores the original control mode (default behavior).
 type OnExitRestoreConfig struct{}
 
+// OnExitAutoPointsConfig translates the linear curve of a hwmon fan into the trip points of its chip
+// (pwmN_auto_pointM_temp/pwm) and switches the chip to its automatic mode on exit,
+// so the fan keeps following the curve while fan2go is not running.
+type OnExitAutoPointsConfig struct {
+	// Mode is the pwm_enable value of the trip point mode of the chip, f.ex. 5 ("Smart Fan IV") for nct6775 chips.
+	// Defaults to the automatic mode the fan was in before fan2go took control of it (or 2).
+	Mode int `json:"mode,omitempty"`
+}
+
 // OnExitNoneConfig skips all exit actions, leaving the fan at the last speed set by fan2go.
 type OnExitNoneConfig struct{}
 
@@ -110,17 +121,30 @@ type FanConfig struct {
 	// are directly mapped with PwmMap, **without** scaling them first.
 	// Note: If NeverStop is also set to true, values smaller than MinPwm (incl. 0) are replaced with MinPwm
 	UseUnscaledCurveValues bool `json:"useUnscaledCurveValues"`
+	// ControlTarget defines how the curve value is interpreted. With ControlTargetRpm, curve values
+	// [1..255] are scaled to the measured RPM range of the fan and approached using the actual RPM as feedback.
+	// Defaults to ControlTargetPwm.
+	ControlTarget ControlTarget `json:"controlTarget,omitempty"`
+	// SpinUp configures a kick applied when the fan starts from a standstill with a target below StartPwm.
+	SpinUp *SpinUpConfig `json:"spinUp,omitempty"`
+	// ZeroRpm configures a mode where the fan is stopped below a temperature threshold, and only started
+	// again above a second, higher threshold.
+	ZeroRpm *ZeroRpmConfig `json:"zeroRpm,omitempty"`
+	// FailsafePwm is the PWM value (in [0..255], before PwmMap is applied) the fan is set to
+	// while a sensor used by its curve is faulted. Defaults to 255.
+	FailsafePwm *int `json:"failsafePwm,omitempty"`
 	// PwmSetDelay overrides the global fanController.pwmSetDelay for this fan.
 	PwmSetDelay *time.Duration `json:"pwmSetDelay,omitempty"`
 	// ControlAlgorithm defines how the curve target is applied to the fan.
 	ControlAlgorithm *ControlAlgorithmConfig `json:"controlAlgorithm,omitempty"`
 	// SanityCheck defines Configuration options for sanity checks
 	SanityCheck SanityCheckConfig `json:"sanityCheck"`
-	// HwMon, File and Cmd are the different ways to configure the respective fan types.
+	// HwMon, File, Cmd and Group are the different ways to configure the respective fan types.
 	HwMon  *HwMonFanConfig  `json:"hwMon,omitempty"`
 	Nvidia *NvidiaFanConfig `json:"nvidia,omitempty"`
 	File   *FileFanConfig   `json:"file,omitempty"`
 	Cmd    *CmdFanConfig    `json:"cmd,omitempty"`
+	Group  *GroupFanConfig  `json:"group,omitempty"`
 
 	// ControlLoop is a configuration for a PID control loop.
 	//
@@ -130,6 +154,60 @@ type FanConfig struct {
 	ControlLoop *ControlLoopConfig `json:"controlLoop,omitempty"`
 }
 
+type SpinUpPwm string
+
+const (
+	SpinUpPwmStart SpinUpPwm = "start"
+	SpinUpPwmMax   SpinUpPwm = "max"
+)
+
+const (
+	DefaultSpinUpDuration  = 2 * time.Second
+	DefaultZeroRpmMinDwell = 30 * time.Second
+)
+
+type SpinUpConfig struct {
+	// Pwm is the PWM value applied during the kick, either SpinUpPwmStart (default) or SpinUpPwmMax.
+	Pwm SpinUpPwm `json:"pwm,omitempty"`
+	// Duration of the kick, before the fan settles to its target. Defaults to DefaultSpinUpDuration.
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// GetDuration returns the duration of the kick, DefaultSpinUpDuration if none is configured
+func (c SpinUpConfig) GetDuration() time.Duration {
+	if c.Duration <= 0 {
+		return DefaultSpinUpDuration
+	}
+	return c.Duration
+}
+
+type ZeroRpmConfig struct {
+	// Sensor is the id of the sensor the thresholds are compared to.
+	Sensor string `json:"sensor"`
+	// StopTemp is the value (in the unit of the sensor, f.ex. °C) at or below which the fan is stopped.
+	StopTemp float64 `json:"stopTemp"`
+	// StartTemp is the value (in the unit of the sensor, f.ex. °C) at or above which the fan is started again.
+	StartTemp float64 `json:"startTemp"`
+	// MinDwell is the minimum time the fan stays stopped or running, before switching again.
+	// Defaults to DefaultZeroRpmMinDwell.
+	MinDwell *time.Duration `json:"minDwell,omitempty"`
+}
+
+// GetMinDwell returns the minimum time between two switches, DefaultZeroRpmMinDwell if none is configured
+func (c ZeroRpmConfig) GetMinDwell() time.Duration {
+	if c.MinDwell == nil {
+		return DefaultZeroRpmMinDwell
+	}
+	return *c.MinDwell
+}
+
+type ControlTarget string
+
+const (
+	ControlTargetPwm ControlTarget = "pwm"
+	ControlTargetRpm ControlTarget = "rpm"
+)
+
 type ControlAlgorithm string
 
 const (
@@ -179,16 +257,48 @@ type FanModeChangedByThirdPartyConfig struct {
 }
 
 type HwMonFanConfig struct {
-	Platform      string `json:"platform"`
-	Index         int    `json:"index"`
-	RpmChannel    int    `json:"rpmChannel"`
-	PwmChannel    int    `json:"pwmChannel"`
+	Platform   string `json:"platform"`
+	Index      int    `json:"index"`
+	RpmChannel int    `json:"rpmChannel"`
+	PwmChannel int    `json:"pwmChannel"`
+	// Label is the label of the fan as printed by 'fan2go detect' (e.g. "CPU_FAN"),
+	// matched exactly or as a regular expression that has to match the whole label
+	Label string `json:"label,omitempty"`
+	// Modalias is a regex matching the modalias of the chip as printed by 'fan2go detect'
+	Modalias string `json:"modalias,omitempty"`
+	// PciPath is the PCI address (or a trailing part of the sysfs PCI path) of the chip as printed by 'fan2go detect'
+	PciPath string `json:"pciPath,omitempty"`
+
 	SysfsPath     string
 	RpmInputPath  string
 	PwmPath       string
 	PwmEnablePath string
 }
 
+// GroupFanConfig lets a single curve and control loop drive several other fans, f.ex. identical case fans
+// connected to separate headers, so they don't drift apart.
+type GroupFanConfig struct {
+	Members []GroupFanMemberConfig `json:"members"`
+}
+
+type GroupFanMemberConfig struct {
+	// Fan is the id of the member fan. Member fans are only controlled by their group and can't have a curve.
+	Fan string `json:"fan"`
+	// Multiplier is applied to the PWM value of the group. Defaults to 1.
+	Multiplier float64 `json:"multiplier,omitempty"`
+	// Offset is added to the PWM value of the group after applying the Multiplier.
+	// The pwmMap of the member fan is applied to the result.
+	Offset int `json:"offset,omitempty"`
+}
+
+// GetMultiplier returns the multiplier of this member, 1 if none is configured
+func (c GroupFanMemberConfig) GetMultiplier() float64 {
+	if c.Multiplier == 0 {
+		return 1
+	}
+	return c.Multiplier
+}
+
 type NvidiaFanConfig struct {
 	Device string `json:"device"` // e.g. "nvidia-10DE2489-0800"
 	Index  int    `json:"index"`
@@ -1,5 +1,7 @@
 package configuration
 
+import "time"
+
 type SensorConfig struct {
 	// ID is the unique identifier for this sensor
 	ID string `json:"id"`
@@ -10,16 +12,236 @@ type SensorConfig struct {
 	File   *FileSensorConfig   `json:"file,omitempty"`
 	Cmd    *CmdSensorConfig    `json:"cmd,omitempty"`
 	Disk   *DiskSensorConfig   `json:"disk,omitempty"`
+
+	ThermalZone *ThermalZoneSensorConfig `json:"thermalZone,omitempty"`
+	CpuUsage    *CpuUsageSensorConfig    `json:"cpuUsage,omitempty"`
+	Rapl        *RaplSensorConfig        `json:"rapl,omitempty"`
+	Remote      *RemoteSensorConfig      `json:"remote,omitempty"`
+	Aggregate   *AggregateSensorConfig   `json:"aggregate,omitempty"`
+
+	Expression *ExpressionSensorConfig `json:"expression,omitempty"`
+	Derivative *DerivativeSensorConfig `json:"derivative,omitempty"`
+
+	// Unit is the unit of the values of this sensor, which is also used in curve definitions, one of:
+	// celsius | celsiusPerSecond | percent | watt | volt | ampere | rpm.
+	// If omitted, the natural unit of the sensor type is used, f.ex. celsius for temperature sensors.
+	Unit string `json:"unit,omitempty"`
+	// InputUnit is the unit of the raw values reported by a temperature sensor, one of:
+	// millicelsius | celsius | fahrenheit | kelvin (default: millicelsius)
+	InputUnit string `json:"inputUnit,omitempty"`
+	// Scale is a factor the (converted) values of this sensor are multiplied with, defaults to 1
+	Scale float64 `json:"scale,omitempty"`
+	// Offset is added to the (scaled) values of this sensor, given in the unit of the sensor, f.ex. degrees
+	Offset float64 `json:"offset,omitempty"`
+
+	// PollingRate overrides the global tempSensorPollingRate for this sensor
+	PollingRate *time.Duration `json:"pollingRate,omitempty"`
+	// Filter defines how consecutive readings of this sensor are smoothed.
+	// If omitted, a simple moving average with the global tempRollingWindowSize is used.
+	Filter *SensorFilterConfig `json:"filter,omitempty"`
+
+	// FaultDetection defines when a sensor is considered faulted
+	FaultDetection SensorFaultDetectionConfig `json:"faultDetection"`
+}
+
+// SensorFilterConfig selects the filter used to smooth sensor readings, only one of them can be used.
+type SensorFilterConfig struct {
+	Sma    *SmaSensorFilterConfig    `json:"sma,omitempty"`
+	Ema    *EmaSensorFilterConfig    `json:"ema,omitempty"`
+	Median *MedianSensorFilterConfig `json:"median,omitempty"`
+	Kalman *KalmanSensorFilterConfig `json:"kalman,omitempty"`
+}
+
+type SmaSensorFilterConfig struct {
+	// WindowSize is the number of readings to average, defaults to the global tempRollingWindowSize
+	WindowSize int `json:"windowSize,omitempty"`
+}
+
+type EmaSensorFilterConfig struct {
+	// Alpha is the weight (0 < alpha <= 1) of a new reading, higher values react faster
+	Alpha float64 `json:"alpha"`
+}
+
+type MedianSensorFilterConfig struct {
+	// WindowSize is the number of readings to compute the median of, defaults to the global tempRollingWindowSize
+	WindowSize int `json:"windowSize,omitempty"`
 }
 
+type KalmanSensorFilterConfig struct {
+	// ProcessNoise is the expected variance of the actual temperature between two readings
+	ProcessNoise float64 `json:"processNoise,omitempty"`
+	// MeasurementNoise is the expected variance of the sensor readings
+	MeasurementNoise float64 `json:"measurementNoise,omitempty"`
+}
+
+// SensorFaultDetectionConfig defines the conditions under which a sensor is considered faulted.
+// Fans using a curve based on a faulted sensor are driven at their failsafe PWM until the sensor recovers.
+type SensorFaultDetectionConfig struct {
+	// Enabled defines whether fault detection is enabled for this sensor.
+	Enabled DefaultTrueBool `json:"enabled,omitempty"`
+	// MaxConsecutiveErrors is the number of consecutive failed reads after which the sensor is considered faulted.
+	MaxConsecutiveErrors int `json:"maxConsecutiveErrors,omitempty" default:"3"`
+	// MaxUnchangedDuration is the duration after which a sensor reporting the exact same value
+	// is considered stuck. A value of 0 disables this check.
+	MaxUnchangedDuration time.Duration `json:"maxUnchangedDuration,omitempty"`
+	// InvalidValues is a list of values (in degrees) that are known to be reported by broken sensors.
+	// nil uses the default values, an empty list disables this check.
+	InvalidValues *[]float64 `json:"invalidValues,omitempty"`
+}
+
+// DefaultInvalidSensorValues are the values (in degrees) typically reported by broken temperature sensors
+var DefaultInvalidSensorValues = []float64{-273, 0, 127, 255}
+
+// GetInvalidValues returns the configured invalid values, or the default ones if none are configured
+func (c *SensorFaultDetectionConfig) GetInvalidValues() []float64 {
+	if c.InvalidValues == nil {
+		return DefaultInvalidSensorValues
+	}
+	return *c.InvalidValues
+}
+
+const (
+	// SensorUnitCelsius is the unit of temperature sensors, values are reported in milli-degrees
+	SensorUnitCelsius = "celsius"
+	// SensorUnitCelsiusPerSecond is the unit of derivative sensors, values are reported in milli-degrees per second
+	SensorUnitCelsiusPerSecond = "celsiusPerSecond"
+	// SensorUnitPercent is the unit of utilization sensors, values are reported in milli-percent
+	SensorUnitPercent = "percent"
+	// SensorUnitWatt is the unit of power sensors, values are reported in milli-watts
+	SensorUnitWatt = "watt"
+	// SensorUnitVolt is the unit of voltage sensors, values are reported in milli-volts
+	SensorUnitVolt = "volt"
+	// SensorUnitAmpere is the unit of current sensors, values are reported in milli-amperes
+	SensorUnitAmpere = "ampere"
+	// SensorUnitRpm is the unit of fan speed sensors, values are reported in RPM
+	SensorUnitRpm = "rpm"
+)
+
+// SensorUnits are all supported sensor units
+var SensorUnits = []string{
+	SensorUnitCelsius, SensorUnitCelsiusPerSecond, SensorUnitPercent,
+	SensorUnitWatt, SensorUnitVolt, SensorUnitAmpere, SensorUnitRpm,
+}
+
+// GetUnit returns the unit of this sensor, which is either configured explicitly
+// or the natural unit of the sensor type
+func (c SensorConfig) GetUnit() string {
+	switch {
+	case len(c.Unit) > 0:
+		return c.Unit
+	case c.HwMon != nil:
+		switch c.HwMon.Type {
+		case HwMonSensorTypePower:
+			return SensorUnitWatt
+		case HwMonSensorTypeCurrent:
+			return SensorUnitAmpere
+		case HwMonSensorTypeVoltage:
+			return SensorUnitVolt
+		case HwMonSensorTypeFan:
+			return SensorUnitRpm
+		}
+	case c.CpuUsage != nil:
+		return SensorUnitPercent
+	case c.Rapl != nil:
+		return SensorUnitWatt
+	case c.Derivative != nil:
+		return SensorUnitCelsiusPerSecond
+	}
+	return SensorUnitCelsius
+}
+
+const (
+	// SensorInputUnitMilliCelsius is the default input unit, values are used as-is
+	SensorInputUnitMilliCelsius = "millicelsius"
+	// SensorInputUnitCelsius means values are reported in degrees Celsius, f.ex. 45.5
+	SensorInputUnitCelsius = "celsius"
+	// SensorInputUnitFahrenheit means values are reported in degrees Fahrenheit
+	SensorInputUnitFahrenheit = "fahrenheit"
+	// SensorInputUnitKelvin means values are reported in Kelvin
+	SensorInputUnitKelvin = "kelvin"
+)
+
+// SensorInputUnits are all supported input units of temperature sensors
+var SensorInputUnits = []string{
+	SensorInputUnitMilliCelsius, SensorInputUnitCelsius, SensorInputUnitFahrenheit, SensorInputUnitKelvin,
+}
+
+// CorrectValue converts a raw value read from this sensor from its InputUnit to milli-degrees,
+// and applies Scale and Offset to it
+func (c SensorConfig) CorrectValue(value float64) float64 {
+	switch c.InputUnit {
+	case SensorInputUnitCelsius:
+		value = value * 1000
+	case SensorInputUnitFahrenheit:
+		value = (value - 32) * 5 / 9 * 1000
+	case SensorInputUnitKelvin:
+		value = (value - 273.15) * 1000
+	}
+	if c.Scale != 0 {
+		value = value * c.Scale
+	}
+	return value + c.Offset*UnitScale(c.GetUnit())
+}
+
+// UnitScale returns the factor between the values reported by a sensor with the given unit
+// and the values used in curve definitions. All units are reported in milli-units, except rpm.
+func UnitScale(unit string) float64 {
+	if unit == SensorUnitRpm {
+		return 1
+	}
+	return 1000
+}
+
+// UnitSymbol returns the symbol used to print values of the given unit
+func UnitSymbol(unit string) string {
+	switch unit {
+	case SensorUnitCelsiusPerSecond:
+		return "°C/s"
+	case SensorUnitPercent:
+		return "%"
+	case SensorUnitWatt:
+		return "W"
+	case SensorUnitVolt:
+		return "V"
+	case SensorUnitAmpere:
+		return "A"
+	case SensorUnitRpm:
+		return " RPM"
+	default:
+		return "°C"
+	}
+}
+
+const (
+	// HwMonSensorTypeTemp selects a temperature input (temp*_input)
+	HwMonSensorTypeTemp = "temp"
+	// HwMonSensorTypePower selects a power input (power*_input or power*_average)
+	HwMonSensorTypePower = "power"
+	// HwMonSensorTypeCurrent selects a current input (curr*_input)
+	HwMonSensorTypeCurrent = "curr"
+	// HwMonSensorTypeVoltage selects a voltage input (in*_input)
+	HwMonSensorTypeVoltage = "in"
+	// HwMonSensorTypeFan selects a fan speed input (fan*_input)
+	HwMonSensorTypeFan = "fan"
+)
+
 type HwMonSensorConfig struct {
 	// Platform is the platform of the sensor as printed by 'fan2go detect'
 	Platform string `json:"platform"`
 	// Index is the enumeration index of the sensor as printed by 'fan2go detect' (deprecated: prefer Channel)
 	Index int `json:"index"`
+	// Type is the type of the input, one of: temp | power | curr | in | fan (default: temp)
+	Type string `json:"type,omitempty"`
 	// Channel is the hardware channel number of the sensor (e.g. temp3_input → channel 3)
 	Channel int `json:"channel"`
-	// TempInput is the sysfs path to the temperature input
+	// Label is the label of the sensor as printed by 'fan2go detect' (e.g. "Tctl"),
+	// matched exactly or as a regular expression that has to match the whole label
+	Label string `json:"label,omitempty"`
+	// Modalias is a regex matching the modalias of the chip as printed by 'fan2go detect'
+	Modalias string `json:"modalias,omitempty"`
+	// PciPath is the PCI address (or a trailing part of the sysfs PCI path) of the chip as printed by 'fan2go detect'
+	PciPath string `json:"pciPath,omitempty"`
+	// TempInput is the sysfs path to the input, which is not necessarily a temperature (see Type)
 	TempInput string
 }
 
@@ -39,10 +261,116 @@ type CmdSensorConfig struct {
 	Exec string `json:"exec"`
 	// Args is a list of arguments to pass to the command
 	Args []string `json:"args"`
+	// Streaming starts the command once and reads values from its stdout line by line,
+	// instead of executing it on every poll
+	Streaming *CmdStreamingConfig `json:"streaming,omitempty"`
+}
+
+type CmdStreamingConfig struct {
+	// JsonField, if set, parses each line as a JSON object and reads the value from the given field.
+	// Otherwise, each line is expected to contain just the value.
+	JsonField string `json:"jsonField,omitempty"`
+	// StaleTimeout is the duration after which the sensor reports an error, if no new value was received
+	StaleTimeout time.Duration `json:"staleTimeout,omitempty" default:"10s"`
+	// RestartDelay is the initial delay before restarting the command after it exited,
+	// which is doubled after every unsuccessful restart
+	RestartDelay time.Duration `json:"restartDelay,omitempty" default:"1s"`
+	// MaxRestartDelay is the upper bound of the restart delay
+	MaxRestartDelay time.Duration `json:"maxRestartDelay,omitempty" default:"1m"`
 }
 
 type DiskSensorConfig struct {
 	// Device is the path to the block device. Accepts stable paths like /dev/disk/by-id/...
 	// as well as plain paths like /dev/sda or just "sda".
 	Device string `json:"device"`
+	// StandbyValue is the value (in degrees) reported while the drive is in standby.
+	// If omitted, the last temperature read while the drive was active is reported.
+	StandbyValue *float64 `json:"standbyValue,omitempty"`
+	// NvmeSensor selects the temperature of NVMe drives: 0 (default) is the composite temperature,
+	// 1-8 select the respective temperature sensor of the SMART log
+	NvmeSensor int `json:"nvmeSensor,omitempty"`
+}
+
+type ThermalZoneSensorConfig struct {
+	// Type is the type of the thermal zone as printed by 'fan2go detect', f.ex. "x86_pkg_temp" or "acpitz"
+	Type string `json:"type"`
+	// Index selects the n-th zone (starting at 1) if multiple zones share the same type, defaults to 1
+	Index int `json:"index,omitempty"`
+}
+
+const (
+	// CpuUsageModeTotal reports the utilization of all cores combined
+	CpuUsageModeTotal = "total"
+	// CpuUsageModeMaxCore reports the utilization of the busiest core
+	CpuUsageModeMaxCore = "maxCore"
+)
+
+type CpuUsageSensorConfig struct {
+	// Mode selects how the utilization of multiple cores is combined, one of: total | maxCore
+	Mode string `json:"mode,omitempty" default:"total"`
+}
+
+type RaplSensorConfig struct {
+	// Domain is the name of the RAPL (powercap) domain to read, f.ex. "package-0", "core" or "dram"
+	Domain string `json:"domain,omitempty" default:"package-0"`
+}
+
+type RemoteSensorConfig struct {
+	// Url is the http(s) URL to poll, f.ex. the sensor endpoint of the API of another fan2go instance:
+	// "http://host:9001/sensor/cpu_package/"
+	Url string `json:"url"`
+	// JsonPath is a dot separated path to the value in the JSON response, f.ex. "data.temps.0".
+	// Defaults to the moving average of a sensor returned by the fan2go API.
+	JsonPath string `json:"jsonPath,omitempty" default:"movingAvg"`
+	// Timeout is the maximum duration of a single request
+	Timeout time.Duration `json:"timeout,omitempty" default:"2s"`
+	// MaxAge is the duration the last received value is still used while the remote can't be reached.
+	// After that, reading the sensor fails, so fans using it are driven at their failsafe PWM.
+	MaxAge time.Duration `json:"maxAge,omitempty" default:"30s"`
+}
+
+const (
+	// AggregateSourceDisk aggregates disks found in /dev/disk/by-id
+	AggregateSourceDisk = "disk"
+	// AggregateSourceHwMon aggregates temperature sensors of hwmon chips
+	AggregateSourceHwMon = "hwmon"
+
+	// AggregateFunctionMax reports the highest value of all matched sensors
+	AggregateFunctionMax = "max"
+	// AggregateFunctionAverage reports the arithmetic mean of all matched sensors
+	AggregateFunctionAverage = "average"
+	// AggregateFunctionMedian reports the median of all matched sensors
+	AggregateFunctionMedian = "median"
+)
+
+type AggregateSensorConfig struct {
+	// Source is the kind of device to aggregate, one of: disk | hwmon
+	Source string `json:"source"`
+	// Match is a glob matching device names in /dev/disk/by-id, f.ex. "ata-WDC*" (source: disk)
+	Match string `json:"match,omitempty"`
+	// Platform is a glob matching the platform of hwmon chips as printed by 'fan2go detect',
+	// f.ex. "coretemp-*" (source: hwmon)
+	Platform string `json:"platform,omitempty"`
+	// LabelMatch is a glob matching the labels of hwmon sensors, f.ex. "Core *" (source: hwmon).
+	// If omitted, all temperature sensors of the matched chips are used.
+	LabelMatch string `json:"labelMatch,omitempty"`
+	// Function combines the values of all matched sensors, one of: max | average | median
+	Function string `json:"function,omitempty" default:"max"`
+	// HwMonInputs are the sysfs paths of all matched hwmon temperature inputs (source: hwmon).
+	// They are resolved at startup and can't be configured.
+	HwMonInputs []string `json:"-" mapstructure:"-"`
+}
+
+type ExpressionSensorConfig struct {
+	// Formula computes the value of this sensor from other sensors, which are referenced by their ID,
+	// f.ex. "max(cpu, gpu - 10)". Sensor values are used in degrees (not milli-degrees).
+	Formula string `json:"formula"`
+}
+
+type DerivativeSensorConfig struct {
+	// Sensor is the id of the sensor to compute the rate of change of
+	Sensor string `json:"sensor"`
+	// Window is the time span of readings used to compute the slope.
+	// Longer windows reduce the noise caused by the limited resolution of most sensors, but react slower.
+	Window time.Duration `json:"window,omitempty" default:"10s"`
 }
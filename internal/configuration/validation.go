@@ -2,6 +2,9 @@ package configuration
 
 import (
 	"fmt"
+	"net/url"
+	"path"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -54,6 +57,7 @@ func containsCmdSensors(config *Configuration) bool {
 }
 
 func validateSensors(config *Configuration) error {
+	graph := make(map[interface{}][]interface{})
 	sensorIds := []string{}
 
 	for _, sensorConfig := range config.Sensors {
@@ -82,22 +86,91 @@ func validateSensors(config *Configuration) error {
 		if sensorConfig.Disk != nil {
 			subConfigs++
 		}
+		if sensorConfig.ThermalZone != nil {
+			subConfigs++
+		}
+		if sensorConfig.CpuUsage != nil {
+			subConfigs++
+		}
+		if sensorConfig.Rapl != nil {
+			subConfigs++
+		}
+		if sensorConfig.Remote != nil {
+			subConfigs++
+		}
+		if sensorConfig.Aggregate != nil {
+			subConfigs++
+		}
+		if sensorConfig.Expression != nil {
+			subConfigs++
+		}
+		if sensorConfig.Derivative != nil {
+			subConfigs++
+		}
 		if subConfigs > 1 {
 			return fmt.Errorf("sensor %s: only one sensor type can be used per sensor definition block", sensorConfig.ID)
 		}
 		if subConfigs <= 0 {
-			return fmt.Errorf("sensor %s: sub-configuration for sensor is missing, use one of: hwmon | nvidia | file | cmd | disk", sensorConfig.ID)
+			return fmt.Errorf("sensor %s: sub-configuration for sensor is missing, use one of: hwmon | nvidia | file | cmd | disk | thermalZone | cpuUsage | rapl | remote | aggregate | expression | derivative", sensorConfig.ID)
 		}
 
-		if !isSensorConfigInUse(sensorConfig, config.Curves) {
+		if !isSensorConfigInUse(sensorConfig, config.Curves, config.Sensors, config.Fans) {
 			ui.Warning("Unused sensor configuration: %s", sensorConfig.ID)
 		}
 
+		if len(sensorConfig.Unit) > 0 && !slices.Contains(SensorUnits, sensorConfig.Unit) {
+			return fmt.Errorf("sensor %s: invalid unit '%s', must be one of: %s", sensorConfig.ID, sensorConfig.Unit, strings.Join(SensorUnits, " | "))
+		}
+
+		if len(sensorConfig.InputUnit) > 0 {
+			if !slices.Contains(SensorInputUnits, sensorConfig.InputUnit) {
+				return fmt.Errorf("sensor %s: invalid inputUnit '%s', must be one of: %s", sensorConfig.ID, sensorConfig.InputUnit, strings.Join(SensorInputUnits, " | "))
+			}
+			if sensorConfig.GetUnit() != SensorUnitCelsius {
+				return fmt.Errorf("sensor %s: inputUnit can only be used with temperature sensors", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.HwMon != nil {
+			switch sensorConfig.HwMon.Type {
+			case "", HwMonSensorTypeTemp, HwMonSensorTypePower, HwMonSensorTypeCurrent, HwMonSensorTypeVoltage, HwMonSensorTypeFan:
+			default:
+				return fmt.Errorf("sensor %s: invalid hwmon type '%s', must be one of: temp | power | curr | in | fan", sensorConfig.ID, sensorConfig.HwMon.Type)
+			}
+		}
+
 		if sensorConfig.HwMon != nil {
 			hasIndex := sensorConfig.HwMon.Index > 0
 			hasChannel := sensorConfig.HwMon.Channel > 0
-			if (hasIndex && hasChannel) || (!hasIndex && !hasChannel) {
-				return fmt.Errorf("sensor %s: must have exactly one of index or channel, must be >= 1", sensorConfig.ID)
+			hasLabel := len(sensorConfig.HwMon.Label) > 0
+			if countTrue(hasIndex, hasChannel, hasLabel) != 1 {
+				return fmt.Errorf("sensor %s: must have exactly one of index, channel (must be >= 1) or label", sensorConfig.ID)
+			}
+			if hasLabel {
+				err := validateLabelPattern(sensorConfig.HwMon.Label)
+				if err != nil {
+					return fmt.Errorf("sensor %s: invalid label: %w", sensorConfig.ID, err)
+				}
+			}
+		}
+
+		if sensorConfig.Cmd != nil && sensorConfig.Cmd.Streaming != nil {
+			streaming := sensorConfig.Cmd.Streaming
+			if streaming.StaleTimeout <= 0 {
+				return fmt.Errorf("sensor %s: invalid streaming.staleTimeout, must be > 0", sensorConfig.ID)
+			}
+			if streaming.RestartDelay <= 0 {
+				return fmt.Errorf("sensor %s: invalid streaming.restartDelay, must be > 0", sensorConfig.ID)
+			}
+			if streaming.MaxRestartDelay < streaming.RestartDelay {
+				return fmt.Errorf("sensor %s: invalid streaming.maxRestartDelay, must be >= restartDelay", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.Remote != nil {
+			err := validateRemoteSensor(sensorConfig.ID, sensorConfig.Remote)
+			if err != nil {
+				return err
 			}
 		}
 
@@ -105,13 +178,320 @@ func validateSensors(config *Configuration) error {
 			if len(sensorConfig.Disk.Device) == 0 {
 				return fmt.Errorf("sensor %s: disk sensor requires a device path", sensorConfig.ID)
 			}
+			if sensorConfig.Disk.NvmeSensor < 0 || sensorConfig.Disk.NvmeSensor > 8 {
+				return fmt.Errorf("sensor %s: invalid nvmeSensor, must be in range [0..8]", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.PollingRate != nil && *sensorConfig.PollingRate <= 0 {
+			return fmt.Errorf("sensor %s: invalid pollingRate, must be > 0", sensorConfig.ID)
+		}
+
+		if sensorConfig.Filter != nil {
+			err := validateSensorFilter(sensorConfig.ID, sensorConfig.Filter)
+			if err != nil {
+				return err
+			}
+		}
+
+		if sensorConfig.FaultDetection.MaxConsecutiveErrors < 0 {
+			return fmt.Errorf("sensor %s: invalid faultDetection.maxConsecutiveErrors, must be >= 0", sensorConfig.ID)
+		}
+		if sensorConfig.FaultDetection.MaxUnchangedDuration < 0 {
+			return fmt.Errorf("sensor %s: invalid faultDetection.maxUnchangedDuration, must be >= 0", sensorConfig.ID)
+		}
+
+		if sensorConfig.ThermalZone != nil {
+			if len(sensorConfig.ThermalZone.Type) == 0 {
+				return fmt.Errorf("sensor %s: thermalZone sensor requires a type", sensorConfig.ID)
+			}
+			if sensorConfig.ThermalZone.Index < 0 {
+				return fmt.Errorf("sensor %s: invalid thermalZone index, must be >= 1", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.CpuUsage != nil {
+			switch sensorConfig.CpuUsage.Mode {
+			case "", CpuUsageModeTotal, CpuUsageModeMaxCore:
+			default:
+				return fmt.Errorf("sensor %s: invalid cpuUsage mode '%s', must be one of: total | maxCore", sensorConfig.ID, sensorConfig.CpuUsage.Mode)
+			}
+		}
+
+		if sensorConfig.Aggregate != nil {
+			err := validateAggregateSensor(sensorConfig.ID, sensorConfig.Aggregate)
+			if err != nil {
+				return err
+			}
+		}
+
+		if sensorConfig.Expression != nil {
+			if len(strings.TrimSpace(sensorConfig.Expression.Formula)) == 0 {
+				return fmt.Errorf("sensor %s: expression sensor requires a formula", sensorConfig.ID)
+			}
+			expression, err := util.ParseExpression(sensorConfig.Expression.Formula)
+			if err != nil {
+				return fmt.Errorf("sensor %s: invalid formula: %v", sensorConfig.ID, err)
+			}
+
+			var connections []interface{}
+			for _, sensorId := range expression.Variables() {
+				if sensorId == sensorConfig.ID {
+					return fmt.Errorf("sensor %s: a sensor cannot reference itself", sensorConfig.ID)
+				}
+				if !sensorIdExists(sensorId, config) {
+					return fmt.Errorf("sensor %s: no sensor definition with id '%s' found", sensorConfig.ID, sensorId)
+				}
+				connections = append(connections, sensorId)
+			}
+			graph[sensorConfig.ID] = connections
+		}
+
+		if sensorConfig.Derivative != nil {
+			if len(sensorConfig.Derivative.Sensor) == 0 {
+				return fmt.Errorf("sensor %s: derivative sensor requires a sensor", sensorConfig.ID)
+			}
+			if sensorConfig.Derivative.Sensor == sensorConfig.ID {
+				return fmt.Errorf("sensor %s: a sensor cannot reference itself", sensorConfig.ID)
+			}
+			if !sensorIdExists(sensorConfig.Derivative.Sensor, config) {
+				return fmt.Errorf("sensor %s: no sensor definition with id '%s' found", sensorConfig.ID, sensorConfig.Derivative.Sensor)
+			}
+			if sensorConfig.Derivative.Window <= 0 {
+				return fmt.Errorf("sensor %s: invalid derivative window, must be > 0", sensorConfig.ID)
+			}
+			graph[sensorConfig.ID] = []interface{}{sensorConfig.Derivative.Sensor}
+		}
+	}
+
+	return validateNoLoops(graph, "sensor")
+}
+
+func validateAggregateSensor(sensorId string, config *AggregateSensorConfig) error {
+	switch config.Source {
+	case AggregateSourceDisk:
+		if len(config.Match) == 0 {
+			return fmt.Errorf("sensor %s: aggregate sensor with source disk requires a match pattern", sensorId)
+		}
+	case AggregateSourceHwMon:
+		if len(config.Platform) == 0 {
+			return fmt.Errorf("sensor %s: aggregate sensor with source hwmon requires a platform pattern", sensorId)
+		}
+	default:
+		return fmt.Errorf("sensor %s: invalid aggregate source '%s', must be one of: disk | hwmon", sensorId, config.Source)
+	}
+
+	for _, pattern := range []string{config.Match, config.Platform, config.LabelMatch} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("sensor %s: invalid aggregate pattern '%s': %v", sensorId, pattern, err)
+		}
+	}
+
+	switch config.Function {
+	case "", AggregateFunctionMax, AggregateFunctionAverage, AggregateFunctionMedian:
+	default:
+		return fmt.Errorf("sensor %s: invalid aggregate function '%s', must be one of: max | average | median", sensorId, config.Function)
+	}
+	return nil
+}
+
+func validateAutoPoints(fanConfig FanConfig, autoPoints *OnExitAutoPointsConfig, config *Configuration) error {
+	if fanConfig.HwMon == nil {
+		return fmt.Errorf("fan '%s': controlMode.onExit autoPoints is only supported by hwmon fans", fanConfig.ID)
+	}
+	if autoPoints.Mode == 1 || autoPoints.Mode < 0 {
+		return fmt.Errorf("fan '%s': controlMode.onExit.autoPoints.mode must be an automatic pwm_enable value >= 2, got %d", fanConfig.ID, autoPoints.Mode)
+	}
+	if fanConfig.ControlTarget == ControlTargetRpm {
+		return fmt.Errorf("fan '%s': controlMode.onExit autoPoints is not supported with controlTarget rpm", fanConfig.ID)
+	}
+	curveConfig := getCurveConfig(fanConfig.Curve, config.Curves)
+	if curveConfig == nil || curveConfig.Linear == nil {
+		return fmt.Errorf("fan '%s': controlMode.onExit autoPoints requires a linear curve", fanConfig.ID)
+	}
+	for _, sensorConfig := range config.Sensors {
+		if sensorConfig.ID == curveConfig.Linear.Sensor && sensorConfig.GetUnit() != SensorUnitCelsius {
+			return fmt.Errorf("fan '%s': controlMode.onExit autoPoints requires a curve based on a temperature sensor", fanConfig.ID)
+		}
+	}
+	return nil
+}
+
+// validateLabelPattern checks that the given hwmon label compiles as a regular expression,
+// since labels are matched either exactly or as a regular expression
+func validateLabelPattern(label string) error {
+	_, err := regexp.Compile("^(?:" + label + ")$")
+	return err
+}
+
+func validateControlTarget(fanConfig FanConfig) error {
+	switch fanConfig.ControlTarget {
+	case "", ControlTargetPwm:
+		return nil
+	case ControlTargetRpm:
+		if fanConfig.UseUnscaledCurveValues {
+			return fmt.Errorf("fan %s: controlTarget rpm can't be combined with useUnscaledCurveValues", fanConfig.ID)
+		}
+		if fanConfig.ControlAlgorithm != nil && fanConfig.ControlAlgorithm.Direct != nil {
+			return fmt.Errorf("fan %s: controlTarget rpm requires the pid control algorithm", fanConfig.ID)
+		}
+		if fanConfig.HwMon == nil && fanConfig.Nvidia == nil {
+			// other fans don't measure a PWM -> RPM curve to compute target RPMs from
+			return fmt.Errorf("fan %s: controlTarget rpm is only supported by hwmon and nvidia fans", fanConfig.ID)
+		}
+		return nil
+	default:
+		return fmt.Errorf("fan %s: invalid controlTarget '%s', use one of: pwm | rpm", fanConfig.ID, fanConfig.ControlTarget)
+	}
+}
+
+func validateSpinUp(fanConfig FanConfig) error {
+	if fanConfig.SpinUp == nil {
+		return nil
+	}
+	switch fanConfig.SpinUp.Pwm {
+	case "", SpinUpPwmStart, SpinUpPwmMax:
+	default:
+		return fmt.Errorf("fan %s: invalid spinUp pwm '%s', use one of: start | max", fanConfig.ID, fanConfig.SpinUp.Pwm)
+	}
+	if fanConfig.SpinUp.Duration < 0 {
+		return fmt.Errorf("fan %s: invalid spinUp duration, must be >= 0", fanConfig.ID)
+	}
+	return nil
+}
+
+func validateZeroRpm(fanConfig FanConfig, config *Configuration) error {
+	zeroRpm := fanConfig.ZeroRpm
+	if zeroRpm == nil {
+		return nil
+	}
+	if fanConfig.NeverStop {
+		return fmt.Errorf("fan %s: zeroRpm can't be combined with neverStop", fanConfig.ID)
+	}
+	if !sensorIdExists(zeroRpm.Sensor, config) {
+		return fmt.Errorf("fan %s: no sensor definition with id '%s' found for zeroRpm", fanConfig.ID, zeroRpm.Sensor)
+	}
+	if zeroRpm.StopTemp >= zeroRpm.StartTemp {
+		return fmt.Errorf("fan %s: zeroRpm stopTemp must be lower than startTemp", fanConfig.ID)
+	}
+	if zeroRpm.MinDwell != nil && *zeroRpm.MinDwell < 0 {
+		return fmt.Errorf("fan %s: invalid zeroRpm minDwell, must be >= 0", fanConfig.ID)
+	}
+	return nil
+}
+
+// getFanGroupMembers returns the id of the group of each fan that is a member of a fan group
+func getFanGroupMembers(fans []FanConfig) (map[string]string, error) {
+	groupOfMember := map[string]string{}
+	for _, fanConfig := range fans {
+		if fanConfig.Group == nil {
+			continue
+		}
+		for _, member := range fanConfig.Group.Members {
+			if groupId, ok := groupOfMember[member.Fan]; ok {
+				return nil, fmt.Errorf("fan %s: member %s is already a member of group %s", fanConfig.ID, member.Fan, groupId)
+			}
+			groupOfMember[member.Fan] = fanConfig.ID
+		}
+	}
+	return groupOfMember, nil
+}
+
+func validateGroupFan(fanConfig FanConfig, fans []FanConfig) error {
+	if len(fanConfig.Group.Members) <= 0 {
+		return fmt.Errorf("fan %s: group has no members", fanConfig.ID)
+	}
+	for _, member := range fanConfig.Group.Members {
+		memberIndex := slices.IndexFunc(fans, func(f FanConfig) bool {
+			return f.ID == member.Fan
+		})
+		if memberIndex < 0 {
+			return fmt.Errorf("fan %s: no fan definition with id '%s' found for group member", fanConfig.ID, member.Fan)
+		}
+		if fans[memberIndex].Group != nil {
+			return fmt.Errorf("fan %s: group member %s can't be a group itself", fanConfig.ID, member.Fan)
+		}
+		if member.Multiplier < 0 {
+			return fmt.Errorf("fan %s: invalid multiplier of group member %s, must be >= 0", fanConfig.ID, member.Fan)
+		}
+		if member.Offset < -255 || member.Offset > 255 {
+			return fmt.Errorf("fan %s: invalid offset of group member %s, must be in range [-255..255]", fanConfig.ID, member.Fan)
 		}
 	}
+	return nil
+}
+
+func validateRemoteSensor(sensorId string, config *RemoteSensorConfig) error {
+	remoteUrl, err := url.Parse(config.Url)
+	if err != nil || (remoteUrl.Scheme != "http" && remoteUrl.Scheme != "https") || len(remoteUrl.Host) == 0 {
+		return fmt.Errorf("sensor %s: invalid remote url '%s', must be an absolute http(s) URL", sensorId, config.Url)
+	}
+	if config.Timeout <= 0 {
+		return fmt.Errorf("sensor %s: invalid remote timeout, must be > 0", sensorId)
+	}
+	if config.MaxAge < 0 {
+		return fmt.Errorf("sensor %s: invalid remote maxAge, must be >= 0", sensorId)
+	}
+	return nil
+}
+
+// countTrue returns the number of given values that are true
+func countTrue(values ...bool) int {
+	count := 0
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+	return count
+}
 
+func validateSensorFilter(sensorId string, filter *SensorFilterConfig) error {
+	subConfigs := 0
+	if filter.Sma != nil {
+		subConfigs++
+		if filter.Sma.WindowSize < 0 {
+			return fmt.Errorf("sensor %s: invalid sma windowSize, must be >= 0 (0 = tempRollingWindowSize)", sensorId)
+		}
+	}
+	if filter.Ema != nil {
+		subConfigs++
+		if filter.Ema.Alpha <= 0 || filter.Ema.Alpha > 1 {
+			return fmt.Errorf("sensor %s: invalid ema alpha, must be in range (0..1]", sensorId)
+		}
+	}
+	if filter.Median != nil {
+		subConfigs++
+		if filter.Median.WindowSize < 0 {
+			return fmt.Errorf("sensor %s: invalid median windowSize, must be >= 0 (0 = tempRollingWindowSize)", sensorId)
+		}
+	}
+	if filter.Kalman != nil {
+		subConfigs++
+		if filter.Kalman.ProcessNoise < 0 || filter.Kalman.MeasurementNoise < 0 {
+			return fmt.Errorf("sensor %s: invalid kalman noise values, must be >= 0", sensorId)
+		}
+	}
+	if subConfigs != 1 {
+		return fmt.Errorf("sensor %s: filter must use exactly one of: sma | ema | median | kalman", sensorId)
+	}
 	return nil
 }
 
-func isSensorConfigInUse(config SensorConfig, curves []CurveConfig) bool {
+func isSensorConfigInUse(config SensorConfig, curves []CurveConfig, sensors []SensorConfig, fans []FanConfig) bool {
+	for _, sensorConfig := range sensors {
+		if sensorConfig.Derivative != nil && sensorConfig.Derivative.Sensor == config.ID {
+			return true
+		}
+		if sensorConfig.Expression == nil {
+			continue
+		}
+		expression, err := util.ParseExpression(sensorConfig.Expression.Formula)
+		if err == nil && slices.Contains(expression.Variables(), config.ID) {
+			return true
+		}
+	}
+
 	for _, curveConfig := range curves {
 		if curveConfig.Function != nil {
 			// function curves cannot reference sensors
@@ -128,6 +508,12 @@ func isSensorConfigInUse(config SensorConfig, curves []CurveConfig) bool {
 		}
 	}
 
+	for _, fanConfig := range fans {
+		if fanConfig.ZeroRpm != nil && fanConfig.ZeroRpm.Sensor == config.ID {
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -229,7 +615,7 @@ func validateCurves(config *Configuration) error {
 
 	}
 
-	err := validateNoLoops(graph)
+	err := validateNoLoops(graph, "curve")
 	return err
 }
 
@@ -243,11 +629,11 @@ func sensorIdExists(sensorId string, config *Configuration) bool {
 	return false
 }
 
-func validateNoLoops(graph map[interface{}][]interface{}) error {
+func validateNoLoops(graph map[interface{}][]interface{}, kind string) error {
 	output := tarjan.Connections(graph)
 	for _, items := range output {
 		if len(items) > 1 {
-			return fmt.Errorf("you have created a curve dependency cycle: %v", items)
+			return fmt.Errorf("you have created a %s dependency cycle: %v", kind, items)
 		}
 	}
 	return nil
@@ -274,6 +660,11 @@ func isCurveConfigInUse(config CurveConfig, curves []CurveConfig, fans []FanConf
 func validateFans(config *Configuration) error {
 	fanIds := []string{}
 
+	groupOfMember, err := getFanGroupMembers(config.Fans)
+	if err != nil {
+		return err
+	}
+
 	for _, fanConfig := range config.Fans {
 		if slices.Contains(fanIds, fanConfig.ID) {
 			return fmt.Errorf("duplicate fan id detected: %s", fanConfig.ID)
@@ -290,6 +681,9 @@ func validateFans(config *Configuration) error {
 		if fanConfig.Cmd != nil {
 			subConfigs++
 		}
+		if fanConfig.Group != nil {
+			subConfigs++
+		}
 		if fanConfig.Nvidia != nil {
 			if nvidia_base.IsNvmlSupported {
 				subConfigs++
@@ -302,17 +696,32 @@ func validateFans(config *Configuration) error {
 			return fmt.Errorf("fan %s: only one fan type can be used per fan definition block", fanConfig.ID)
 		}
 		if subConfigs <= 0 {
-			return fmt.Errorf("fan %s: sub-configuration for fan is missing, use one of: hwmon | nvidia | file | cmd", fanConfig.ID)
+			return fmt.Errorf("fan %s: sub-configuration for fan is missing, use one of: hwmon | nvidia | file | cmd | group", fanConfig.ID)
 		}
 
-		if len(fanConfig.Curve) <= 0 {
+		if fanConfig.Group != nil {
+			err := validateGroupFan(fanConfig, config.Fans)
+			if err != nil {
+				return err
+			}
+		}
+
+		if groupId, ok := groupOfMember[fanConfig.ID]; ok {
+			if len(fanConfig.Curve) > 0 {
+				return fmt.Errorf("fan %s: members of a fan group can't have a curve, the fan is controlled by group %s", fanConfig.ID, groupId)
+			}
+		} else if len(fanConfig.Curve) <= 0 {
 			return fmt.Errorf("fan %s: missing curve definition in configuration entry", fanConfig.ID)
 		}
 
-		if !curveIdExists(fanConfig.Curve, config) {
+		if len(fanConfig.Curve) > 0 && !curveIdExists(fanConfig.Curve, config) {
 			return fmt.Errorf("fan %s: no curve definition with id '%s' found", fanConfig.ID, fanConfig.Curve)
 		}
 
+		if fanConfig.FailsafePwm != nil && (*fanConfig.FailsafePwm < 0 || *fanConfig.FailsafePwm > 255) {
+			return fmt.Errorf("fan %s: invalid failsafePwm, must be in range [0..255]", fanConfig.ID)
+		}
+
 		if fanConfig.ControlAlgorithm != nil {
 			if fanConfig.ControlAlgorithm.Direct != nil {
 				maxPwmChangePerCycle := fanConfig.ControlAlgorithm.Direct.MaxPwmChangePerCycle
@@ -329,9 +738,31 @@ func validateFans(config *Configuration) error {
 			}
 		}
 
+		err := validateControlTarget(fanConfig)
+		if err != nil {
+			return err
+		}
+
+		err = validateSpinUp(fanConfig)
+		if err != nil {
+			return err
+		}
+
+		err = validateZeroRpm(fanConfig, config)
+		if err != nil {
+			return err
+		}
+
 		if fanConfig.HwMon != nil {
-			if (fanConfig.HwMon.Index != 0 && fanConfig.HwMon.RpmChannel != 0) || (fanConfig.HwMon.Index == 0 && fanConfig.HwMon.RpmChannel == 0) {
-				return fmt.Errorf("fan %s: must have one of index or rpmChannel, must be >= 1", fanConfig.ID)
+			hasLabel := len(fanConfig.HwMon.Label) > 0
+			if countTrue(fanConfig.HwMon.Index != 0, fanConfig.HwMon.RpmChannel != 0, hasLabel) != 1 {
+				return fmt.Errorf("fan %s: must have one of index, rpmChannel (must be >= 1) or label", fanConfig.ID)
+			}
+			if hasLabel {
+				err := validateLabelPattern(fanConfig.HwMon.Label)
+				if err != nil {
+					return fmt.Errorf("fan %s: invalid label: %w", fanConfig.ID, err)
+				}
 			}
 			if fanConfig.HwMon.Index < 0 {
 				return fmt.Errorf("fan %s: invalid index, must be >= 1", fanConfig.ID)
@@ -447,12 +878,22 @@ func validateFans(config *Configuration) error {
 			if cm.OnExit != nil {
 				hasRestore := cm.OnExit.Restore != nil
 				hasNone := cm.OnExit.None != nil
+				hasAutoPoints := cm.OnExit.AutoPoints != nil
 				hasMode := cm.OnExit.ControlMode != nil
 				hasSpeed := cm.OnExit.Speed != nil
 
-				if !hasRestore && !hasNone && !hasMode && !hasSpeed {
+				if !hasRestore && !hasNone && !hasAutoPoints && !hasMode && !hasSpeed {
 					return fmt.Errorf("fan '%s': controlMode.onExit is set but no option is specified", fanConfig.ID)
 				}
+				if hasAutoPoints {
+					if countTrue(hasRestore, hasNone, hasMode, hasSpeed) > 0 {
+						return fmt.Errorf("fan '%s': controlMode.onExit autoPoints cannot be combined with other options", fanConfig.ID)
+					}
+					err := validateAutoPoints(fanConfig, cm.OnExit.AutoPoints, config)
+					if err != nil {
+						return err
+					}
+				}
 				if (hasRestore || hasNone) && (hasMode || hasSpeed) {
 					return fmt.Errorf("fan '%s': controlMode.onExit restore/none cannot be combined with controlMode/speed", fanConfig.ID)
 				}
@@ -504,6 +945,15 @@ func curveIdExists(curveId string, config *Configuration) bool {
 	return false
 }
 
+func getCurveConfig(curveId string, curves []CurveConfig) *CurveConfig {
+	for i := range curves {
+		if curves[i].ID == curveId {
+			return &curves[i]
+		}
+	}
+	return nil
+}
+
 func validateControlModeValue(fanID, field, s string) error {
 	if _, err := strconv.Atoi(s); err == nil {
 		return nil // valid integer
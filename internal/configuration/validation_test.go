@@ -75,7 +75,7 @@ func TestValidateFanSubConfigIsMissing(t *testing.T) {
 	err := ValidateConfig(&config, "")
 
 	// THEN
-	assert.EqualError(t, err, "fan fan: sub-configuration for fan is missing, use one of: hwmon | nvidia | file | cmd")
+	assert.EqualError(t, err, "fan fan: sub-configuration for fan is missing, use one of: hwmon | nvidia | file | cmd | group")
 }
 
 func TestValidateFanCurveWithIdIsNotDefined(t *testing.T) {
@@ -100,6 +100,47 @@ func TestValidateFanCurveWithIdIsNotDefined(t *testing.T) {
 	assert.EqualError(t, err, "fan fan: no curve definition with id 'curve' found")
 }
 
+func TestValidateFanFailsafePwmOutOfRange(t *testing.T) {
+	// GIVEN
+	failsafePwm := 256
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				File: &FileSensorConfig{
+					Path: "",
+				},
+			},
+		},
+		Curves: []CurveConfig{
+			{
+				ID: "curve",
+				Linear: &LinearCurveConfig{
+					Sensor: "sensor",
+					Min:    0,
+					Max:    100,
+				},
+			},
+		},
+		Fans: []FanConfig{
+			{
+				ID:          "fan",
+				Curve:       "curve",
+				FailsafePwm: &failsafePwm,
+				File: &FileFanConfig{
+					Path: "",
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: invalid failsafePwm, must be in range [0..255]")
+}
+
 func TestValidateCurveSubConfigSensorIdIsMissing(t *testing.T) {
 	// GIVEN
 	config := Configuration{
@@ -408,7 +449,7 @@ func TestValidateSensorSubConfigSensorIdIsMissing(t *testing.T) {
 	err := ValidateConfig(&config, "")
 
 	// THEN
-	assert.EqualError(t, err, "sensor sensor: sub-configuration for sensor is missing, use one of: hwmon | nvidia | file | cmd | disk")
+	assert.EqualError(t, err, "sensor sensor: sub-configuration for sensor is missing, use one of: hwmon | nvidia | file | cmd | disk | thermalZone | cpuUsage | rapl | remote | aggregate | expression | derivative")
 }
 
 func TestValidateSensor(t *testing.T) {
@@ -431,6 +472,221 @@ func TestValidateSensor(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestValidateSensorHwMonInvalidLabelPattern(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				HwMon: &HwMonSensorConfig{
+					Platform: "nct6798",
+					Label:    "CPUTIN(",
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.ErrorContains(t, err, "sensor sensor: invalid label: ")
+}
+
+func TestValidateStreamingCmdSensorInvalidRestartDelay(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				Cmd: &CmdSensorConfig{
+					Exec: "/usr/local/bin/ipmi-temp-stream",
+					Streaming: &CmdStreamingConfig{
+						StaleTimeout:    10 * time.Second,
+						RestartDelay:    time.Minute,
+						MaxRestartDelay: time.Second,
+					},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: invalid streaming.maxRestartDelay, must be >= restartDelay")
+}
+
+func TestValidateDerivativeSensorUnknownSensor(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "cpu_slope",
+				Derivative: &DerivativeSensorConfig{
+					Sensor: "cpu",
+					Window: 10 * time.Second,
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor cpu_slope: no sensor definition with id 'cpu' found")
+}
+
+func TestValidateSensorInvalidUnit(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:   "sensor",
+				Unit: "fahrenheit",
+				File: &FileSensorConfig{
+					Path: "",
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: invalid unit 'fahrenheit', must be one of: celsius | celsiusPerSecond | percent | watt | volt | ampere | rpm")
+}
+
+func TestValidateSensorInputUnit(t *testing.T) {
+	var tests = []struct {
+		tn      string
+		config  SensorConfig
+		wantErr string
+	}{{
+		tn:     "fahrenheit",
+		config: SensorConfig{ID: "sensor", InputUnit: SensorInputUnitFahrenheit, Cmd: &CmdSensorConfig{Exec: "/usr/bin/echo"}},
+	}, {
+		tn:      "invalid",
+		config:  SensorConfig{ID: "sensor", InputUnit: "rankine", Cmd: &CmdSensorConfig{Exec: "/usr/bin/echo"}},
+		wantErr: "sensor sensor: invalid inputUnit 'rankine', must be one of: millicelsius | celsius | fahrenheit | kelvin",
+	}, {
+		tn:      "non-temperature sensor",
+		config:  SensorConfig{ID: "sensor", InputUnit: SensorInputUnitCelsius, Unit: SensorUnitWatt, Cmd: &CmdSensorConfig{Exec: "/usr/bin/echo"}},
+		wantErr: "sensor sensor: inputUnit can only be used with temperature sensors",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.tn, func(t *testing.T) {
+			// GIVEN
+			config := Configuration{
+				Sensors: []SensorConfig{tt.config},
+			}
+
+			// WHEN
+			err := ValidateConfig(&config, "")
+
+			// THEN
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRemoteSensor(t *testing.T) {
+	var tests = []struct {
+		tn      string
+		config  RemoteSensorConfig
+		wantErr string
+	}{{
+		tn:     "valid",
+		config: RemoteSensorConfig{Url: "http://rack-1:9001/sensor/cpu_package/", Timeout: 2 * time.Second, MaxAge: 30 * time.Second},
+	}, {
+		tn:      "relative url",
+		config:  RemoteSensorConfig{Url: "/sensor/cpu_package/", Timeout: 2 * time.Second},
+		wantErr: "sensor remote: invalid remote url '/sensor/cpu_package/', must be an absolute http(s) URL",
+	}, {
+		tn:      "invalid timeout",
+		config:  RemoteSensorConfig{Url: "https://rack-1/temp"},
+		wantErr: "sensor remote: invalid remote timeout, must be > 0",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.tn, func(t *testing.T) {
+			// WHEN
+			err := validateRemoteSensor("remote", &tt.config)
+
+			// THEN
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAggregateSensor(t *testing.T) {
+	var tests = []struct {
+		tn      string
+		config  AggregateSensorConfig
+		wantErr string
+	}{{
+		tn:     "disk",
+		config: AggregateSensorConfig{Source: AggregateSourceDisk, Match: "ata-WDC*", Function: AggregateFunctionMax},
+	}, {
+		tn:     "hwmon",
+		config: AggregateSensorConfig{Source: AggregateSourceHwMon, Platform: "coretemp-*", LabelMatch: "Core *", Function: AggregateFunctionMedian},
+	}, {
+		tn:      "unknown source",
+		config:  AggregateSensorConfig{Source: "nvidia", Match: "*"},
+		wantErr: "sensor sensor: invalid aggregate source 'nvidia', must be one of: disk | hwmon",
+	}, {
+		tn:      "disk without match",
+		config:  AggregateSensorConfig{Source: AggregateSourceDisk},
+		wantErr: "sensor sensor: aggregate sensor with source disk requires a match pattern",
+	}, {
+		tn:      "hwmon without platform",
+		config:  AggregateSensorConfig{Source: AggregateSourceHwMon},
+		wantErr: "sensor sensor: aggregate sensor with source hwmon requires a platform pattern",
+	}, {
+		tn:      "invalid pattern",
+		config:  AggregateSensorConfig{Source: AggregateSourceDisk, Match: "ata-[WDC"},
+		wantErr: "sensor sensor: invalid aggregate pattern 'ata-[WDC': syntax error in pattern",
+	}, {
+		tn:      "unknown function",
+		config:  AggregateSensorConfig{Source: AggregateSourceDisk, Match: "*", Function: "sum"},
+		wantErr: "sensor sensor: invalid aggregate function 'sum', must be one of: max | average | median",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.tn, func(t *testing.T) {
+			// GIVEN
+			config := Configuration{
+				Sensors: []SensorConfig{
+					{
+						ID:        "sensor",
+						Aggregate: &tt.config,
+					},
+				},
+			}
+
+			// WHEN
+			err := ValidateConfig(&config, "")
+
+			// THEN
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestValidateDuplicateSensorId(t *testing.T) {
 	// GIVEN
 	sensorId := "sensor"
@@ -458,6 +714,198 @@ func TestValidateDuplicateSensorId(t *testing.T) {
 	assert.EqualError(t, err, fmt.Sprintf("duplicate sensor id detected: %s", sensorId))
 }
 
+func TestValidateExpressionSensor(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:   "cpu",
+				File: &FileSensorConfig{Path: ""},
+			},
+			{
+				ID:   "gpu",
+				File: &FileSensorConfig{Path: ""},
+			},
+			{
+				ID:         "combined",
+				Expression: &ExpressionSensorConfig{Formula: "max(cpu, gpu - 10)"},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func TestValidateExpressionSensorInvalidFormula(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:         "combined",
+				Expression: &ExpressionSensorConfig{Formula: "max(cpu,"},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor combined: invalid formula: unexpected 'end of expression' at position 8")
+}
+
+func TestValidateExpressionSensorUnknownSensor(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:         "combined",
+				Expression: &ExpressionSensorConfig{Formula: "cpu + 5"},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor combined: no sensor definition with id 'cpu' found")
+}
+
+func TestValidateExpressionSensorSelfReference(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:         "combined",
+				Expression: &ExpressionSensorConfig{Formula: "combined + 5"},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor combined: a sensor cannot reference itself")
+}
+
+func TestValidateSensorFilterMultipleTypes(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:   "sensor",
+				File: &FileSensorConfig{Path: ""},
+				Filter: &SensorFilterConfig{
+					Ema:    &EmaSensorFilterConfig{Alpha: 0.5},
+					Median: &MedianSensorFilterConfig{WindowSize: 5},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: filter must use exactly one of: sma | ema | median | kalman")
+}
+
+func TestValidateSensorFilterInvalidEmaAlpha(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:   "sensor",
+				File: &FileSensorConfig{Path: ""},
+				Filter: &SensorFilterConfig{
+					Ema: &EmaSensorFilterConfig{Alpha: 1.5},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: invalid ema alpha, must be in range (0..1]")
+}
+
+func TestValidateSensorFilterInvalidMedianWindowSize(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:   "sensor",
+				File: &FileSensorConfig{Path: ""},
+				Filter: &SensorFilterConfig{
+					Median: &MedianSensorFilterConfig{WindowSize: -1},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: invalid median windowSize, must be >= 0 (0 = tempRollingWindowSize)")
+}
+
+func TestValidateSensorInvalidPollingRate(t *testing.T) {
+	// GIVEN
+	pollingRate := time.Duration(0)
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:          "sensor",
+				File:        &FileSensorConfig{Path: ""},
+				PollingRate: &pollingRate,
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: invalid pollingRate, must be > 0")
+}
+
+func TestValidateExpressionSensorCycle(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:         "a",
+				Expression: &ExpressionSensorConfig{Formula: "b + 1"},
+			},
+			{
+				ID:         "b",
+				Expression: &ExpressionSensorConfig{Formula: "max(a, c)"},
+			},
+			{
+				ID:   "c",
+				File: &FileSensorConfig{Path: ""},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.Contains(t, err.Error(), "you have created a sensor dependency cycle")
+	assert.Contains(t, err.Error(), "a")
+	assert.Contains(t, err.Error(), "b")
+}
+
 func TestValidateFanHasIndexOrChannel(t *testing.T) {
 	// GIVEN
 	config := Configuration{
@@ -493,7 +941,46 @@ func TestValidateFanHasIndexOrChannel(t *testing.T) {
 	err := ValidateConfig(&config, "")
 
 	// THEN
-	assert.EqualError(t, err, "fan fan: must have one of index or rpmChannel, must be >= 1")
+	assert.EqualError(t, err, "fan fan: must have one of index, rpmChannel (must be >= 1) or label")
+}
+
+func TestValidateFanInvalidLabelPattern(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Fans: []FanConfig{
+			{
+				ID:    "fan",
+				Curve: "curve",
+				HwMon: &HwMonFanConfig{
+					Label: "CPU_FAN[",
+				},
+			},
+		},
+		Curves: []CurveConfig{
+			{
+				ID: "curve",
+				Linear: &LinearCurveConfig{
+					Sensor: "sensor",
+					Min:    0,
+					Max:    100,
+				},
+			},
+		},
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				File: &FileSensorConfig{
+					Path: "",
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := ValidateConfig(&config, "")
+
+	// THEN
+	assert.ErrorContains(t, err, "fan fan: invalid label: ")
 }
 
 func TestValidateFanIndex(t *testing.T) {
@@ -1050,3 +1537,338 @@ func TestFanConfig_PwmSetDelay_Absent(t *testing.T) {
 	// THEN
 	assert.Nil(t, fanConfig.PwmSetDelay)
 }
+
+func TestValidateControlMode_OnExit_AutoPoints(t *testing.T) {
+	var tests = []struct {
+		tn        string
+		modify    func(config *Configuration)
+		wantErr   string
+		autoPoint OnExitAutoPointsConfig
+	}{{
+		tn: "valid",
+	}, {
+		tn:        "explicit mode",
+		autoPoint: OnExitAutoPointsConfig{Mode: 5},
+	}, {
+		tn:        "manual mode",
+		autoPoint: OnExitAutoPointsConfig{Mode: 1},
+		wantErr:   "fan 'fan': controlMode.onExit.autoPoints.mode must be an automatic pwm_enable value >= 2, got 1",
+	}, {
+		tn: "non-hwmon fan",
+		modify: func(config *Configuration) {
+			config.Fans[0].HwMon = nil
+			config.Fans[0].File = &FileFanConfig{Path: "/dev/null"}
+		},
+		wantErr: "fan 'fan': controlMode.onExit autoPoints is only supported by hwmon fans",
+	}, {
+		tn: "non-linear curve",
+		modify: func(config *Configuration) {
+			config.Curves = append(config.Curves,
+				CurveConfig{ID: "curve2", Linear: &LinearCurveConfig{Sensor: "sensor", Min: 40, Max: 80}},
+				CurveConfig{ID: "function", Function: &FunctionCurveConfig{Type: FunctionMaximum, Curves: []string{"curve", "curve2"}}},
+			)
+			config.Fans[0].Curve = "function"
+		},
+		wantErr: "fan 'fan': controlMode.onExit autoPoints requires a linear curve",
+	}, {
+		tn: "non-temperature sensor",
+		modify: func(config *Configuration) {
+			config.Sensors[0].Unit = SensorUnitWatt
+		},
+		wantErr: "fan 'fan': controlMode.onExit autoPoints requires a curve based on a temperature sensor",
+	}, {
+		tn: "combined with speed",
+		modify: func(config *Configuration) {
+			speed := 128
+			config.Fans[0].ControlMode.OnExit.Speed = &speed
+		},
+		wantErr: "fan 'fan': controlMode.onExit autoPoints cannot be combined with other options",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.tn, func(t *testing.T) {
+			// GIVEN
+			autoPoints := tt.autoPoint
+			config := minimalFanConfigWithControlMode(&ControlModeConfig{
+				OnExit: &OnExitConfig{AutoPoints: &autoPoints},
+			})
+			config.Fans[0].File = nil
+			config.Fans[0].HwMon = &HwMonFanConfig{Platform: "nct6798", RpmChannel: 1, PwmChannel: 1}
+			if tt.modify != nil {
+				tt.modify(&config)
+			}
+
+			// WHEN
+			err := ValidateConfig(&config, "")
+
+			// THEN
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func createGroupFanTestConfig(group *GroupFanConfig, memberCurve string) Configuration {
+	return Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:   "sensor",
+				File: &FileSensorConfig{Path: ""},
+			},
+		},
+		Curves: []CurveConfig{
+			{
+				ID: "curve",
+				Linear: &LinearCurveConfig{
+					Sensor: "sensor",
+					Min:    0,
+					Max:    100,
+				},
+			},
+		},
+		Fans: []FanConfig{
+			{
+				ID:    "group",
+				Curve: "curve",
+				Group: group,
+			},
+			{
+				ID:    "front1",
+				Curve: memberCurve,
+				File:  &FileFanConfig{Path: "front1_pwm"},
+			},
+			{
+				ID:   "front2",
+				File: &FileFanConfig{Path: "front2_pwm"},
+			},
+		},
+	}
+}
+
+func TestValidateFanGroup(t *testing.T) {
+	tests := []struct {
+		name        string
+		group       *GroupFanConfig
+		memberCurve string
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			group: &GroupFanConfig{Members: []GroupFanMemberConfig{
+				{Fan: "front1"},
+				{Fan: "front2", Multiplier: 0.8, Offset: -10},
+			}},
+		},
+		{
+			name:        "no members",
+			group:       &GroupFanConfig{},
+			expectedErr: "fan group: group has no members",
+		},
+		{
+			name:        "unknown member",
+			group:       &GroupFanConfig{Members: []GroupFanMemberConfig{{Fan: "rear"}}},
+			expectedErr: "fan group: no fan definition with id 'rear' found for group member",
+		},
+		{
+			name:        "group as member",
+			group:       &GroupFanConfig{Members: []GroupFanMemberConfig{{Fan: "front1"}, {Fan: "front2"}, {Fan: "group"}}},
+			expectedErr: "fan group: group member group can't be a group itself",
+		},
+		{
+			name:        "duplicate member",
+			group:       &GroupFanConfig{Members: []GroupFanMemberConfig{{Fan: "front1"}, {Fan: "front1"}}},
+			expectedErr: "fan group: member front1 is already a member of group group",
+		},
+		{
+			name:        "negative multiplier",
+			group:       &GroupFanConfig{Members: []GroupFanMemberConfig{{Fan: "front1"}, {Fan: "front2", Multiplier: -1}}},
+			expectedErr: "fan group: invalid multiplier of group member front2, must be >= 0",
+		},
+		{
+			name:        "offset out of range",
+			group:       &GroupFanConfig{Members: []GroupFanMemberConfig{{Fan: "front1"}, {Fan: "front2", Offset: 256}}},
+			expectedErr: "fan group: invalid offset of group member front2, must be in range [-255..255]",
+		},
+		{
+			name:        "member with curve",
+			group:       &GroupFanConfig{Members: []GroupFanMemberConfig{{Fan: "front1"}, {Fan: "front2"}}},
+			memberCurve: "curve",
+			expectedErr: "fan front1: members of a fan group can't have a curve, the fan is controlled by group group",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// GIVEN
+			config := createGroupFanTestConfig(tt.group, tt.memberCurve)
+
+			// WHEN
+			err := ValidateConfig(&config, "")
+
+			// THEN
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestValidateFanControlTarget(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(fanConfig *FanConfig)
+		expectedErr string
+	}{
+		{
+			name: "rpm",
+			modify: func(fanConfig *FanConfig) {
+				fanConfig.ControlTarget = ControlTargetRpm
+				fanConfig.File = nil
+				fanConfig.HwMon = &HwMonFanConfig{Index: 1}
+			},
+		},
+		{
+			name:        "rpm with file fan",
+			modify:      func(fanConfig *FanConfig) { fanConfig.ControlTarget = ControlTargetRpm },
+			expectedErr: "fan fan: controlTarget rpm is only supported by hwmon and nvidia fans",
+		},
+		{
+			name:        "unknown",
+			modify:      func(fanConfig *FanConfig) { fanConfig.ControlTarget = "speed" },
+			expectedErr: "fan fan: invalid controlTarget 'speed', use one of: pwm | rpm",
+		},
+		{
+			name: "rpm with unscaled curve values",
+			modify: func(fanConfig *FanConfig) {
+				fanConfig.ControlTarget = ControlTargetRpm
+				fanConfig.UseUnscaledCurveValues = true
+			},
+			expectedErr: "fan fan: controlTarget rpm can't be combined with useUnscaledCurveValues",
+		},
+		{
+			name: "rpm with direct control algorithm",
+			modify: func(fanConfig *FanConfig) {
+				fanConfig.ControlTarget = ControlTargetRpm
+				fanConfig.ControlAlgorithm = &ControlAlgorithmConfig{Direct: &DirectControlAlgorithmConfig{}}
+			},
+			expectedErr: "fan fan: controlTarget rpm requires the pid control algorithm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// GIVEN
+			fanConfig := FanConfig{
+				ID:    "fan",
+				Curve: "curve",
+				File:  &FileFanConfig{Path: "fan_pwm"},
+			}
+			tt.modify(&fanConfig)
+			config := Configuration{
+				Sensors: []SensorConfig{
+					{ID: "sensor", File: &FileSensorConfig{Path: ""}},
+				},
+				Curves: []CurveConfig{
+					{ID: "curve", Linear: &LinearCurveConfig{Sensor: "sensor", Min: 0, Max: 100}},
+				},
+				Fans: []FanConfig{fanConfig},
+			}
+
+			// WHEN
+			err := ValidateConfig(&config, "")
+
+			// THEN
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestValidateFanSpinUpAndZeroRpm(t *testing.T) {
+	negativeDwell := -time.Second
+	tests := []struct {
+		name        string
+		modify      func(fanConfig *FanConfig)
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			modify: func(fanConfig *FanConfig) {
+				fanConfig.SpinUp = &SpinUpConfig{Pwm: SpinUpPwmMax, Duration: time.Second}
+				fanConfig.ZeroRpm = &ZeroRpmConfig{Sensor: "sensor", StopTemp: 50, StartTemp: 60}
+			},
+		},
+		{
+			name:        "invalid spinUp pwm",
+			modify:      func(fanConfig *FanConfig) { fanConfig.SpinUp = &SpinUpConfig{Pwm: "min"} },
+			expectedErr: "fan fan: invalid spinUp pwm 'min', use one of: start | max",
+		},
+		{
+			name: "zeroRpm with neverStop",
+			modify: func(fanConfig *FanConfig) {
+				fanConfig.NeverStop = true
+				fanConfig.ZeroRpm = &ZeroRpmConfig{Sensor: "sensor", StopTemp: 50, StartTemp: 60}
+			},
+			expectedErr: "fan fan: zeroRpm can't be combined with neverStop",
+		},
+		{
+			name: "zeroRpm unknown sensor",
+			modify: func(fanConfig *FanConfig) {
+				fanConfig.ZeroRpm = &ZeroRpmConfig{Sensor: "gpu", StopTemp: 50, StartTemp: 60}
+			},
+			expectedErr: "fan fan: no sensor definition with id 'gpu' found for zeroRpm",
+		},
+		{
+			name: "zeroRpm without hysteresis",
+			modify: func(fanConfig *FanConfig) {
+				fanConfig.ZeroRpm = &ZeroRpmConfig{Sensor: "sensor", StopTemp: 60, StartTemp: 60}
+			},
+			expectedErr: "fan fan: zeroRpm stopTemp must be lower than startTemp",
+		},
+		{
+			name: "zeroRpm negative minDwell",
+			modify: func(fanConfig *FanConfig) {
+				fanConfig.ZeroRpm = &ZeroRpmConfig{Sensor: "sensor", StopTemp: 50, StartTemp: 60, MinDwell: &negativeDwell}
+			},
+			expectedErr: "fan fan: invalid zeroRpm minDwell, must be >= 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// GIVEN
+			fanConfig := FanConfig{
+				ID:    "fan",
+				Curve: "curve",
+				File:  &FileFanConfig{Path: "fan_pwm"},
+			}
+			tt.modify(&fanConfig)
+			config := Configuration{
+				Sensors: []SensorConfig{
+					{ID: "sensor", File: &FileSensorConfig{Path: ""}},
+				},
+				Curves: []CurveConfig{
+					{ID: "curve", Linear: &LinearCurveConfig{Sensor: "sensor", Min: 0, Max: 100}},
+				},
+				Fans: []FanConfig{fanConfig},
+			}
+
+			// WHEN
+			err := ValidateConfig(&config, "")
+
+			// THEN
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
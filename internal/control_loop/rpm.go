@@ -0,0 +1,98 @@
+package control_loop
+
+import (
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+var (
+	// DefaultRpmConfig are the default gains of the RpmControlLoop, which are applied to the
+	// RPM error and result in a PWM correction
+	DefaultRpmConfig = PidControlLoopDefaults{
+		P: 0.02,
+		I: 0.05,
+		D: 0,
+	}
+)
+
+// RpmControlLoop is a control loop that approaches a target RPM value (instead of a PWM value)
+// using the actual RPM of the fan as feedback. The measured PWM -> RPM curve data of the fan
+// is used as a feed-forward term, so the PID loop only has to correct the remaining error,
+// f.ex. caused by dust or differences between identical fans.
+type RpmControlLoop struct {
+	p, i, d float64
+
+	pidLoop *util.PidLoop
+
+	// getRpm returns the current (averaged) RPM of the fan
+	getRpm func() float64
+	// getCurveData returns the measured PWM -> RPM curve data of the fan
+	getCurveData func() map[int]float64
+}
+
+// NewRpmControlLoop creates a RpmControlLoop, which interprets the target passed to Cycle as an RPM value
+// and returns the PWM value to apply to the fan.
+func NewRpmControlLoop(
+	p float64,
+	i float64,
+	d float64,
+	getRpm func() float64,
+	getCurveData func() map[int]float64,
+) *RpmControlLoop {
+	return &RpmControlLoop{
+		p:            p,
+		i:            i,
+		d:            d,
+		pidLoop:      util.NewPidLoop(p, i, d, -255, 255, true, true),
+		getRpm:       getRpm,
+		getCurveData: getCurveData,
+	}
+}
+
+func (l *RpmControlLoop) Cycle(target float64) float64 {
+	if target <= 0 {
+		// the fan is supposed to stop, start over once it should spin again
+		l.pidLoop = util.NewPidLoop(l.p, l.i, l.d, -255, 255, true, true)
+		return 0
+	}
+
+	feedForward := feedForwardPwm(l.getCurveData(), target)
+	measured := l.getRpm()
+	// limit the correction to the headroom left by the feed-forward term, so the integral
+	// doesn't wind up while the resulting PWM is already saturated at 0 or 255
+	l.pidLoop.SetOutputLimits(-feedForward, 255-feedForward)
+	correction := l.pidLoop.Loop(target, measured)
+
+	ui.Debug("RpmControlLoop: target(rpm): %.0f, measured(rpm): %.0f, feed-forward(pwm): %.2f, correction(pwm): %.2f", target, measured, feedForward, correction)
+
+	// ensure we are within sane bounds
+	return util.Coerce(feedForward+correction, 0, 255)
+}
+
+// feedForwardPwm returns the PWM value expected to result in the given RPM, by interpolating
+// linearly between the points of the given PWM -> RPM curve data.
+// Returns the highest PWM of the curve data, if the RPM can't be reached.
+func feedForwardPwm(curveData map[int]float64, targetRpm float64) float64 {
+	if len(curveData) <= 0 || targetRpm <= 0 {
+		return 0
+	}
+
+	keys := util.SortedKeys(curveData)
+	for idx, pwm := range keys {
+		rpm := curveData[pwm]
+		if rpm < targetRpm {
+			continue
+		}
+		if idx == 0 {
+			return float64(pwm)
+		}
+		lowerPwm := keys[idx-1]
+		lowerRpm := curveData[lowerPwm]
+		if rpm <= lowerRpm {
+			return float64(pwm)
+		}
+		ratio := (targetRpm - lowerRpm) / (rpm - lowerRpm)
+		return float64(lowerPwm) + ratio*float64(pwm-lowerPwm)
+	}
+	return float64(keys[len(keys)-1])
+}
@@ -0,0 +1,78 @@
+package control_loop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedForwardPwm(t *testing.T) {
+	// GIVEN
+	curveData := map[int]float64{
+		0:   0,
+		50:  0,
+		100: 600,
+		200: 1200,
+		255: 1250,
+	}
+
+	// THEN
+	assert.Equal(t, 0.0, feedForwardPwm(curveData, 0))
+	assert.Equal(t, 75.0, feedForwardPwm(curveData, 300))
+	assert.Equal(t, 150.0, feedForwardPwm(curveData, 900))
+	assert.Equal(t, 200.0, feedForwardPwm(curveData, 1200))
+	// unreachable RPM values result in the highest PWM
+	assert.Equal(t, 255.0, feedForwardPwm(curveData, 2000))
+	assert.Equal(t, 0.0, feedForwardPwm(nil, 900))
+}
+
+func TestRpmControlLoop(t *testing.T) {
+	// GIVEN
+	measured := 600.0
+	curveData := map[int]float64{0: 0, 255: 1020}
+	loop := NewRpmControlLoop(
+		DefaultRpmConfig.P, DefaultRpmConfig.I, DefaultRpmConfig.D,
+		func() float64 { return measured },
+		func() map[int]float64 { return curveData },
+	)
+
+	// WHEN
+	reached := loop.Cycle(600)
+
+	// THEN
+	assert.InDelta(t, 150.0, reached, 0.001)
+
+	// WHEN
+	// the fan is slower than its curve data suggests
+	measured = 400
+	slow := loop.Cycle(600)
+
+	// THEN
+	assert.Greater(t, slow, 150.0)
+
+	// WHEN
+	stopped := loop.Cycle(0)
+
+	// THEN
+	assert.Equal(t, 0.0, stopped)
+}
+
+func TestRpmControlLoop_LimitsCorrectionToFeedForwardHeadroom(t *testing.T) {
+	// GIVEN
+	measured := 0.0
+	curveData := map[int]float64{0: 0, 255: 1020}
+	loop := NewRpmControlLoop(
+		1, 0, 0,
+		func() float64 { return measured },
+		func() map[int]float64 { return curveData },
+	)
+
+	// WHEN
+	// the fan doesn't spin at all, f.ex. because it is blocked
+	saturated := loop.Cycle(600)
+
+	// THEN
+	assert.Equal(t, 255.0, saturated)
+	// the correction is limited to 255 - 150 (feed-forward)
+	assert.Equal(t, 105.0, loop.pidLoop.Loop(600, measured))
+}
@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"path"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// computeAutoPoints translates a linear curve into the given number of hardware trip points.
+// toPwm maps a curve value in [0..255] to the raw PWM value applied to the fan.
+//
+// If the curve has fewer steps than the chip has points, all steps are used and the remaining
+// points continue the last step in 1°C increments. Otherwise, the points are spread evenly
+// across the temperature range of the curve.
+func computeAutoPoints(curve configuration.LinearCurveConfig, count int, toPwm func(value float64) int) []fans.AutoPoint {
+	steps := curve.Steps
+	if steps == nil {
+		steps = map[int]float64{curve.Min: 0, curve.Max: fans.MaxPwmValue}
+	}
+	temps := util.SortedKeys(steps)
+
+	if len(temps) > count {
+		first := float64(temps[0])
+		last := float64(temps[len(temps)-1])
+		temps = make([]int, count)
+		for i := range temps {
+			temps[i] = int(math.Round(first + (last-first)*float64(i)/float64(count-1)))
+		}
+	}
+
+	points := make([]fans.AutoPoint, 0, count)
+	for _, temp := range temps {
+		value, err := util.CalculateInterpolatedCurveValue(steps, util.InterpolationTypeLinear, float64(temp))
+		if err != nil {
+			value = fans.MaxPwmValue
+		}
+		points = append(points, fans.AutoPoint{Temp: temp * 1000, Pwm: toPwm(value)})
+	}
+	for len(points) < count {
+		last := points[len(points)-1]
+		points = append(points, fans.AutoPoint{Temp: last.Temp + 1000, Pwm: last.Pwm})
+	}
+	return points
+}
+
+// programAutoPoints translates the linear curve of the fan into the trip points of its hwmon chip,
+// and verifies them by reading them back
+func (f *DefaultFanController) programAutoPoints() ([]fans.AutoPoint, error) {
+	fan, ok := f.fan.(*fans.HwMonFan)
+	if !ok {
+		return nil, fmt.Errorf("fan %s: auto points are only supported by hwmon fans", f.fan.GetId())
+	}
+	if fan.GetConfig().ControlTarget == configuration.ControlTargetRpm {
+		return nil, fmt.Errorf("fan %s: auto points are not supported with controlTarget rpm", fan.GetId())
+	}
+	f.curveMutex.RLock()
+	curve, ok := f.curve.(*curves.LinearSpeedCurve)
+	f.curveMutex.RUnlock()
+	if !ok || curve.Config.Linear == nil {
+		return nil, fmt.Errorf("fan %s: auto points require a linear curve", fan.GetId())
+	}
+	count := fan.GetAutoPointCount()
+	if count <= 0 {
+		return nil, fmt.Errorf("fan %s: driver doesn't expose auto points", fan.GetId())
+	}
+	err := checkAutoPointTempInput(fan, curve)
+	if err != nil {
+		return nil, err
+	}
+
+	points := computeAutoPoints(*curve.Config.Linear, count, func(value float64) int {
+		return f.applyPwmMapToTarget(f.computeSpeedTarget(value))
+	})
+	err = fan.SetAutoPoints(points)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, err := fan.GetAutoPoints()
+	if err != nil {
+		return nil, err
+	}
+	for i, point := range points {
+		// chips usually store temperatures in whole degrees
+		if math.Abs(float64(actual[i].Temp-point.Temp)) >= 1000 || actual[i].Pwm != point.Pwm {
+			return actual, fmt.Errorf("fan %s: auto point %d was written as %d°C/%d, but reads back as %d°C/%d",
+				fan.GetId(), i+1, point.Temp/1000, point.Pwm, actual[i].Temp/1000, actual[i].Pwm)
+		}
+	}
+	return actual, nil
+}
+
+// checkAutoPointTempInput verifies that the trip points of the fan refer to the temperature input of the
+// sensor of the given curve, since their temperatures are taken from the curve. If the chip doesn't expose
+// its temperature source, or the sensor isn't a hwmon sensor, this can't be verified and a warning is logged.
+func checkAutoPointTempInput(fan *fans.HwMonFan, curve *curves.LinearSpeedCurve) error {
+	tempInput, err := fan.GetAutoPointTempInput()
+	if err != nil {
+		return err
+	}
+	sensorId := curve.Config.Linear.Sensor
+	var sensorInput string
+	if sensor, ok := curve.GetSensor(); ok && sensor.GetConfig().HwMon != nil {
+		sensorInput = sensor.GetConfig().HwMon.TempInput
+	}
+	if len(tempInput) <= 0 || len(sensorInput) <= 0 {
+		ui.Warning("Fan %s: unable to verify that the chip uses the sensor %s as temperature source of the auto points", fan.GetId(), sensorId)
+		return nil
+	}
+	if path.Clean(tempInput) != path.Clean(sensorInput) {
+		return fmt.Errorf("fan %s: the chip uses %s as temperature source of the auto points, but the curve uses sensor %s (%s)",
+			fan.GetId(), tempInput, sensorId, sensorInput)
+	}
+	return nil
+}
+
+// ProgramAutoPoints programs the curve of the fan into the trip points of its hwmon chip outside of a
+// running controller, f.ex. from the CLI. Persisted fan data is used, but nothing is measured.
+func (f *DefaultFanController) ProgramAutoPoints() ([]fans.AutoPoint, error) {
+	err := f.persistence.Init()
+	if err != nil {
+		return nil, err
+	}
+	fanPwmData, err := f.persistence.LoadFanRpmData(f.fan)
+	if err == nil {
+		err = f.fan.AttachFanRpmCurveData(&fanPwmData)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ui.Warning("Fan %s: no fan curve data found, using the configured PWM range", f.fan.GetId())
+	}
+
+	loaded, err := f.loadPwmMap()
+	if err != nil {
+		return nil, err
+	}
+	if !loaded {
+		for i := range f.pwmMapping {
+			f.pwmMapping[i] = i
+		}
+	}
+	return f.programAutoPoints()
+}
+
+// getAutoPointsOnExit returns the auto points configuration of the fan, if its curve should be
+// programmed into the hardware when fan2go exits
+func (f *DefaultFanController) getAutoPointsOnExit() *configuration.OnExitAutoPointsConfig {
+	if cfg := f.fan.GetConfig().ControlMode; cfg != nil && cfg.OnExit != nil {
+		return cfg.OnExit.AutoPoints
+	}
+	return nil
+}
+
+// switchToAutoPoints programs the curve of the fan into its hwmon chip and hands control over to it
+func (f *DefaultFanController) switchToAutoPoints(config configuration.OnExitAutoPointsConfig) error {
+	_, err := f.programAutoPoints()
+	if err != nil {
+		return err
+	}
+
+	if fan, ok := f.fan.(*fans.HwMonFan); ok && config.Mode > 0 {
+		fan.SetAutomaticControlMode(config.Mode)
+	}
+	return f.fan.SetControlMode(fans.ControlModeAutomatic)
+}
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/registry"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func identityToPwm(value float64) int {
+	return int(math.Round(value))
+}
+
+func TestComputeAutoPoints_MinMax(t *testing.T) {
+	// GIVEN
+	curve := configuration.LinearCurveConfig{Sensor: "cpu", Min: 40, Max: 80}
+
+	// WHEN
+	points := computeAutoPoints(curve, 4, identityToPwm)
+
+	// THEN
+	assert.Equal(t, []fans.AutoPoint{
+		{Temp: 40000, Pwm: 0},
+		{Temp: 80000, Pwm: 255},
+		{Temp: 81000, Pwm: 255},
+		{Temp: 82000, Pwm: 255},
+	}, points)
+}
+
+func TestComputeAutoPoints_MoreStepsThanPoints(t *testing.T) {
+	// GIVEN
+	curve := configuration.LinearCurveConfig{
+		Sensor: "cpu",
+		Steps: map[int]float64{
+			20: 0,
+			40: 50,
+			50: 100,
+			60: 200,
+			80: 255,
+		},
+	}
+
+	// WHEN
+	points := computeAutoPoints(curve, 3, identityToPwm)
+
+	// THEN
+	assert.Equal(t, []fans.AutoPoint{
+		{Temp: 20000, Pwm: 0},
+		{Temp: 50000, Pwm: 100},
+		{Temp: 80000, Pwm: 255},
+	}, points)
+}
+
+func TestProgramAutoPoints(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	for i := 1; i <= 3; i++ {
+		for _, kind := range []string{"temp", "pwm"} {
+			err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("pwm1_auto_point%d_%s", i, kind)), []byte("0"), 0644)
+			require.NoError(t, err)
+		}
+	}
+	minPwm := 50
+	fan := &fans.HwMonFan{
+		Config: configuration.FanConfig{
+			ID:    "cpu",
+			HwMon: &configuration.HwMonFanConfig{PwmChannel: 1, SysfsPath: dir},
+		},
+		MinPwm: &minPwm,
+	}
+	curve := &curves.LinearSpeedCurve{
+		Config: configuration.CurveConfig{
+			ID:     "curve",
+			Linear: &configuration.LinearCurveConfig{Sensor: "cpu", Min: 40, Max: 80},
+		},
+	}
+	controller := DefaultFanController{
+		fan:        fan,
+		curve:      curve,
+		pwmMapping: createOneToOnePwmMap(),
+	}
+
+	// WHEN
+	points, err := controller.programAutoPoints()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []fans.AutoPoint{
+		{Temp: 40000, Pwm: 0},
+		{Temp: 80000, Pwm: 255},
+		{Temp: 81000, Pwm: 255},
+	}, points)
+}
+
+func TestProgramAutoPoints_NonLinearCurve(t *testing.T) {
+	// GIVEN
+	controller := DefaultFanController{
+		fan:   &fans.HwMonFan{Config: configuration.FanConfig{ID: "cpu", HwMon: &configuration.HwMonFanConfig{}}},
+		curve: &curves.PidSpeedCurve{},
+	}
+
+	// WHEN
+	_, err := controller.programAutoPoints()
+
+	// THEN
+	assert.EqualError(t, err, "fan cpu: auto points require a linear curve")
+}
+
+// createAutoPointsFan creates a hwmon fan with 2 auto points using the temperature input selected by tempSel,
+// and a linear curve based on a hwmon sensor with the given input
+func createAutoPointsFan(t *testing.T, tempSel string, sensorInput string) (*fans.HwMonFan, *curves.LinearSpeedCurve) {
+	dir := t.TempDir()
+	for i := 1; i <= 2; i++ {
+		for _, kind := range []string{"temp", "pwm"} {
+			err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("pwm1_auto_point%d_%s", i, kind)), []byte("0"), 0644)
+			require.NoError(t, err)
+		}
+	}
+	err := os.WriteFile(filepath.Join(dir, "pwm1_temp_sel"), []byte(tempSel), 0644)
+	require.NoError(t, err)
+
+	fan := &fans.HwMonFan{
+		Config: configuration.FanConfig{
+			ID:    "cpu",
+			HwMon: &configuration.HwMonFanConfig{PwmChannel: 1, SysfsPath: dir},
+		},
+	}
+	curve := &curves.LinearSpeedCurve{
+		Config: configuration.CurveConfig{
+			ID:     "curve",
+			Linear: &configuration.LinearCurveConfig{Sensor: "cpu_temp", Min: 40, Max: 80},
+		},
+	}
+	reg := registry.NewRegistry()
+	reg.RegisterSensor(&sensors.HwmonSensor{
+		Config: configuration.SensorConfig{
+			ID:    "cpu_temp",
+			HwMon: &configuration.HwMonSensorConfig{TempInput: filepath.Join(dir, sensorInput)},
+		},
+	})
+	reg.RegisterCurve(curve)
+	return fan, curve
+}
+
+func TestProgramAutoPoints_TempInputMatches(t *testing.T) {
+	// GIVEN
+	fan, curve := createAutoPointsFan(t, "2", "temp2_input")
+	controller := DefaultFanController{
+		fan:        fan,
+		curve:      curve,
+		pwmMapping: createOneToOnePwmMap(),
+	}
+
+	// WHEN
+	points, err := controller.programAutoPoints()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Len(t, points, 2)
+}
+
+func TestProgramAutoPoints_TempInputMismatch(t *testing.T) {
+	// GIVEN
+	fan, curve := createAutoPointsFan(t, "1", "temp2_input")
+	controller := DefaultFanController{
+		fan:        fan,
+		curve:      curve,
+		pwmMapping: createOneToOnePwmMap(),
+	}
+
+	// WHEN
+	_, err := controller.programAutoPoints()
+
+	// THEN
+	assert.ErrorContains(t, err, "fan cpu: the chip uses ")
+	assert.ErrorContains(t, err, "temp1_input as temperature source of the auto points, but the curve uses sensor cpu_temp")
+}
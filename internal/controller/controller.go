@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/markusressel/fan2go/internal/control_loop"
@@ -16,7 +18,9 @@ import (
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/curves"
 	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/hwmon"
 	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/markusressel/fan2go/internal/util"
 	"github.com/oklog/run"
@@ -26,22 +30,38 @@ var (
 	ErrFanStalledAtMaxPwm = errors.New("fan stalled at max pwm")
 )
 
+// resumeGracePeriod is the time after a resume from suspend in which sanity checks are suppressed
+const resumeGracePeriod = 10 * time.Second
+
 var InitializationSequenceMutex sync.Mutex
 
 type FanControllerStatistics struct {
 	UnexpectedPwmValueCount int
 	IncreasedMinPwmCount    int
 	MinPwmOffset            int
+	RestartCount            int
+	// relative RPM loss of the refined fan curve data compared to the initial measurement,
+	// at the PWM value observed last
+	CurveDrift float64
 }
 
 type FanController interface {
 	// Run starts the control loop
 	Run(ctx context.Context) error
 
+	// RunSupervised starts the control loop and restarts it, if it panics or stops on its own
+	RunSupervised(ctx context.Context) error
+
 	GetFanId() string
 
 	GetStatistics() FanControllerStatistics
 
+	// GetLastCycleTime returns the time the control loop last updated the fan speed, zero if it never did
+	GetLastCycleTime() time.Time
+
+	// IsRestarting returns true after the control loop failed, until the restarted one completed a cycle
+	IsRestarting() bool
+
 	UpdateFanSpeed() error
 
 	// UpdateCurve dynamically updates the curve reference
@@ -51,13 +71,9 @@ type FanController interface {
 	RunInitialization(ctx context.Context) (map[int]float64, error)
 }
 
-type FanStateSnapshot struct {
-	// the ControlMode the fan was in before the controller started
-	ControlMode fans.ControlMode
-	// the raw pwm value read from the fan before the controller started
-	// Note: this is the raw value, no pwmMap is applied to it
-	PwmValue int
-}
+// FanStateSnapshot is the state of a fan before the controller started,
+// it is persisted to restore it after an unclean shutdown
+type FanStateSnapshot = persistence.FanStateSnapshot
 
 type DefaultFanController struct {
 	// protects concurrent access to curve
@@ -136,6 +152,49 @@ type DefaultFanController struct {
 
 	// lastFanModeCheckTime is the last time we checked if some third party changed the fan control mode
 	lastFanModeCheckTime time.Time
+
+	// true while the fan is driven at its failsafe PWM because a sensor of its curve is faulted
+	failsafeActive bool
+
+	// unix nano timestamp of the last completed control loop cycle, 0 if none completed yet
+	lastCycleTime atomic.Int64
+	// true after the control loop failed, until the restarted one completed a cycle
+	restarting atomic.Bool
+
+	// detects resumes from suspend, after which the control mode has to be applied again
+	resumeDetector *util.ResumeDetector
+	// sanity checks are suppressed until this time, since the hardware is reinitialized after a resume
+	resumeGraceUntil time.Time
+
+	// the kick PWM is applied until this time, after the fan was started from a standstill
+	spinUpUntil time.Time
+	// the sensor used to decide whether the fan is stopped in zero-RPM mode (nil if not configured)
+	zeroRpmSensor sensors.Sensor
+	// true while the fan is stopped by the zero-RPM mode
+	zeroRpmActive bool
+	// the last time zeroRpmActive changed
+	zeroRpmChangedAt time.Time
+
+	// the persisted minPwmOffset, including the history of its changes
+	minPwmOffsetState persistence.MinPwmOffsetState
+	// while a lower minPwmOffset is probed, the fan has to keep spinning until this time
+	minPwmOffsetProbeUntil time.Time
+	// the next time a lower minPwmOffset may be probed
+	nextMinPwmOffsetProbe time.Time
+
+	// the fan curve data as initially measured, used to detect drift of the refined curve data (nil if unknown)
+	referenceCurveData map[int]float64
+	// the pwm value (before applying the pwmMap) that has been applied steadily since curveRefinementSince
+	curveRefinementTarget int
+	curveRefinementSince  time.Time
+	// the last time a steady-state observation was blended into the fan curve data
+	lastCurveRefinement time.Time
+	// true if the fan curve data was refined since it was saved last
+	curveDataChanged bool
+	// the next time the refined fan curve data is saved
+	nextCurveDataSave time.Time
+	// true while the drift of the fan curve data exceeds the threshold and a warning was logged
+	curveDriftWarned bool
 }
 
 func (f *DefaultFanController) UpdateCurve(curve curves.SpeedCurve) {
@@ -173,6 +232,24 @@ func (f *DefaultFanController) GetStatistics() FanControllerStatistics {
 	return f.stats
 }
 
+func (f *DefaultFanController) GetLastCycleTime() time.Time {
+	lastCycleTime := f.lastCycleTime.Load()
+	if lastCycleTime == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, lastCycleTime)
+}
+
+// markCycleCompleted records that the control loop updated the fan speed
+func (f *DefaultFanController) markCycleCompleted() {
+	f.lastCycleTime.Store(time.Now().UnixNano())
+	f.restarting.Store(false)
+}
+
+func (f *DefaultFanController) IsRestarting() bool {
+	return f.restarting.Load()
+}
+
 func (f *DefaultFanController) prepareController() (err error) {
 	err = f.persistence.Init()
 	if err != nil {
@@ -197,20 +274,41 @@ func (f *DefaultFanController) storeCurrentFanState() error {
 	if err != nil {
 		ui.Warning("Cannot read pwm value of %s", fan.GetId())
 	}
+	snapshot := captureFanState(fan, pwm)
+
+	if group, ok := fan.(*fans.GroupFan); ok {
+		// members may be in different states, so the state of each member is captured individually
+		snapshot.Members = map[string]FanStateSnapshot{}
+		for _, member := range group.Members {
+			memberPwm := member.Fan.GetMinPwm()
+			if member.Fan.Supports(fans.FeaturePwmSensor) {
+				memberPwm, err = member.Fan.GetPwm()
+				if err != nil {
+					ui.Warning("Cannot read pwm value of %s", member.Id)
+				}
+			}
+			snapshot.Members[member.Id] = captureFanState(member.Fan, memberPwm)
+		}
+	}
+	f.originalFanState = &snapshot
+	return nil
+}
+
+// captureFanState creates a snapshot of the given fan with the given pwm value and its current control mode
+func captureFanState(fan fans.Fan, pwm int) FanStateSnapshot {
 	snapshot := FanStateSnapshot{
 		PwmValue: pwm,
 	}
 
 	// store original pwm_enable value
-	if f.fan.Supports(fans.FeatureControlModeRead) {
+	if fan.Supports(fans.FeatureControlModeRead) {
 		controlMode, err := fan.GetControlMode()
 		if err != nil {
 			ui.Warning("Cannot read pwm_enable value of %s", fan.GetId())
 		}
 		snapshot.ControlMode = controlMode
 	}
-	f.originalFanState = &snapshot
-	return nil
+	return snapshot
 }
 
 // storeInitialFanState stores the initial fan state snapshot if it hasn't been captured yet.
@@ -227,6 +325,77 @@ func (f *DefaultFanController) clearInitialFanState() {
 	f.originalFanState = nil
 }
 
+// persistOriginalFanState saves the captured initial fan state snapshot to persistence,
+// so it can be restored even if fan2go is killed before restoreControlMode is called.
+func (f *DefaultFanController) persistOriginalFanState() {
+	if f.persistence == nil || f.originalFanState == nil {
+		return
+	}
+	err := f.persistence.SaveFanOriginalState(f.fan.GetId(), *f.originalFanState)
+	if err != nil {
+		ui.Warning("Fan %s: unable to persist original fan state: %v", f.fan.GetId(), err)
+	}
+}
+
+// forgetOriginalFanState removes the persisted initial fan state snapshot after it was restored.
+func (f *DefaultFanController) forgetOriginalFanState() {
+	if f.persistence == nil {
+		return
+	}
+	err := f.persistence.DeleteFanOriginalState(f.fan.GetId())
+	if err != nil {
+		ui.Warning("Fan %s: unable to delete persisted original fan state: %v", f.fan.GetId(), err)
+	}
+}
+
+// RestoreOriginalFanState restores the fan state persisted when fan2go took control of the given fan,
+// which is only present if fan2go didn't shut down cleanly since.
+// Returns false, if there was no persisted state to restore.
+func RestoreOriginalFanState(p persistence.Persistence, fan fans.Fan) (bool, error) {
+	state, err := p.LoadFanOriginalState(fan.GetId())
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if group, ok := fan.(*fans.GroupFan); ok && len(state.Members) > 0 {
+		var errs []error
+		for _, member := range group.Members {
+			memberState, ok := state.Members[member.Id]
+			if !ok {
+				continue
+			}
+			errs = append(errs, applyFanState(member.Fan, memberState))
+		}
+		err = errors.Join(errs...)
+	} else {
+		err = applyFanState(fan, *state)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, p.DeleteFanOriginalState(fan.GetId())
+}
+
+// applyFanState sets the control mode and pwm value of the given snapshot on the given fan
+func applyFanState(fan fans.Fan, state FanStateSnapshot) error {
+	if fan.Supports(fans.FeatureControlModeWrite) {
+		err := fan.SetControlMode(state.ControlMode)
+		if err != nil {
+			return fmt.Errorf("fan %s: error restoring original control mode: %w", fan.GetId(), err)
+		}
+	}
+	if state.ControlMode != fans.ControlModeAutomatic {
+		err := fan.SetPwm(state.PwmValue)
+		if err != nil {
+			return fmt.Errorf("fan %s: error restoring original PWM value: %w", fan.GetId(), err)
+		}
+	}
+	return nil
+}
+
 func (f *DefaultFanController) Run(ctx context.Context) error {
 	// prepare the controller by initializing persistence and checking the fan
 	err := f.prepareController()
@@ -234,11 +403,23 @@ func (f *DefaultFanController) Run(ctx context.Context) error {
 		return err
 	}
 
+	if f.originalFanState == nil {
+		// if fan2go didn't shut down cleanly, the fan is still in the state we left it in,
+		// so restore the state from before that run first
+		restored, err := RestoreOriginalFanState(f.persistence, f.fan)
+		if err != nil {
+			ui.Warning("Fan %s: unable to restore original fan state of previous run: %v", f.fan.GetId(), err)
+		} else if restored {
+			ui.Warning("Fan %s: fan2go was not shut down cleanly, restored original fan state of previous run", f.fan.GetId())
+		}
+	}
+
 	// store the current fan state to restore it when stopping the controller
 	err = f.storeInitialFanState()
 	if err != nil {
 		return err
 	}
+	f.persistOriginalFanState()
 
 	fan := f.fan
 
@@ -266,6 +447,9 @@ func (f *DefaultFanController) Run(ctx context.Context) error {
 		return err
 	}
 
+	f.loadMinPwmOffset()
+	f.loadReferenceCurveData(fanPwmData)
+
 	ui.Debug("setPwmToGetPwmMap of fan '%s': %v", fan.GetId(), f.setPwmToGetPwmMap)
 	ui.Debug("pwmMap of fan '%s': %v", fan.GetId(), f.pwmMapping)
 	ui.Info("PWM settings of fan '%s': Min %d, Start %d, Max %d", fan.GetId(), fan.GetMinPwm(), fan.GetStartPwm(), fan.GetMaxPwm())
@@ -275,6 +459,17 @@ func (f *DefaultFanController) Run(ctx context.Context) error {
 		ui.Warning("Suspicious pwm config of fan '%s': MinPwm (%d) > StartPwm (%d)", fan.GetId(), fan.GetMinPwm(), fan.GetStartPwm())
 	}
 
+	if f.getAutoPointsOnExit() != nil {
+		// program the auto points right away, to detect problems early and to have them in place
+		// if fan2go is killed before it can program them on exit
+		points, err := f.programAutoPoints()
+		if err != nil {
+			ui.Warning("Fan %s: unable to program auto points: %v", fan.GetId(), err)
+		} else {
+			ui.Info("Fan %s: programmed auto points %v", fan.GetId(), points)
+		}
+	}
+
 	// TODO: check if fan.Supports(fans.FeatureControlModeWrite) - or is it ok if it doesn't and our
 	//       default assumption is that it will always be in manual mode then?
 	//       (trySetManualPwm() just returns nil in that case)
@@ -299,7 +494,8 @@ func (f *DefaultFanController) Run(ctx context.Context) error {
 		// === rpm monitoring
 		pollingRate := configuration.CurrentConfig.RpmPollingRate
 
-		g.Add(func() error {
+		g.Add(func() (err error) {
+			defer f.recoverPanic(&err)
 			tick := time.NewTicker(pollingRate)
 			defer tick.Stop()
 			for {
@@ -320,7 +516,9 @@ func (f *DefaultFanController) Run(ctx context.Context) error {
 	}
 
 	{
-		g.Add(func() error {
+		f.resumeDetector = util.NewResumeDetector()
+		g.Add(func() (err error) {
+			defer f.recoverPanic(&err)
 			time.Sleep(1 * time.Second)
 			tick := time.NewTicker(f.updateRate)
 			defer tick.Stop()
@@ -331,20 +529,26 @@ func (f *DefaultFanController) Run(ctx context.Context) error {
 				f.restoreControlMode()
 				return nil
 			}
+			f.markCycleCompleted()
 
 			for {
 				select {
 				case <-controllerCtx.Done():
 					ui.Info("Fan %s: Stopping fan controller...", fan.GetId())
+					f.saveRefinedCurveData()
 					f.restoreControlMode()
 					return nil
 				case <-tick.C:
+					if suspended, resumed := f.resumeDetector.Check(); resumed {
+						f.handleResume(suspended)
+					}
 					err = f.UpdateFanSpeed()
 					if err != nil {
 						ui.ErrorAndNotify("Fan Control Error", "Fan %s: %v", fan.GetId(), err)
 						f.restoreControlMode()
 						return nil
 					}
+					f.markCycleCompleted()
 				}
 			}
 		}, func(err error) {
@@ -426,6 +630,10 @@ func (f *DefaultFanController) runInitialization(ctx context.Context) (map[int]f
 	if err != nil {
 		ui.Error("Fan %s: Failed to save RPM data: %v", fan.GetId(), err)
 	}
+	err = f.persistence.SaveFanRpmDataReference(fan.GetId(), curveData)
+	if err != nil {
+		ui.Error("Fan %s: Failed to save RPM reference data: %v", fan.GetId(), err)
+	}
 
 	fanRpmData, err := f.persistence.LoadFanRpmData(fan)
 	if err != nil {
@@ -446,12 +654,20 @@ func (f *DefaultFanController) UpdateFanSpeed() error {
 	fan := f.fan
 
 	f.ensureNoThirdPartyIsMessingWithUs()
+	f.refineCurveData(time.Now())
 
 	// calculate the direct optimal target speed
 	target, err := f.calculateTargetSpeed()
+	if errors.Is(err, sensors.ErrSensorFaulted) {
+		return f.applyFailsafePwm(err)
+	}
 	if err != nil {
 		return err
 	}
+	if f.failsafeActive {
+		ui.Info("Sensors of fan %s recovered, resuming normal operation", fan.GetId())
+		f.failsafeActive = false
+	}
 
 	// ensure target value is within bounds of possible values
 	if target > fans.MaxPwmValue {
@@ -466,10 +682,64 @@ func (f *DefaultFanController) UpdateFanSpeed() error {
 	maxPwm := fan.GetMaxPwm()
 	minPwm := fan.GetMinPwm()
 	shouldNeverStop := fan.ShouldNeverStop()
+	f.updateMinPwmOffsetProbe(time.Now())
+	speedTarget := f.computeSpeedTarget(target)
+	speedTarget = f.applyZeroRpm(speedTarget)
 
-	var speedTarget int
+	if fan.Supports(fans.FeatureRpmSensor) {
+		// make sure fans never stop by validating the current RPM
+		// and adjusting the target PWM value upwards if necessary
+		if f.lastTarget != nil {
+			lastTarget := *f.lastTarget
+			// TODO: check this logic
+			lastSetPwm, err := f.getLastTarget()
+			if err != nil {
+				ui.Warning("Error reading last set PWM value of fan %s: %v", fan.GetId(), err)
+			}
+			lastSetTargetEqualsNewTarget := lastTarget == speedTarget
+			if shouldNeverStop && lastSetTargetEqualsNewTarget && !f.isInResumeGracePeriod() {
+				avgRpm := fan.GetRpmAvg()
+				if avgRpm <= 0 {
+					if speedTarget >= maxPwm {
+						ui.Error("CRITICAL: Fan %s avg. RPM is %d, even at PWM value %d", fan.GetId(), int(avgRpm), lastSetPwm)
+						return ErrFanStalledAtMaxPwm
+					}
+					oldMinPwm := minPwm
+					ui.Warning("Increasing minPWM of %s from %d to %d, which is supposed to never stop, but RPM is %d at PWM %d",
+						fan.GetId(), oldMinPwm, oldMinPwm+1, int(avgRpm), lastSetPwm)
+					f.increaseMinPwmOffset()
+					speedTarget++
+
+					// set the moving avg to a value > 0 to prevent
+					// this increase from happening too fast
+					fan.SetRpmAvg(1)
+				}
+			}
+		}
+	}
+
+	speedTarget = f.applySpinUp(speedTarget)
+
+	err = f.setPwm(speedTarget)
+	if err != nil {
+		// TODO: maybe we should add some kind of critical failure mode here
+		//  in case these errors don't resolve after a while
+		ui.Error("Error setting %s: %v", fan.GetId(), err)
+		f.rebindIfDeviceGone(err)
+	}
+
+	return nil
+}
+
+// computeSpeedTarget maps a curve value in [0..255] to the PWM range of the fan, before the pwmMap is applied to it
+func (f *DefaultFanController) computeSpeedTarget(target float64) (speedTarget int) {
+	fan := f.fan
+	maxPwm := fan.GetMaxPwm()
+	minPwm := fan.GetMinPwm()
+	shouldNeverStop := fan.ShouldNeverStop()
 
-	if fan.GetConfig().UseUnscaledCurveValues {
+	// with an RPM control target, the control loop already computes the PWM value to apply
+	if fan.GetConfig().UseUnscaledCurveValues || f.isRpmControlTarget() {
 		speedTarget = int(math.Round(target))
 		if speedTarget > 0 && speedTarget < minPwm {
 			// the fan wouldn't spin with this PWM value anyway, so set 0 instead
@@ -506,48 +776,75 @@ func (f *DefaultFanController) UpdateFanSpeed() error {
 		speedTarget = minPwm + f.minPwmOffset
 	}
 
-	if fan.Supports(fans.FeatureRpmSensor) {
-		// make sure fans never stop by validating the current RPM
-		// and adjusting the target PWM value upwards if necessary
-		if f.lastTarget != nil {
-			lastTarget := *f.lastTarget
-			// TODO: check this logic
-			lastSetPwm, err := f.getLastTarget()
-			if err != nil {
-				ui.Warning("Error reading last set PWM value of fan %s: %v", fan.GetId(), err)
-			}
-			lastSetTargetEqualsNewTarget := lastTarget == speedTarget
-			if shouldNeverStop && lastSetTargetEqualsNewTarget {
-				avgRpm := fan.GetRpmAvg()
-				if avgRpm <= 0 {
-					if speedTarget >= maxPwm {
-						ui.Error("CRITICAL: Fan %s avg. RPM is %d, even at PWM value %d", fan.GetId(), int(avgRpm), lastSetPwm)
-						return ErrFanStalledAtMaxPwm
-					}
-					oldMinPwm := minPwm
-					ui.Warning("Increasing minPWM of %s from %d to %d, which is supposed to never stop, but RPM is %d at PWM %d",
-						fan.GetId(), oldMinPwm, oldMinPwm+1, int(avgRpm), lastSetPwm)
-					f.increaseMinPwmOffset()
-					speedTarget++
+	return speedTarget
+}
 
-					// set the moving avg to a value > 0 to prevent
-					// this increase from happening too fast
-					fan.SetRpmAvg(1)
-				}
-			}
-		}
+// getFailsafePwm returns the PWM (before applying the pwmMap) this fan is set to, if it can't be controlled safely
+func (f *DefaultFanController) getFailsafePwm() int {
+	if f.fan.GetConfig().FailsafePwm != nil {
+		return *f.fan.GetConfig().FailsafePwm
 	}
+	return fans.MaxPwmValue
+}
 
-	err = f.setPwm(speedTarget)
+// applyFailsafePwm sets the fan to its failsafe PWM value, used while a sensor of its curve is faulted
+func (f *DefaultFanController) applyFailsafePwm(reason error) error {
+	failsafePwm := f.getFailsafePwm()
+
+	if !f.failsafeActive {
+		ui.Warning("Setting fan %s to failsafe PWM %d: %v", f.fan.GetId(), failsafePwm, reason)
+		f.failsafeActive = true
+	}
+
+	err := f.setPwm(failsafePwm)
 	if err != nil {
-		// TODO: maybe we should add some kind of critical failure mode here
-		//  in case these errors don't resolve after a while
-		ui.Error("Error setting %s: %v", fan.GetId(), err)
+		ui.Error("Error setting %s: %v", f.fan.GetId(), err)
+		f.rebindIfDeviceGone(err)
 	}
 
 	return nil
 }
 
+// handleResume applies the manual control mode again after the system resumed from suspend, since many boards
+// reset pwm_enable or the PWM registers on resume. Sanity checks are suppressed for resumeGracePeriod.
+func (f *DefaultFanController) handleResume(suspended time.Duration) {
+	ui.Info("Fan %s: system resumed after %s, re-applying fan control", f.fan.GetId(), suspended.Round(time.Second))
+	f.resumeGraceUntil = time.Now().Add(resumeGracePeriod)
+	f.lastFanModeCheckTime = time.Now()
+
+	err := trySetManualPwm(f.fan)
+	if err != nil {
+		ui.Warning("Fan %s: unable to set manual control mode after resume: %v", f.fan.GetId(), err)
+	}
+}
+
+// isInResumeGracePeriod returns true shortly after a resume from suspend
+func (f *DefaultFanController) isInResumeGracePeriod() bool {
+	return time.Now().Before(f.resumeGraceUntil)
+}
+
+// rebindIfDeviceGone resolves the sysfs paths of a hwmon fan again, if the given error indicates that its
+// hwmon device disappeared, f.ex. after a driver reload or a resume that renumbered the hwmon devices.
+// Since the driver resets the control mode of a re-created device, the manual control mode is applied again.
+func (f *DefaultFanController) rebindIfDeviceGone(err error) {
+	fan, ok := f.fan.(*fans.HwMonFan)
+	if !ok || !hwmon.IsDeviceGoneError(err) {
+		return
+	}
+	changed, err := hwmon.RebindFan(fan)
+	if err != nil {
+		ui.Warning("Unable to rebind fan %s: %v", fan.GetId(), err)
+		return
+	}
+	if !changed {
+		return
+	}
+	ui.Info("Fan %s: hwmon device changed, now using %s", fan.GetId(), fan.GetConfig().HwMon.PwmPath)
+	if err := trySetManualPwm(fan); err != nil {
+		ui.Error("Fan %s: unable to restore control mode after rebinding: %v", fan.GetId(), err)
+	}
+}
+
 // read the current value of a fan RPM sensor and append it to the moving window
 func (f *DefaultFanController) measureRpm(fan fans.Fan) {
 	rpm, err := fan.GetRpm()
@@ -621,13 +918,12 @@ func trySetManualPwm(fan fans.Fan) error {
 }
 
 func (f *DefaultFanController) restoreControlMode() {
+	defer f.forgetOriginalFanState()
+
 	if f.originalFanState == nil {
 		ui.Warning("Skipping fan settings restore for %s, original state was never captured", f.fan.GetId())
 		return
 	}
-	originalControlMode := f.originalFanState.ControlMode
-	originalPwmValue := f.originalFanState.PwmValue
-
 	ui.Info("Trying to restore fan settings for %s...", f.fan.GetId())
 
 	var onExit *configuration.OnExitConfig
@@ -641,6 +937,38 @@ func (f *DefaultFanController) restoreControlMode() {
 		return
 	}
 
+	// autoPoints: hand control over to the trip points of the hwmon chip
+	if onExit != nil && onExit.AutoPoints != nil {
+		err := f.switchToAutoPoints(*onExit.AutoPoints)
+		if err == nil {
+			ui.Info("Fan %s: handed control over to the auto points of its chip", f.fan.GetId())
+			return
+		}
+		ui.Error("Fan %s: unable to switch to auto points, restoring original fan settings instead: %v", f.fan.GetId(), err)
+		onExit = nil
+	}
+
+	if group, ok := f.fan.(*fans.GroupFan); ok && len(f.originalFanState.Members) > 0 {
+		// members may have been in different states, so each of them is restored individually
+		for _, member := range group.Members {
+			state, ok := f.originalFanState.Members[member.Id]
+			if !ok {
+				ui.Warning("Skipping fan settings restore for %s, original state was never captured", member.Id)
+				continue
+			}
+			restoreFanState(member.Fan, state, onExit)
+		}
+		return
+	}
+	restoreFanState(f.fan, *f.originalFanState, onExit)
+}
+
+// restoreFanState applies the given onExit configuration to the given fan, using its original state
+// where the configuration doesn't specify explicit values
+func restoreFanState(fan fans.Fan, originalState FanStateSnapshot, onExit *configuration.OnExitConfig) {
+	originalControlMode := originalState.ControlMode
+	originalPwmValue := originalState.PwmValue
+
 	var controlModeToSet *fans.ControlMode = nil
 	var pwmToSet *int = nil
 
@@ -652,7 +980,7 @@ func (f *DefaultFanController) restoreControlMode() {
 		} else if onExit.ControlMode != nil {
 			parsedControlMode, err := parseControlModeValue(*onExit.ControlMode)
 			if err != nil {
-				ui.Warning("Fan %s: Error parsing controlMode.onExit.controlMode: %v", f.fan.GetId(), err)
+				ui.Warning("Fan %s: Error parsing controlMode.onExit.controlMode: %v", fan.GetId(), err)
 			} else {
 				controlModeToSet = &parsedControlMode
 			}
@@ -681,22 +1009,22 @@ func (f *DefaultFanController) restoreControlMode() {
 	}
 
 	if controlModeToSet != nil {
-		if f.fan.Supports(fans.FeatureControlModeWrite) {
-			if err := f.fan.SetControlMode(*controlModeToSet); err != nil {
+		if fan.Supports(fans.FeatureControlModeWrite) {
+			if err := fan.SetControlMode(*controlModeToSet); err != nil {
 				// if this fails, try to set it to max speed instead
-				if err := f.fan.SetPwm(fans.MaxPwmValue); err != nil {
-					ui.Warning("Unable to restore fan %s, make sure it is running!", f.fan.GetId())
+				if err := fan.SetPwm(fans.MaxPwmValue); err != nil {
+					ui.Warning("Unable to restore fan %s, make sure it is running!", fan.GetId())
 				}
 				return
 			}
 		} else {
-			ui.Warning("Cannot restore control mode of fan %s, writing control mode is not supported", f.fan.GetId())
+			ui.Warning("Cannot restore control mode of fan %s, writing control mode is not supported", fan.GetId())
 		}
 	}
 	if pwmToSet != nil {
 		// restore (default: onExit == nil or onExit.Restore != nil)
-		if err := f.fan.SetPwm(*pwmToSet); err != nil {
-			ui.Warning("Fan %s: Error restoring original PWM value: %v", f.fan.GetId(), err)
+		if err := fan.SetPwm(*pwmToSet); err != nil {
+			ui.Warning("Fan %s: Error restoring original PWM value: %v", fan.GetId(), err)
 		}
 	}
 }
@@ -713,12 +1041,47 @@ func (f *DefaultFanController) calculateTargetSpeed() (float64, error) {
 		return 0, err
 	}
 
+	if f.isRpmControlTarget() {
+		target, err = f.computeTargetRpm(target)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	// the new target speed to set, which approaches the actual target based on the control loop
 	newTarget := f.controlLoop.Cycle(target)
 
 	return newTarget, nil
 }
 
+// IsRpmControlTarget returns true, if the curve values of the given fan are target RPMs, which are approached
+// by a control_loop.RpmControlLoop using the actual RPM of the fan. Requires an RPM sensor.
+func IsRpmControlTarget(fan fans.Fan) bool {
+	return fan.GetConfig().ControlTarget == configuration.ControlTargetRpm &&
+		fan.Supports(fans.FeatureRpmSensor)
+}
+
+func (f *DefaultFanController) isRpmControlTarget() bool {
+	return IsRpmControlTarget(f.fan)
+}
+
+// computeTargetRpm maps a curve value in [0..255] to the measured RPM range of the fan.
+// 0 (or actually < 1) is mapped to 0 RPM, if the fan is allowed to stop.
+func (f *DefaultFanController) computeTargetRpm(target float64) (float64, error) {
+	fan := f.fan
+	if target < 1.0 && !fan.ShouldNeverStop() {
+		return 0, nil
+	}
+	curveData := fan.GetFanRpmCurveData()
+	if curveData == nil || len(*curveData) <= 0 {
+		return 0, fmt.Errorf("fan %s: controlTarget rpm requires measured fan curve data", fan.GetId())
+	}
+	minRpm, maxRpm := fans.ComputeRpmBoundariesFromCurveData(*curveData)
+	// scale [1..255] to [minRpm..maxRpm]
+	target = math.Max(target-1.0, 0)
+	return minRpm + (target/(fans.MaxPwmValue-1))*(maxRpm-minRpm), nil
+}
+
 func (f *DefaultFanController) getLastTarget() (int, error) {
 	lastSetPwm := 0
 	if f.lastTarget != nil {
@@ -759,6 +1122,10 @@ func (f *DefaultFanController) ensureNoThirdPartyIsMessingWithUs() {
 		// sanity checks are disabled, so we don't check for third party changes
 		return
 	}
+	if f.isInResumeGracePeriod() {
+		// the PWM registers might have been reset by the resume
+		return
+	}
 
 	if !f.fan.Supports(fans.FeaturePwmSensor) {
 		// we cannot read the PWM value, so we also cannot check if third party changed the PWM value
@@ -913,6 +1280,26 @@ func (f *DefaultFanController) computePwmMap() (err error) {
 		defer InitializationSequenceMutex.Unlock()
 	}
 
+	loaded, err := f.loadPwmMap()
+	if err != nil || loaded {
+		return err
+	}
+
+	{
+		ui.Info("Computing pwm map...")
+		err = f.computePwmMapAutomatically()
+		if err != nil {
+			ui.Error("Fan %s: Error computing pwm map: %v", f.fan.GetId(), err)
+			return err
+		}
+	}
+
+	ui.Debug("Saving pwm map to fan...")
+	return f.persistence.SaveFanPwmMap(f.fan.GetId(), f.pwmMapping[:])
+}
+
+// loadPwmMap loads the pwmMap of the fan from its configuration or the persistence, if available
+func (f *DefaultFanController) loadPwmMap() (loaded bool, err error) {
 	cfg := f.fan.GetConfig().PwmMap
 
 	if cfg != nil {
@@ -921,33 +1308,33 @@ func (f *DefaultFanController) computePwmMap() (err error) {
 			for i := 0; i < 256; i++ {
 				f.pwmMapping[i] = i
 			}
-			return nil
+			return true, nil
 		}
 		if cfg.Values != nil {
 			ui.Info("Fan %s: Using user-defined step pwm map", f.fan.GetId())
 			pts := map[int]int(*cfg.Values)
 			expanded, err := util.InterpolateStepInt(&pts, 0, 255)
 			if err != nil {
-				return fmt.Errorf("error expanding pwmMap (values): %w", err)
+				return false, fmt.Errorf("error expanding pwmMap (values): %w", err)
 			}
 			for i := 0; i < 256; i++ {
 				f.pwmMapping[i] = expanded[i]
 			}
-			return nil
+			return true, nil
 		}
 		if cfg.Linear != nil {
 			ui.Info("Fan %s: Using user-defined linear pwm map", f.fan.GetId())
 			pts := map[int]int(*cfg.Linear)
 			expanded, err := util.InterpolateLinearlyInt(&pts, 0, 255)
 			if err != nil {
-				return fmt.Errorf("error expanding pwmMap (linear): %w", err)
+				return false, fmt.Errorf("error expanding pwmMap (linear): %w", err)
 			}
 			for i := 0; i < 256; i++ {
 				f.pwmMapping[i] = expanded[i]
 			}
-			return nil
+			return true, nil
 		}
-		// cfg.Autodetect != nil → fall through to autodetect logic below
+		// cfg.Autodetect != nil → fall through to the saved (autodetected) pwm map
 	}
 
 	savedPwmMap, err := f.persistence.LoadFanPwmMap(f.fan.GetId())
@@ -956,20 +1343,9 @@ func (f *DefaultFanController) computePwmMap() (err error) {
 		for i := 0; i < 256; i++ {
 			f.pwmMapping[i] = savedPwmMap[i]
 		}
-		return nil
-	}
-
-	{
-		ui.Info("Computing pwm map...")
-		err = f.computePwmMapAutomatically()
-		if err != nil {
-			ui.Error("Fan %s: Error computing pwm map: %v", f.fan.GetId(), err)
-			return err
-		}
+		return true, nil
 	}
-
-	ui.Debug("Saving pwm map to fan...")
-	return f.persistence.SaveFanPwmMap(f.fan.GetId(), f.pwmMapping[:])
+	return false, nil
 }
 
 func (f *DefaultFanController) computePwmMapAutomatically() (err error) {
@@ -1022,8 +1398,7 @@ func (f *DefaultFanController) updateDistinctPwmValues() {
 }
 
 func (f *DefaultFanController) increaseMinPwmOffset() {
-	f.minPwmOffset += 1
-	f.stats.MinPwmOffset = f.minPwmOffset
+	f.setMinPwmOffset(f.minPwmOffset+1, MinPwmOffsetReasonStall)
 	f.stats.IncreasedMinPwmCount += 1
 }
 
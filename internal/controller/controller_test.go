@@ -3,6 +3,10 @@ package controller
 import (
 	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
 	"testing"
 	"time"
 
@@ -10,14 +14,17 @@ import (
 
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/util"
 	"github.com/stretchr/testify/assert"
 )
 
 type MockSensor struct {
-	ID        string
-	Name      string
-	MovingAvg float64
+	ID          string
+	Name        string
+	MovingAvg   float64
+	FaultReason string
 }
 
 func (sensor MockSensor) GetId() string {
@@ -29,7 +36,7 @@ func (sensor MockSensor) GetLabel() string {
 }
 
 func (sensor MockSensor) GetConfig() configuration.SensorConfig {
-	panic("not implemented")
+	return configuration.SensorConfig{ID: sensor.ID}
 }
 
 func (sensor MockSensor) GetValue() (result float64, err error) {
@@ -44,6 +51,18 @@ func (sensor *MockSensor) SetMovingAvg(avg float64) {
 	sensor.MovingAvg = avg
 }
 
+func (sensor MockSensor) IsFaulted() bool {
+	return len(sensor.FaultReason) > 0
+}
+
+func (sensor MockSensor) GetFaultReason() string {
+	return sensor.FaultReason
+}
+
+func (sensor *MockSensor) SetFault(reason string) {
+	sensor.FaultReason = reason
+}
+
 type MockCurve struct {
 	ID    string
 	Value *float64
@@ -73,22 +92,28 @@ type MockFan struct {
 	ControlMode                                  fans.ControlMode
 	PWM                                          int
 	MinPWM                                       int
+	StartPWM                                     int
 	MaxPWM                                       int
 	RPM                                          int
 	curveId                                      string
 	shouldNeverStop                              bool
 	sanityCheckFanModeChangedByThirdPartyEnabled bool
 	useUnscaledCurveValues                       bool
+	controlTarget                                configuration.ControlTarget
 	speedCurve                                   *map[int]float64
 	PwmMap                                       *configuration.PwmMapConfig
 	SetPwmToGetPwmMap                            *configuration.SetPwmToGetPwmMapConfig
 	ControlModeConfig                            *configuration.ControlModeConfig
 	PwmSetDelay                                  *time.Duration
+	FailsafePwm                                  *int
+	SpinUp                                       *configuration.SpinUpConfig
+	ZeroRpm                                      *configuration.ZeroRpmConfig
+	HwMon                                        *configuration.HwMonFanConfig
 	setPwmAlwaysFails                            bool
 }
 
 func (fan MockFan) GetStartPwm() int {
-	return 0
+	return fan.StartPWM
 }
 
 func (fan *MockFan) GetLabel() string {
@@ -202,8 +227,12 @@ func (fan MockFan) GetConfig() configuration.FanConfig {
 		SetPwmToGetPwmMap:      fan.SetPwmToGetPwmMap,
 		ControlMode:            fan.ControlModeConfig,
 		PwmSetDelay:            fan.PwmSetDelay,
+		FailsafePwm:            fan.FailsafePwm,
+		SpinUp:                 fan.SpinUp,
+		ZeroRpm:                fan.ZeroRpm,
 		UseUnscaledCurveValues: fan.useUnscaledCurveValues,
-		HwMon:                  nil, // Not used in this mock
+		ControlTarget:          fan.controlTarget,
+		HwMon:                  fan.HwMon,
 		File:                   nil, // Not used in this mock
 		Cmd:                    nil, // Not used in this mock
 		SanityCheck: configuration.SanityCheckConfig{
@@ -390,11 +419,22 @@ var (
 type mockPersistence struct {
 	hasPwmMap       bool
 	hasSavedPwmData bool
+	// saved minPwm offsets by fan id, not persisted if nil
+	minPwmOffsets map[string]persistence.MinPwmOffsetState
+	// saved fan curve data by fan id, not persisted if nil
+	rpmData map[string]map[int]float64
+	// saved fan curve reference data by fan id, not persisted if nil
+	rpmDataReferences map[string]map[int]float64
 }
 
 func (p mockPersistence) Init() (err error) { return nil }
 
-func (p mockPersistence) SaveFanRpmData(fan fans.Fan) (err error) { return nil }
+func (p mockPersistence) SaveFanRpmData(fan fans.Fan) (err error) {
+	if p.rpmData != nil && fan.GetFanRpmCurveData() != nil {
+		p.rpmData[fan.GetId()] = maps.Clone(*fan.GetFanRpmCurveData())
+	}
+	return nil
+}
 func (p mockPersistence) LoadFanRpmData(fan fans.Fan) (map[int]float64, error) {
 	if p.hasSavedPwmData {
 		fanCurveDataMap := map[int]float64{}
@@ -429,6 +469,50 @@ func (p mockPersistence) LoadFanPwmMap(fanId string) ([]int, error) {
 func (p mockPersistence) SaveFanPwmMap(fanId string, pwmMap []int) (err error) { return nil }
 func (p mockPersistence) DeleteFanPwmMap(fanId string) (err error)             { return nil }
 
+func (p mockPersistence) LoadFanOriginalState(fanId string) (*persistence.FanStateSnapshot, error) {
+	return nil, os.ErrNotExist
+}
+func (p mockPersistence) SaveFanOriginalState(fanId string, state persistence.FanStateSnapshot) (err error) {
+	return nil
+}
+func (p mockPersistence) DeleteFanOriginalState(fanId string) (err error) { return nil }
+
+func (p mockPersistence) LoadFanMinPwmOffset(fanId string) (*persistence.MinPwmOffsetState, error) {
+	state, ok := p.minPwmOffsets[fanId]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return &state, nil
+}
+func (p mockPersistence) SaveFanMinPwmOffset(fanId string, state persistence.MinPwmOffsetState) (err error) {
+	if p.minPwmOffsets != nil {
+		p.minPwmOffsets[fanId] = state
+	}
+	return nil
+}
+func (p mockPersistence) DeleteFanMinPwmOffset(fanId string) (err error) {
+	delete(p.minPwmOffsets, fanId)
+	return nil
+}
+
+func (p mockPersistence) LoadFanRpmDataReference(fanId string) (map[int]float64, error) {
+	curveData, ok := p.rpmDataReferences[fanId]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return curveData, nil
+}
+func (p mockPersistence) SaveFanRpmDataReference(fanId string, curveData map[int]float64) (err error) {
+	if p.rpmDataReferences != nil {
+		p.rpmDataReferences[fanId] = maps.Clone(curveData)
+	}
+	return nil
+}
+func (p mockPersistence) DeleteFanRpmDataReference(fanId string) (err error) {
+	delete(p.rpmDataReferences, fanId)
+	return nil
+}
+
 func createOneToOnePwmMap() [256]int {
 	var pwmMap = [256]int{}
 	for i := fans.MinPwmValue; i <= fans.MaxPwmValue; i++ {
@@ -437,6 +521,27 @@ func createOneToOnePwmMap() [256]int {
 	return pwmMap
 }
 
+// createTestController creates a controller for the given fan, which maps PWM values one-to-one and
+// applies the given curve value directly. Tests may change configuration.CurrentConfig, it is restored
+// after the test.
+func createTestController(t *testing.T, fan fans.Fan, p mockPersistence, curveValue *float64) *DefaultFanController {
+	originalConfig := configuration.CurrentConfig
+	t.Cleanup(func() {
+		configuration.CurrentConfig = originalConfig
+	})
+
+	controller := &DefaultFanController{
+		persistence: p,
+		fan:         fan,
+		curve:       &MockCurve{ID: "curve", Value: curveValue},
+		updateRate:  time.Duration(100),
+		controlLoop: control_loop.NewDirectControlLoop(nil),
+		pwmMapping:  createOneToOnePwmMap(),
+	}
+	controller.updateDistinctPwmValues()
+	return controller
+}
+
 func CreateFan(neverStop bool, curveData map[int]float64, startPwm *int) (fan fans.Fan, err error) {
 	configuration.CurrentConfig.RpmRollingWindowSize = 10
 
@@ -590,6 +695,85 @@ func TestCalculateTargetSpeedNeverStop(t *testing.T) {
 	assert.Equal(t, 0.0, target)
 }
 
+func TestCalculateTargetSpeed_RpmControlTarget(t *testing.T) {
+	// GIVEN
+	curveValue := 128.0
+	curve := &MockCurve{
+		ID:    "curve",
+		Value: &curveValue,
+	}
+
+	fan := &MockFan{
+		ID:            "fan",
+		curveId:       curve.GetId(),
+		controlTarget: configuration.ControlTargetRpm,
+		speedCurve:    &LinearFan,
+	}
+	controlLoop := control_loop.NewRpmControlLoop(
+		control_loop.DefaultRpmConfig.P, control_loop.DefaultRpmConfig.I, control_loop.DefaultRpmConfig.D,
+		func() float64 { return fan.GetRpmAvg() },
+		func() map[int]float64 { return *fan.GetFanRpmCurveData() },
+	)
+
+	controller := DefaultFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		curve:       curve,
+		updateRate:  time.Duration(100),
+		controlLoop: controlLoop,
+		pwmMapping:  createOneToOnePwmMap(),
+	}
+
+	// WHEN
+	// the curve value 128 is scaled to the RPM range [50..255] of the fan, resulting in 152.5 RPM,
+	// which is already reached
+	fan.RPM = 152
+	targetReached, errReached := controller.calculateTargetSpeed()
+	// the fan is slower than expected from its curve data, f.ex. because of dust
+	fan.RPM = 100
+	targetSlow, errSlow := controller.calculateTargetSpeed()
+	speedTarget := controller.computeSpeedTarget(targetSlow)
+
+	// THEN
+	assert.NoError(t, errReached)
+	assert.InDelta(t, 152.5, targetReached, 1)
+	assert.NoError(t, errSlow)
+	assert.Greater(t, targetSlow, 153.0)
+	// the control loop already computes the PWM value, so it is not scaled again
+	assert.Equal(t, int(math.Round(targetSlow)), speedTarget)
+}
+
+func TestCalculateTargetSpeed_RpmControlTarget_MissingCurveData(t *testing.T) {
+	// GIVEN
+	curveValue := 128.0
+	curve := &MockCurve{
+		ID:    "curve",
+		Value: &curveValue,
+	}
+
+	fan := &MockFan{
+		ID:            "fan",
+		curveId:       curve.GetId(),
+		controlTarget: configuration.ControlTargetRpm,
+	}
+
+	controller := DefaultFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		curve:       curve,
+		updateRate:  time.Duration(100),
+		controlLoop: control_loop.NewDirectControlLoop(nil),
+		pwmMapping:  createOneToOnePwmMap(),
+	}
+
+	// WHEN
+	_, err := controller.calculateTargetSpeed()
+
+	// THEN
+	assert.True(t, IsRpmControlTarget(fan))
+	assert.ErrorContains(t, err, "requires measured fan curve data")
+}
+
 func TestFanWithStartPwmConfig(t *testing.T) {
 	// GIVEN
 	startPwm := 50
@@ -958,6 +1142,73 @@ func TestFanController_RunStopsOnCurveEvaluationError(t *testing.T) {
 	}
 }
 
+func TestFanController_UpdateFanSpeed_SensorFaulted_AppliesFailsafePwm(t *testing.T) {
+	// GIVEN
+	failsafePwm := 200
+	fan := &MockFan{
+		ID:          "fan",
+		PWM:         50,
+		MinPWM:      20,
+		MaxPWM:      255,
+		RPM:         1000,
+		FailsafePwm: &failsafePwm,
+		speedCurve:  &LinearFan,
+	}
+
+	controller := DefaultFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		updateRate:  time.Duration(100),
+		curve: MockCurve{
+			ID:  "curve",
+			Err: fmt.Errorf("sensor 'cpu': %w", sensors.ErrSensorFaulted),
+		},
+		controlLoop: control_loop.NewDirectControlLoop(nil),
+	}
+	err := controller.computeFanSpecificMappings()
+	assert.NoError(t, err)
+
+	// WHEN
+	err = controller.UpdateFanSpeed()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.True(t, controller.failsafeActive)
+	assert.Equal(t, 200, fan.PWM)
+}
+
+func TestFanController_UpdateFanSpeed_SensorFaulted_DefaultsToMaxPwm(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{
+		ID:         "fan",
+		PWM:        50,
+		MinPWM:     20,
+		MaxPWM:     255,
+		RPM:        1000,
+		speedCurve: &LinearFan,
+	}
+
+	controller := DefaultFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		updateRate:  time.Duration(100),
+		curve: MockCurve{
+			ID:  "curve",
+			Err: fmt.Errorf("sensor 'cpu': %w", sensors.ErrSensorFaulted),
+		},
+		controlLoop: control_loop.NewDirectControlLoop(nil),
+	}
+	err := controller.computeFanSpecificMappings()
+	assert.NoError(t, err)
+
+	// WHEN
+	err = controller.UpdateFanSpeed()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, fans.MaxPwmValue, fan.PWM)
+}
+
 func TestFanController_PwmMapping2(t *testing.T) {
 	// GIVEN
 	fan := &MockFan{
@@ -2415,3 +2666,161 @@ func TestClearInitialFanState_ResetsSnapshot(t *testing.T) {
 	controller.clearInitialFanState()
 	assert.Nil(t, controller.originalFanState)
 }
+
+func TestRestoreOriginalFanState(t *testing.T) {
+	// GIVEN
+	p := persistence.NewPersistence(t.TempDir() + "/fan2go.db")
+	err := p.SaveFanOriginalState("fan", FanStateSnapshot{PwmValue: 100, ControlMode: fans.ControlModePWM})
+	assert.NoError(t, err)
+	fan := &mockFanForRestore{
+		MockFan:             MockFan{ID: "fan", PWM: 255, ControlMode: fans.ControlModePWM},
+		supportsControlMode: true,
+	}
+
+	// WHEN
+	restored, err := RestoreOriginalFanState(p, fan)
+	restoredAgain, errAgain := RestoreOriginalFanState(p, fan)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.True(t, restored)
+	assert.Equal(t, []fans.ControlMode{fans.ControlModePWM}, fan.controlModeHistory)
+	assert.Equal(t, []int{100}, fan.pwmHistory)
+	// the persisted state is removed after it was restored
+	assert.NoError(t, errAgain)
+	assert.False(t, restoredAgain)
+}
+
+func createGroupFanForRestore(t *testing.T) (*fans.GroupFan, *mockFanForRestore, *mockFanForRestore) {
+	member1 := &mockFanForRestore{
+		MockFan:             MockFan{ID: "member1", PWM: 120, ControlMode: fans.ControlModePWM},
+		supportsControlMode: true,
+	}
+	member2 := &mockFanForRestore{
+		MockFan:             MockFan{ID: "member2", PWM: 0, ControlMode: fans.ControlModeAutomatic},
+		supportsControlMode: true,
+	}
+	group, err := fans.NewGroupFan(configuration.FanConfig{
+		ID: "group",
+		Group: &configuration.GroupFanConfig{
+			Members: []configuration.GroupFanMemberConfig{{Fan: "member1"}, {Fan: "member2"}},
+		},
+	}, map[string]fans.Fan{"member1": member1, "member2": member2}, nil)
+	assert.NoError(t, err)
+	return group, member1, member2
+}
+
+func TestRestoreControlMode_GroupFan_RestoresEachMember(t *testing.T) {
+	// GIVEN
+	group, member1, member2 := createGroupFanForRestore(t)
+	controller := DefaultFanController{
+		fan: group,
+	}
+	err := controller.storeInitialFanState()
+	assert.NoError(t, err)
+	_ = group.SetControlMode(fans.ControlModePWM)
+	_ = group.SetPwm(200)
+	member1.pwmHistory = nil
+	member1.controlModeHistory = nil
+	member2.pwmHistory = nil
+	member2.controlModeHistory = nil
+
+	// WHEN
+	controller.restoreControlMode()
+
+	// THEN
+	assert.Equal(t, []fans.ControlMode{fans.ControlModePWM}, member1.controlModeHistory)
+	assert.Equal(t, []int{120}, member1.pwmHistory)
+	assert.Equal(t, []fans.ControlMode{fans.ControlModeAutomatic}, member2.controlModeHistory)
+	assert.Empty(t, member2.pwmHistory)
+}
+
+func TestRestoreOriginalFanState_GroupFan(t *testing.T) {
+	// GIVEN
+	p := persistence.NewPersistence(t.TempDir() + "/fan2go.db")
+	group, member1, member2 := createGroupFanForRestore(t)
+	err := p.SaveFanOriginalState("group", FanStateSnapshot{
+		Members: map[string]FanStateSnapshot{
+			"member1": {PwmValue: 100, ControlMode: fans.ControlModePWM},
+			"member2": {PwmValue: 0, ControlMode: fans.ControlModeAutomatic},
+		},
+	})
+	assert.NoError(t, err)
+
+	// WHEN
+	restored, err := RestoreOriginalFanState(p, group)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.True(t, restored)
+	assert.Equal(t, []int{100}, member1.pwmHistory)
+	assert.Equal(t, []fans.ControlMode{fans.ControlModeAutomatic}, member2.controlModeHistory)
+	assert.Empty(t, member2.pwmHistory)
+}
+
+func TestRestoreControlMode_ForgetsPersistedState(t *testing.T) {
+	// GIVEN
+	p := persistence.NewPersistence(t.TempDir() + "/fan2go.db")
+	fan := &mockFanForRestore{
+		MockFan:             MockFan{ID: "fan", PWM: 100, ControlMode: fans.ControlModeAutomatic},
+		supportsControlMode: true,
+	}
+	controller := DefaultFanController{
+		fan:         fan,
+		persistence: p,
+	}
+	err := controller.storeInitialFanState()
+	assert.NoError(t, err)
+	controller.persistOriginalFanState()
+	state, err := p.LoadFanOriginalState("fan")
+	assert.NoError(t, err)
+	assert.Equal(t, FanStateSnapshot{PwmValue: 100, ControlMode: fans.ControlModeAutomatic}, *state)
+
+	// WHEN
+	controller.restoreControlMode()
+
+	// THEN
+	_, err = p.LoadFanOriginalState("fan")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestHandleResume_ReappliesManualControlMode(t *testing.T) {
+	// GIVEN
+	fan := &mockFanForRestore{
+		MockFan:             MockFan{ID: "fan", PWM: 0, ControlMode: fans.ControlModeAutomatic},
+		supportsControlMode: true,
+	}
+	controller := DefaultFanController{
+		fan: fan,
+	}
+
+	// WHEN
+	controller.handleResume(time.Hour)
+
+	// THEN
+	assert.Equal(t, []fans.ControlMode{fans.ControlModePWM}, fan.controlModeHistory)
+	assert.True(t, controller.isInResumeGracePeriod())
+}
+
+func TestEnsureNoThirdPartyIsMessingWithUs_SuppressedAfterResume(t *testing.T) {
+	// GIVEN
+	fan := &mockFanForRestore{
+		MockFan:             MockFan{ID: "fan", PWM: 0, ControlMode: fans.ControlModePWM},
+		supportsControlMode: true,
+	}
+	lastTarget := 100
+	controller := DefaultFanController{
+		fan:        fan,
+		lastTarget: &lastTarget,
+		pwmMapping: createOneToOnePwmMap(),
+	}
+
+	// WHEN
+	controller.ensureNoThirdPartyIsMessingWithUs()
+	controller.handleResume(time.Hour)
+	controller.ensureNoThirdPartyIsMessingWithUs()
+
+	// THEN
+	// only the check before the resume detected the reset PWM value
+	assert.Equal(t, 1, controller.GetStatistics().UnexpectedPwmValueCount)
+}
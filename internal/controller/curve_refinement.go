@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+const (
+	// a pwm value has to be applied for this long, before the RPM of the fan is considered steady
+	curveRefinementSettleTime = 10 * time.Second
+	// minimum time between two observations blended into the fan curve data
+	curveRefinementSampleInterval = 10 * time.Second
+	// the confidence in a single steady-state observation, relative to the stored fan curve data
+	curveRefinementWeight = 0.1
+)
+
+// isCurveRefinementEnabled returns true if the fan curve data of the fan is refined during normal operation.
+// Only fans with measured fan curve data (hwmon and nvidia) are refined.
+func (f *DefaultFanController) isCurveRefinementEnabled() bool {
+	config := f.fan.GetConfig()
+	return configuration.CurrentConfig.FanController.CurveRefinementInterval > 0 &&
+		(config.HwMon != nil || config.Nvidia != nil) &&
+		f.fan.Supports(fans.FeatureRpmSensor) &&
+		f.fan.GetFanRpmCurveData() != nil
+}
+
+// loadReferenceCurveData restores the initially measured fan curve data, which is used to detect drift.
+// If there is none, the given (not yet refined) curve data is saved as the reference.
+func (f *DefaultFanController) loadReferenceCurveData(curveData map[int]float64) {
+	if !f.isCurveRefinementEnabled() {
+		return
+	}
+	fanId := f.fan.GetId()
+
+	reference, err := f.persistence.LoadFanRpmDataReference(fanId)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			ui.Warning("Fan %s: unable to load fan curve reference data: %v", fanId, err)
+		}
+		reference = maps.Clone(curveData)
+		err = f.persistence.SaveFanRpmDataReference(fanId, reference)
+		if err != nil {
+			ui.Warning("Fan %s: unable to save fan curve reference data: %v", fanId, err)
+		}
+	}
+	f.referenceCurveData = reference
+	f.nextCurveDataSave = time.Now().Add(configuration.CurrentConfig.FanController.CurveRefinementInterval)
+}
+
+// refineCurveData blends the RPM of the fan into its fan curve data, once the fan runs at a steady
+// pwm value, and periodically saves the refined data
+func (f *DefaultFanController) refineCurveData(now time.Time) {
+	if !f.isCurveRefinementEnabled() {
+		return
+	}
+
+	f.observeSteadyState(now)
+
+	if f.curveDataChanged && !now.Before(f.nextCurveDataSave) {
+		f.saveRefinedCurveData()
+		f.nextCurveDataSave = now.Add(configuration.CurrentConfig.FanController.CurveRefinementInterval)
+	}
+}
+
+// observeSteadyState blends the current RPM of the fan into the stored value of the current pwm value,
+// if the pwm value didn't change for a while and nothing else is interfering with the fan speed
+func (f *DefaultFanController) observeSteadyState(now time.Time) {
+	if f.lastTarget == nil {
+		return
+	}
+	target := *f.lastTarget
+	if f.curveRefinementSince.IsZero() || target != f.curveRefinementTarget {
+		f.curveRefinementTarget = target
+		f.curveRefinementSince = now
+		return
+	}
+	if now.Sub(f.curveRefinementSince) < curveRefinementSettleTime || now.Sub(f.lastCurveRefinement) < curveRefinementSampleInterval {
+		return
+	}
+	if f.failsafeActive || f.isInResumeGracePeriod() || now.Before(f.spinUpUntil) || now.Before(f.minPwmOffsetProbeUntil) {
+		return
+	}
+
+	rpm := f.fan.GetRpmAvg()
+	stored, err := util.CalculateInterpolatedCurveValue(*f.fan.GetFanRpmCurveData(), util.InterpolationTypeLinear, float64(target))
+	if err != nil {
+		return
+	}
+	// only refine values the fan is known to spin at, to keep the start boundary of the fan curve data intact
+	if !fans.IsRpmLikelySpinning(stored) || !fans.IsRpmLikelySpinning(rpm) {
+		return
+	}
+
+	refined := stored + curveRefinementWeight*(rpm-stored)
+	ui.Debug("Fan %s: refining fan curve data at PWM %d from %.0f to %.0f (observed %.0f)", f.fan.GetId(), target, stored, refined, rpm)
+	f.fan.UpdateFanRpmCurveValue(target, refined)
+	f.lastCurveRefinement = now
+	f.curveDataChanged = true
+
+	f.checkCurveDrift(target, refined)
+}
+
+// checkCurveDrift compares the refined RPM at the given pwm value with the initial measurement
+// and warns once, if the fan lost more RPM than the configured threshold
+func (f *DefaultFanController) checkCurveDrift(target int, refined float64) {
+	if f.referenceCurveData == nil {
+		return
+	}
+	reference, err := util.CalculateInterpolatedCurveValue(f.referenceCurveData, util.InterpolationTypeLinear, float64(target))
+	if err != nil || !fans.IsRpmLikelySpinning(reference) {
+		return
+	}
+
+	drift := (reference - refined) / reference
+	f.stats.CurveDrift = drift
+
+	threshold := configuration.CurrentConfig.FanController.CurveDriftThreshold
+	if threshold <= 0 || drift < threshold {
+		f.curveDriftWarned = false
+		return
+	}
+	if f.curveDriftWarned {
+		return
+	}
+	f.curveDriftWarned = true
+	ui.WarningAndNotify("Fan Drift Detected",
+		"Fan %s: RPM at PWM %d dropped by %.0f%% (from %.0f to %.0f) compared to the initial measurement, the fan might be failing (f.ex. a worn bearing)",
+		f.fan.GetId(), target, drift*100, reference, refined)
+}
+
+// saveRefinedCurveData saves the fan curve data, if it was refined since it was saved last
+func (f *DefaultFanController) saveRefinedCurveData() {
+	if !f.curveDataChanged {
+		return
+	}
+	err := f.persistence.SaveFanRpmData(f.fan)
+	if err != nil {
+		ui.Warning("Fan %s: unable to save refined fan curve data: %v", f.fan.GetId(), err)
+		return
+	}
+	f.curveDataChanged = false
+}
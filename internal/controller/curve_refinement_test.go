@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func createCurveRefinementTestFan(rpm int) *MockFan {
+	return &MockFan{
+		ID:         "fan",
+		RPM:        rpm,
+		HwMon:      &configuration.HwMonFanConfig{},
+		speedCurve: &map[int]float64{0: 0, 50: 0, 100: 1000, 255: 2000},
+	}
+}
+
+// runSteadily applies the given target until the given time, in steps of one second
+func runSteadily(controller *DefaultFanController, target int, from time.Time, until time.Time) {
+	controller.lastTarget = &target
+	for now := from; !now.After(until); now = now.Add(time.Second) {
+		controller.refineCurveData(now)
+	}
+}
+
+func TestLoadReferenceCurveData_SavesInitialCurveData(t *testing.T) {
+	// GIVEN
+	fan := createCurveRefinementTestFan(1000)
+	p := mockPersistence{
+		rpmData:           map[string]map[int]float64{},
+		rpmDataReferences: map[string]map[int]float64{},
+	}
+	controller := createTestController(t, fan, p, nil)
+	configuration.CurrentConfig.FanController.CurveRefinementInterval = time.Minute
+
+	// WHEN
+	controller.loadReferenceCurveData(*fan.GetFanRpmCurveData())
+
+	// THEN
+	assert.Equal(t, *fan.speedCurve, controller.referenceCurveData)
+	assert.Equal(t, *fan.speedCurve, p.rpmDataReferences["fan"])
+}
+
+func TestRefineCurveData_BlendsSteadyStateObservations(t *testing.T) {
+	// GIVEN
+	fan := createCurveRefinementTestFan(900)
+	p := mockPersistence{
+		rpmData:           map[string]map[int]float64{},
+		rpmDataReferences: map[string]map[int]float64{},
+	}
+	controller := createTestController(t, fan, p, nil)
+	configuration.CurrentConfig.FanController.CurveRefinementInterval = time.Minute
+	configuration.CurrentConfig.FanController.CurveDriftThreshold = 0.2
+	controller.loadReferenceCurveData(*fan.GetFanRpmCurveData())
+	now := time.Now()
+
+	// WHEN
+	runSteadily(controller, 100, now, now.Add(curveRefinementSettleTime-time.Second))
+
+	// THEN
+	// the fan didn't settle yet
+	assert.Equal(t, 1000.0, (*fan.speedCurve)[100])
+
+	// WHEN
+	runSteadily(controller, 100, now.Add(curveRefinementSettleTime), now.Add(curveRefinementSettleTime))
+
+	// THEN
+	assert.Equal(t, 990.0, (*fan.speedCurve)[100])
+	assert.InDelta(t, 0.01, controller.GetStatistics().CurveDrift, 0.0001)
+	assert.True(t, controller.curveDataChanged)
+	assert.Empty(t, p.rpmData)
+
+	// WHEN
+	runSteadily(controller, 100, now.Add(curveRefinementSettleTime), now.Add(time.Minute))
+
+	// THEN
+	assert.Less(t, (*fan.speedCurve)[100], 990.0)
+	assert.Equal(t, (*fan.speedCurve)[100], p.rpmData["fan"][100])
+	// the reference is not refined
+	assert.Equal(t, 1000.0, p.rpmDataReferences["fan"][100])
+}
+
+func TestRefineCurveData_SkipsPwmValuesTheFanDoesNotSpinAt(t *testing.T) {
+	// GIVEN
+	fan := createCurveRefinementTestFan(300)
+	p := mockPersistence{
+		rpmData:           map[string]map[int]float64{},
+		rpmDataReferences: map[string]map[int]float64{},
+	}
+	controller := createTestController(t, fan, p, nil)
+	configuration.CurrentConfig.FanController.CurveRefinementInterval = time.Minute
+	configuration.CurrentConfig.FanController.CurveDriftThreshold = 0.2
+	controller.loadReferenceCurveData(*fan.GetFanRpmCurveData())
+	now := time.Now()
+
+	// WHEN
+	runSteadily(controller, 50, now, now.Add(time.Minute))
+
+	// THEN
+	assert.Equal(t, 0.0, (*fan.speedCurve)[50])
+	assert.False(t, controller.curveDataChanged)
+}
+
+func TestRefineCurveData_WarnsAboutDrift(t *testing.T) {
+	// GIVEN
+	fan := createCurveRefinementTestFan(700)
+	p := mockPersistence{
+		rpmData:           map[string]map[int]float64{},
+		rpmDataReferences: map[string]map[int]float64{},
+	}
+	controller := createTestController(t, fan, p, nil)
+	configuration.CurrentConfig.FanController.CurveRefinementInterval = time.Minute
+	configuration.CurrentConfig.FanController.CurveDriftThreshold = 0.2
+	controller.loadReferenceCurveData(*fan.GetFanRpmCurveData())
+	now := time.Now()
+
+	// WHEN
+	runSteadily(controller, 100, now, now.Add(time.Minute))
+
+	// THEN
+	// refined values approach the observation slowly
+	assert.False(t, controller.curveDriftWarned)
+
+	// WHEN
+	runSteadily(controller, 100, now.Add(time.Minute), now.Add(10*time.Minute))
+
+	// THEN
+	assert.True(t, controller.curveDriftWarned)
+	assert.Greater(t, controller.GetStatistics().CurveDrift, 0.2)
+}
+
+func TestRefineCurveData_Disabled(t *testing.T) {
+	// GIVEN
+	fan := createCurveRefinementTestFan(900)
+	p := mockPersistence{
+		rpmData:           map[string]map[int]float64{},
+		rpmDataReferences: map[string]map[int]float64{},
+	}
+	controller := createTestController(t, fan, p, nil)
+	configuration.CurrentConfig.FanController.CurveRefinementInterval = 0
+	configuration.CurrentConfig.FanController.CurveDriftThreshold = 0.2
+	controller.loadReferenceCurveData(*fan.GetFanRpmCurveData())
+	now := time.Now()
+
+	// WHEN
+	runSteadily(controller, 100, now, now.Add(time.Minute))
+
+	// THEN
+	assert.Equal(t, 1000.0, (*fan.speedCurve)[100])
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+const (
+	// MinPwmOffsetReasonStall is recorded when the offset was increased, because the fan stalled
+	MinPwmOffsetReasonStall = "stall"
+	// MinPwmOffsetReasonReprobe is recorded when the offset was lowered successfully
+	MinPwmOffsetReasonReprobe = "reprobe"
+	// MinPwmOffsetReasonReprobeFailed is recorded when the fan stalled after lowering the offset
+	MinPwmOffsetReasonReprobeFailed = "reprobe failed"
+
+	// minPwmOffsetProbeDuration is the time the fan has to keep spinning after lowering the offset,
+	// which has to be longer than it takes the moving average of the RPM to react
+	minPwmOffsetProbeDuration = time.Minute
+)
+
+// loadMinPwmOffset restores the minPwm offset learned in previous runs from persistence
+func (f *DefaultFanController) loadMinPwmOffset() {
+	f.nextMinPwmOffsetProbe = time.Now().Add(configuration.CurrentConfig.FanController.MinPwmReprobeInterval)
+	state, err := f.persistence.LoadFanMinPwmOffset(f.fan.GetId())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			ui.Warning("Fan %s: unable to load minPwm offset: %v", f.fan.GetId(), err)
+		}
+		return
+	}
+	f.minPwmOffsetState = *state
+	f.minPwmOffset = state.Offset
+	f.stats.MinPwmOffset = state.Offset
+	if state.Offset > 0 {
+		ui.Info("Fan %s: Using learned minPwm offset %d", f.fan.GetId(), state.Offset)
+	}
+}
+
+// setMinPwmOffset changes the minPwm offset and persists it, including the reason of the change
+func (f *DefaultFanController) setMinPwmOffset(offset int, reason string) {
+	f.minPwmOffset = offset
+	f.stats.MinPwmOffset = offset
+	f.minPwmOffsetState.AddChange(time.Now(), offset, reason)
+	err := f.persistence.SaveFanMinPwmOffset(f.fan.GetId(), f.minPwmOffsetState)
+	if err != nil {
+		ui.Warning("Fan %s: unable to save minPwm offset: %v", f.fan.GetId(), err)
+	}
+}
+
+// updateMinPwmOffsetProbe periodically tries to lower the minPwm offset of a fan that should never stop,
+// since the reason for the increase may be gone, f.ex. after cleaning the fan. The offset is lowered one
+// step at a time, while the fan runs at its minimum speed. If the fan doesn't keep spinning, the previous
+// offset is restored immediately and the fan is kicked to start it again.
+func (f *DefaultFanController) updateMinPwmOffsetProbe(now time.Time) {
+	fan := f.fan
+	interval := configuration.CurrentConfig.FanController.MinPwmReprobeInterval
+	if interval <= 0 || !fan.ShouldNeverStop() || !fan.Supports(fans.FeatureRpmSensor) {
+		return
+	}
+
+	if !f.minPwmOffsetProbeUntil.IsZero() {
+		if !fans.IsRpmLikelySpinning(fan.GetRpmAvg()) {
+			ui.Warning("Fan %s: stalled at minPwm offset %d, restoring offset %d", fan.GetId(), f.minPwmOffset, f.minPwmOffset+1)
+			f.setMinPwmOffset(f.minPwmOffset+1, MinPwmOffsetReasonReprobeFailed)
+			f.startSpinUp(now)
+			f.minPwmOffsetProbeUntil = time.Time{}
+			f.nextMinPwmOffsetProbe = now.Add(interval)
+		} else if f.lastTarget == nil || *f.lastTarget != fan.GetMinPwm()+f.minPwmOffset {
+			// the curve raised the speed, so the lower offset isn't tested at the minimum speed anymore.
+			// The probe starts over, once the fan runs at its minimum speed again.
+			ui.Debug("Fan %s: aborting probe of minPwm offset %d, fan is not running at its minimum speed", fan.GetId(), f.minPwmOffset)
+			f.minPwmOffset++
+			f.stats.MinPwmOffset = f.minPwmOffset
+			f.minPwmOffsetProbeUntil = time.Time{}
+		} else if !now.Before(f.minPwmOffsetProbeUntil) {
+			ui.Info("Fan %s: lowered minPwm offset to %d", fan.GetId(), f.minPwmOffset)
+			f.setMinPwmOffset(f.minPwmOffset, MinPwmOffsetReasonReprobe)
+			f.minPwmOffsetProbeUntil = time.Time{}
+			// keep lowering the offset until the fan stalls
+			f.nextMinPwmOffsetProbe = now
+		}
+		return
+	}
+
+	if f.minPwmOffset <= 0 || now.Before(f.nextMinPwmOffsetProbe) || f.isInResumeGracePeriod() || f.failsafeActive {
+		return
+	}
+	// only probe while the fan runs at its minimum speed, otherwise the lower offset has no effect
+	if f.lastTarget == nil || *f.lastTarget != fan.GetMinPwm()+f.minPwmOffset || !fans.IsRpmLikelySpinning(fan.GetRpmAvg()) {
+		return
+	}
+
+	ui.Info("Fan %s: probing minPwm offset %d", fan.GetId(), f.minPwmOffset-1)
+	f.minPwmOffset--
+	f.stats.MinPwmOffset = f.minPwmOffset
+	f.minPwmOffsetProbeUntil = now.Add(minPwmOffsetProbeDuration)
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/stretchr/testify/assert"
+)
+
+// createMinPwmOffsetState creates a saved minPwm offset, which was raised because the fan stalled
+func createMinPwmOffsetState(offset int) persistence.MinPwmOffsetState {
+	state := persistence.MinPwmOffsetState{}
+	state.AddChange(time.Now(), offset, MinPwmOffsetReasonStall)
+	return state
+}
+
+func TestLoadMinPwmOffset(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{ID: "fan", MinPWM: 20, shouldNeverStop: true}
+
+	p := mockPersistence{minPwmOffsets: map[string]persistence.MinPwmOffsetState{"fan": createMinPwmOffsetState(3)}}
+	controller := createTestController(t, fan, p, nil)
+
+	// WHEN
+	controller.loadMinPwmOffset()
+
+	// THEN
+	assert.Equal(t, 3, controller.minPwmOffset)
+	assert.Equal(t, 3, controller.GetStatistics().MinPwmOffset)
+	assert.Equal(t, 23, controller.computeSpeedTarget(0))
+}
+
+func TestIncreaseMinPwmOffset_IsPersisted(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{ID: "fan", MinPWM: 20, shouldNeverStop: true}
+	p := mockPersistence{minPwmOffsets: map[string]persistence.MinPwmOffsetState{}}
+	controller := createTestController(t, fan, p, nil)
+	controller.loadMinPwmOffset()
+
+	// WHEN
+	controller.increaseMinPwmOffset()
+
+	// THEN
+	state := p.minPwmOffsets["fan"]
+	assert.Equal(t, 1, state.Offset)
+	assert.Len(t, state.History, 1)
+	assert.Equal(t, MinPwmOffsetReasonStall, state.History[0].Reason)
+}
+
+func TestUpdateMinPwmOffsetProbe_Success(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{ID: "fan", MinPWM: 20, RPM: 400, shouldNeverStop: true}
+	p := mockPersistence{minPwmOffsets: map[string]persistence.MinPwmOffsetState{"fan": createMinPwmOffsetState(3)}}
+	controller := createTestController(t, fan, p, nil)
+	configuration.CurrentConfig.FanController.MinPwmReprobeInterval = time.Hour
+	controller.loadMinPwmOffset()
+	lastTarget := 23
+	controller.lastTarget = &lastTarget
+	now := time.Now()
+
+	// WHEN
+	controller.updateMinPwmOffsetProbe(now)
+
+	// THEN
+	// the interval didn't pass yet
+	assert.Equal(t, 3, controller.minPwmOffset)
+
+	// WHEN
+	now = now.Add(time.Hour)
+	controller.updateMinPwmOffsetProbe(now)
+
+	// THEN
+	assert.Equal(t, 2, controller.minPwmOffset)
+	assert.Equal(t, 3, p.minPwmOffsets["fan"].Offset)
+
+	// WHEN
+	// the fan runs at the lowered minimum speed
+	lastTarget = 22
+	now = now.Add(minPwmOffsetProbeDuration)
+	controller.updateMinPwmOffsetProbe(now)
+
+	// THEN
+	state := p.minPwmOffsets["fan"]
+	assert.Equal(t, 2, state.Offset)
+	assert.Equal(t, MinPwmOffsetReasonReprobe, state.History[len(state.History)-1].Reason)
+	// the next lower offset is probed right away
+	controller.updateMinPwmOffsetProbe(now)
+	assert.Equal(t, 1, controller.minPwmOffset)
+}
+
+func TestUpdateMinPwmOffsetProbe_Stall(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{ID: "fan", MinPWM: 20, StartPWM: 40, RPM: 400, shouldNeverStop: true}
+	p := mockPersistence{minPwmOffsets: map[string]persistence.MinPwmOffsetState{"fan": createMinPwmOffsetState(3)}}
+	controller := createTestController(t, fan, p, nil)
+	configuration.CurrentConfig.FanController.MinPwmReprobeInterval = time.Hour
+	controller.loadMinPwmOffset()
+	lastTarget := 23
+	controller.lastTarget = &lastTarget
+	now := time.Now().Add(time.Hour)
+	controller.updateMinPwmOffsetProbe(now)
+
+	// WHEN
+	fan.RPM = 0
+	controller.updateMinPwmOffsetProbe(now.Add(10 * time.Second))
+
+	// THEN
+	state := p.minPwmOffsets["fan"]
+	assert.Equal(t, 3, controller.minPwmOffset)
+	assert.Equal(t, 3, state.Offset)
+	assert.Equal(t, MinPwmOffsetReasonReprobeFailed, state.History[len(state.History)-1].Reason)
+	// the fan is kicked to start it again
+	assert.Equal(t, 40, controller.applySpinUp(23))
+}
+
+func TestUpdateMinPwmOffsetProbe_AbortsIfSpeedIsRaised(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{ID: "fan", MinPWM: 20, RPM: 400, shouldNeverStop: true}
+	p := mockPersistence{minPwmOffsets: map[string]persistence.MinPwmOffsetState{"fan": createMinPwmOffsetState(3)}}
+	controller := createTestController(t, fan, p, nil)
+	configuration.CurrentConfig.FanController.MinPwmReprobeInterval = time.Hour
+	controller.loadMinPwmOffset()
+	lastTarget := 23
+	controller.lastTarget = &lastTarget
+	now := time.Now().Add(time.Hour)
+	controller.updateMinPwmOffsetProbe(now)
+	assert.Equal(t, 2, controller.minPwmOffset)
+
+	// WHEN
+	lastTarget = 22
+	controller.updateMinPwmOffsetProbe(now.Add(10 * time.Second))
+	lastTarget = 100
+	controller.updateMinPwmOffsetProbe(now.Add(20 * time.Second))
+	controller.updateMinPwmOffsetProbe(now.Add(minPwmOffsetProbeDuration))
+
+	// THEN
+	state := p.minPwmOffsets["fan"]
+	assert.Equal(t, 3, controller.minPwmOffset)
+	assert.Equal(t, 3, state.Offset)
+	assert.Len(t, state.History, 1)
+
+	// WHEN
+	// the probe starts over at the minimum speed
+	lastTarget = 23
+	controller.updateMinPwmOffsetProbe(now.Add(2 * minPwmOffsetProbeDuration))
+
+	// THEN
+	assert.Equal(t, 2, controller.minPwmOffset)
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+// SetZeroRpmSensor sets the sensor used to decide whether the fan is stopped in zero-RPM mode
+func (f *DefaultFanController) SetZeroRpmSensor(sensor sensors.Sensor) {
+	f.zeroRpmSensor = sensor
+}
+
+// isFanStopped returns true, if the fan is (likely) not rotating at the moment
+func (f *DefaultFanController) isFanStopped() bool {
+	if f.lastTarget != nil && *f.lastTarget <= 0 {
+		return true
+	}
+	return f.fan.Supports(fans.FeatureRpmSensor) && !fans.IsRpmLikelySpinning(f.fan.GetRpmAvg())
+}
+
+// getSpinUpPwm returns the PWM value (before applying the pwmMap) used to kick the fan
+func (f *DefaultFanController) getSpinUpPwm(config *configuration.SpinUpConfig) int {
+	if config != nil && config.Pwm == configuration.SpinUpPwmMax {
+		return f.fan.GetMaxPwm()
+	}
+	return f.fan.GetStartPwm()
+}
+
+// applySpinUp replaces the given speed target with the kick PWM of the fan, if the fan is started
+// from a standstill with a target below its startPwm, until the kick duration is over.
+// A kick started by startSpinUp is applied even if spinUp isn't configured.
+func (f *DefaultFanController) applySpinUp(speedTarget int) int {
+	if speedTarget <= 0 {
+		f.spinUpUntil = time.Time{}
+		return speedTarget
+	}
+
+	config := f.fan.GetConfig().SpinUp
+	kickPwm := f.getSpinUpPwm(config)
+	now := time.Now()
+	if now.Before(f.spinUpUntil) {
+		return max(speedTarget, kickPwm)
+	}
+	if config == nil || speedTarget >= f.fan.GetStartPwm() || !f.isFanStopped() {
+		return speedTarget
+	}
+
+	ui.Debug("Fan %s: Starting from a standstill, applying spin-up PWM %d for %v", f.fan.GetId(), kickPwm, config.GetDuration())
+	f.startSpinUp(now)
+	return max(speedTarget, kickPwm)
+}
+
+// startSpinUp kicks the fan for the configured (or default) spin-up duration
+func (f *DefaultFanController) startSpinUp(now time.Time) {
+	duration := configuration.DefaultSpinUpDuration
+	if config := f.fan.GetConfig().SpinUp; config != nil {
+		duration = config.GetDuration()
+	}
+	f.spinUpUntil = now.Add(duration)
+}
+
+// applyZeroRpm stops the fan while its zero-RPM sensor is at or below stopTemp, until it reaches startTemp again.
+// While the fan is running, its speed target doesn't drop below minPwm, so it is only stopped by this mode.
+func (f *DefaultFanController) applyZeroRpm(speedTarget int) int {
+	config := f.fan.GetConfig().ZeroRpm
+	if config == nil || f.zeroRpmSensor == nil {
+		return speedTarget
+	}
+
+	active := f.updateZeroRpmState(*config, time.Now())
+	if active {
+		return 0
+	}
+	return max(speedTarget, f.fan.GetMinPwm()+f.minPwmOffset)
+}
+
+// updateZeroRpmState switches the zero-RPM mode on or off based on the current value of its sensor,
+// respecting the minimum dwell time. Returns true, if the fan should be stopped.
+func (f *DefaultFanController) updateZeroRpmState(config configuration.ZeroRpmConfig, now time.Time) bool {
+	sensor := f.zeroRpmSensor
+	if sensor.IsFaulted() {
+		// keep the fan running if we don't know the temperature
+		if f.zeroRpmActive {
+			ui.Warning("Fan %s: zeroRpm sensor %s is faulted, starting fan", f.fan.GetId(), sensor.GetId())
+			f.zeroRpmActive = false
+			f.zeroRpmChangedAt = now
+		}
+		return false
+	}
+
+	if !f.zeroRpmChangedAt.IsZero() && now.Sub(f.zeroRpmChangedAt) < config.GetMinDwell() {
+		return f.zeroRpmActive
+	}
+
+	value := sensor.GetMovingAvg() / configuration.UnitScale(sensor.GetConfig().GetUnit())
+	if !f.zeroRpmActive && value <= config.StopTemp {
+		ui.Info("Fan %s: %s is at %.1f, stopping fan (zeroRpm)", f.fan.GetId(), sensor.GetId(), value)
+		f.zeroRpmActive = true
+		f.zeroRpmChangedAt = now
+	} else if f.zeroRpmActive && value >= config.StartTemp {
+		ui.Info("Fan %s: %s is at %.1f, starting fan (zeroRpm)", f.fan.GetId(), sensor.GetId(), value)
+		f.zeroRpmActive = false
+		f.zeroRpmChangedAt = now
+	}
+	return f.zeroRpmActive
+}
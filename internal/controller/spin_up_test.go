@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplySpinUp(t *testing.T) {
+	// GIVEN
+	curveValue := 0.0
+	fan := &MockFan{
+		ID:                     "fan",
+		MinPWM:                 20,
+		StartPWM:               60,
+		MaxPWM:                 255,
+		RPM:                    0,
+		useUnscaledCurveValues: true,
+		SpinUp: &configuration.SpinUpConfig{
+			Pwm:      configuration.SpinUpPwmMax,
+			Duration: 50 * time.Millisecond,
+		},
+	}
+	controller := createTestController(t, fan, mockPersistence{}, &curveValue)
+
+	// WHEN
+	tryUpdateFanSpeed(t, controller)
+
+	// THEN
+	assertPwm(t, 0, fan)
+
+	// WHEN
+	// the fan is started from a standstill with a target between minPwm and startPwm
+	curveValue = 30
+	tryUpdateFanSpeed(t, controller)
+
+	// THEN
+	assertPwm(t, 255, fan)
+
+	// WHEN
+	fan.RPM = 800
+	time.Sleep(60 * time.Millisecond)
+	tryUpdateFanSpeed(t, controller)
+
+	// THEN
+	// the fan settles to its target after the kick
+	assertPwm(t, 30, fan)
+}
+
+func TestApplySpinUp_RunningFanIsNotKicked(t *testing.T) {
+	// GIVEN
+	curveValue := 30.0
+	fan := &MockFan{
+		ID:                     "fan",
+		MinPWM:                 20,
+		StartPWM:               60,
+		MaxPWM:                 255,
+		RPM:                    800,
+		useUnscaledCurveValues: true,
+		SpinUp:                 &configuration.SpinUpConfig{},
+	}
+	controller := createTestController(t, fan, mockPersistence{}, &curveValue)
+	lastTarget := 40
+	controller.lastTarget = &lastTarget
+
+	// WHEN
+	tryUpdateFanSpeed(t, controller)
+
+	// THEN
+	assertPwm(t, 30, fan)
+}
+
+func TestApplyZeroRpm(t *testing.T) {
+	// GIVEN
+	curveValue := 0.0
+	minDwell := time.Duration(0)
+	fan := &MockFan{
+		ID:                     "fan",
+		MinPWM:                 20,
+		MaxPWM:                 255,
+		RPM:                    800,
+		useUnscaledCurveValues: true,
+		ZeroRpm: &configuration.ZeroRpmConfig{
+			Sensor:    "gpu",
+			StopTemp:  50,
+			StartTemp: 60,
+			MinDwell:  &minDwell,
+		},
+	}
+	sensor := &MockSensor{ID: "gpu", MovingAvg: 55000}
+	controller := createTestController(t, fan, mockPersistence{}, &curveValue)
+	controller.SetZeroRpmSensor(sensor)
+
+	// WHEN
+	tryUpdateFanSpeed(t, controller)
+
+	// THEN
+	// between the thresholds the fan keeps running, at least at minPwm
+	assertPwm(t, 20, fan)
+
+	// WHEN
+	sensor.MovingAvg = 50000
+	tryUpdateFanSpeed(t, controller)
+
+	// THEN
+	assertPwm(t, 0, fan)
+
+	// WHEN
+	curveValue = 40
+	sensor.MovingAvg = 59000
+	tryUpdateFanSpeed(t, controller)
+
+	// THEN
+	// the fan stays stopped until startTemp is reached
+	assertPwm(t, 0, fan)
+
+	// WHEN
+	sensor.MovingAvg = 60000
+	tryUpdateFanSpeed(t, controller)
+
+	// THEN
+	assertPwm(t, 40, fan)
+}
+
+func TestApplyZeroRpm_MinDwell(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{
+		ID: "fan",
+		ZeroRpm: &configuration.ZeroRpmConfig{
+			Sensor:    "gpu",
+			StopTemp:  50,
+			StartTemp: 60,
+		},
+	}
+	sensor := &MockSensor{ID: "gpu", MovingAvg: 45000}
+	controller := createTestController(t, fan, mockPersistence{}, nil)
+	controller.SetZeroRpmSensor(sensor)
+	config := *fan.ZeroRpm
+	now := time.Now()
+
+	// WHEN
+	stopped := controller.updateZeroRpmState(config, now)
+	sensor.MovingAvg = 70000
+	stillStopped := controller.updateZeroRpmState(config, now.Add(configuration.DefaultZeroRpmMinDwell/2))
+	started := controller.updateZeroRpmState(config, now.Add(configuration.DefaultZeroRpmMinDwell))
+
+	// THEN
+	assert.True(t, stopped)
+	assert.True(t, stillStopped)
+	assert.False(t, started)
+}
+
+func TestApplyZeroRpm_FaultedSensorStartsFan(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{
+		ID: "fan",
+		ZeroRpm: &configuration.ZeroRpmConfig{
+			Sensor:    "gpu",
+			StopTemp:  50,
+			StartTemp: 60,
+		},
+	}
+	sensor := &MockSensor{ID: "gpu", MovingAvg: 45000}
+	controller := createTestController(t, fan, mockPersistence{}, nil)
+	controller.SetZeroRpmSensor(sensor)
+	config := *fan.ZeroRpm
+	now := time.Now()
+
+	// WHEN
+	stopped := controller.updateZeroRpmState(config, now)
+	sensor.SetFault("stuck")
+	faulted := controller.updateZeroRpmState(config, now)
+
+	// THEN
+	assert.True(t, stopped)
+	assert.False(t, faulted)
+}
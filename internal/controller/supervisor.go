@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+var ErrControllerPanicked = errors.New("fan controller panicked")
+
+var (
+	// delay before the first restart of a failed controller, doubled with every consecutive failure
+	restartBackoffMin = 1 * time.Second
+	// upper limit of the delay between restarts of a failed controller,
+	// kept below the WatchdogSec of the systemd unit
+	restartBackoffMax = 20 * time.Second
+	// a controller that ran for at least this long before failing is restarted with restartBackoffMin again
+	restartBackoffReset = 5 * time.Minute
+)
+
+// RunSupervised runs the controller until the given context is cancelled.
+// If the controller panics or stops on its own, the fan is set to its failsafe PWM
+// and the controller is restarted with an exponential backoff.
+func (f *DefaultFanController) RunSupervised(ctx context.Context) error {
+	return f.supervise(ctx, f.Run)
+}
+
+func (f *DefaultFanController) supervise(ctx context.Context, run func(ctx context.Context) error) error {
+	backoff := restartBackoffMin
+	for {
+		startTime := time.Now()
+		err := f.runRecovered(ctx, run)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err == nil {
+			err = errors.New("controller stopped unexpectedly")
+		}
+		if !errors.Is(err, ErrControllerPanicked) {
+			// the controller restored the original fan settings when it stopped,
+			// keep the fan at its failsafe PWM until it is restarted instead
+			if modeErr := trySetManualPwm(f.fan); modeErr != nil {
+				ui.Warning("Fan %s: unable to set manual control mode: %v", f.fan.GetId(), modeErr)
+			}
+			f.applySafePwm()
+		}
+		if time.Since(startTime) >= restartBackoffReset {
+			backoff = restartBackoffMin
+		}
+		ui.WarningAndNotify(fmt.Sprintf("Fan Controller: %s", f.fan.GetId()), "Restarting in %s: %v", backoff, err)
+
+		// cleared once the restarted controller completed a cycle
+		f.restarting.Store(true)
+		select {
+		case <-ctx.Done():
+			// the controller didn't get the chance to restore the fan settings itself
+			f.restoreControlMode()
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, restartBackoffMax)
+		f.stats.RestartCount += 1
+	}
+}
+
+// runRecovered runs the given function, converting a panic into an ErrControllerPanicked error
+func (f *DefaultFanController) runRecovered(ctx context.Context, run func(ctx context.Context) error) (err error) {
+	defer f.recoverPanic(&err)
+	return run(ctx)
+}
+
+// recoverPanic converts a panic of the calling goroutine into an ErrControllerPanicked error
+// and sets the fan to its failsafe PWM. Has to be deferred by every goroutine of the controller.
+func (f *DefaultFanController) recoverPanic(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	ui.Error("Fan %s: controller panicked: %v\n%s", f.fan.GetId(), r, debug.Stack())
+	f.applySafePwm()
+	*err = fmt.Errorf("%w: %v", ErrControllerPanicked, r)
+}
+
+// applySafePwm sets the fan to its failsafe PWM after a panic or an unexpected stop of the controller.
+// Since the state of the controller can't be trusted anymore, the sanity checks of setPwm are skipped.
+func (f *DefaultFanController) applySafePwm() {
+	defer func() {
+		if r := recover(); r != nil {
+			ui.Error("Fan %s: unable to set failsafe PWM: %v", f.fan.GetId(), r)
+		}
+	}()
+
+	pwm := f.getFailsafePwm()
+	// the pwm map is empty if the controller panicked before computing it
+	if f.pwmMapping[fans.MaxPwmValue] > 0 {
+		pwm = f.applyPwmMapToTarget(pwm)
+	}
+	err := f.fan.SetPwm(pwm)
+	if err != nil {
+		ui.Error("Fan %s: unable to set failsafe PWM: %v", f.fan.GetId(), err)
+	}
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockRestartBackoff(t *testing.T, backoff time.Duration) {
+	originalMin, originalMax := restartBackoffMin, restartBackoffMax
+	restartBackoffMin, restartBackoffMax = backoff, backoff
+	t.Cleanup(func() {
+		restartBackoffMin, restartBackoffMax = originalMin, originalMax
+	})
+}
+
+func TestSupervise_RestartsAfterPanic(t *testing.T) {
+	// GIVEN
+	mockRestartBackoff(t, time.Millisecond)
+	fan := &mockFanForRestore{
+		MockFan: MockFan{ID: "fan", PWM: 50},
+	}
+	controller := DefaultFanController{
+		fan: fan,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	run := func(ctx context.Context) error {
+		runs++
+		if runs == 1 {
+			panic("curve exploded")
+		}
+		cancel()
+		return nil
+	}
+
+	// WHEN
+	err := controller.supervise(ctx, run)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 2, runs)
+	assert.Equal(t, 1, controller.GetStatistics().RestartCount)
+	// the fan is set to its failsafe PWM after the panic
+	assert.Equal(t, []int{fans.MaxPwmValue}, fan.pwmHistory)
+}
+
+func TestSupervise_RestoresFanWhenStoppedDuringBackoff(t *testing.T) {
+	// GIVEN
+	mockRestartBackoff(t, time.Minute)
+	fan := &mockFanForRestore{
+		MockFan:             MockFan{ID: "fan", PWM: 50},
+		supportsControlMode: true,
+	}
+	controller := DefaultFanController{
+		fan: fan,
+		originalFanState: &FanStateSnapshot{
+			PwmValue:    100,
+			ControlMode: fans.ControlModePWM,
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	run := func(ctx context.Context) error {
+		time.AfterFunc(10*time.Millisecond, cancel)
+		return errors.New("no pwm map found")
+	}
+
+	// WHEN
+	err := controller.supervise(ctx, run)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 0, controller.GetStatistics().RestartCount)
+	// the fan is held at its failsafe PWM during the backoff, then restored
+	assert.Equal(t, []fans.ControlMode{fans.ControlModePWM, fans.ControlModePWM}, fan.controlModeHistory)
+	assert.Equal(t, []int{fans.MaxPwmValue, 100}, fan.pwmHistory)
+}
+
+func TestSupervise_AppliesFailsafePwmAfterUnexpectedStop(t *testing.T) {
+	// GIVEN
+	mockRestartBackoff(t, time.Millisecond)
+	fan := &mockFanForRestore{
+		MockFan:             MockFan{ID: "fan", PWM: 50},
+		supportsControlMode: true,
+	}
+	controller := DefaultFanController{
+		fan: fan,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	run := func(ctx context.Context) error {
+		runs++
+		if runs == 1 {
+			// the controller restores the original fan settings before it stops
+			fan.PWM = 50
+			fan.ControlMode = fans.ControlModeAutomatic
+			return nil
+		}
+		cancel()
+		return nil
+	}
+
+	// WHEN
+	err := controller.supervise(ctx, run)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 2, runs)
+	assert.Equal(t, []fans.ControlMode{fans.ControlModePWM}, fan.controlModeHistory)
+	assert.Equal(t, []int{fans.MaxPwmValue}, fan.pwmHistory)
+}
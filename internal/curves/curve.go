@@ -63,3 +63,10 @@ func NewSpeedCurve(config configuration.CurveConfig) (SpeedCurve, error) {
 
 	return nil, fmt.Errorf("no matching curve type for curve: %s", config.ID)
 }
+
+// sensorUnit returns the unit of the given sensor, as well as the factor between the values
+// reported by the sensor and the values used in curve definitions (f.ex. 1000 for milli-degrees)
+func sensorUnit(sensor sensors.Sensor) (unit string, scale float64) {
+	unit = sensor.GetConfig().GetUnit()
+	return unit, configuration.UnitScale(unit)
+}
@@ -5,9 +5,11 @@ import (
 )
 
 type MockSensor struct {
-	ID        string
-	Name      string
-	MovingAvg float64
+	ID          string
+	Name        string
+	MovingAvg   float64
+	Unit        string
+	FaultReason string
 }
 
 func (sensor MockSensor) GetId() string {
@@ -19,7 +21,10 @@ func (sensor MockSensor) GetLabel() string {
 }
 
 func (sensor MockSensor) GetConfig() configuration.SensorConfig {
-	panic("not implemented")
+	return configuration.SensorConfig{
+		ID:   sensor.ID,
+		Unit: sensor.Unit,
+	}
 }
 
 func (sensor MockSensor) GetValue() (result float64, err error) {
@@ -33,3 +38,15 @@ func (sensor MockSensor) GetMovingAvg() (avg float64) {
 func (sensor *MockSensor) SetMovingAvg(avg float64) {
 	sensor.MovingAvg = avg
 }
+
+func (sensor MockSensor) IsFaulted() bool {
+	return len(sensor.FaultReason) > 0
+}
+
+func (sensor MockSensor) GetFaultReason() string {
+	return sensor.FaultReason
+}
+
+func (sensor *MockSensor) SetFault(reason string) {
+	sensor.FaultReason = reason
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/markusressel/fan2go/internal/ui"
 
 	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/util"
 )
 
@@ -22,6 +23,14 @@ func (c *LinearSpeedCurve) BindRegistry(registry RegistryReader) {
 	c.registry = registry
 }
 
+// GetSensor returns the sensor this curve is based on, if it is available
+func (c *LinearSpeedCurve) GetSensor() (sensors.Sensor, bool) {
+	if c.registry == nil {
+		return nil, false
+	}
+	return c.registry.GetSensor(c.Config.Linear.Sensor)
+}
+
 func (c *LinearSpeedCurve) GetId() string {
 	return c.Config.ID
 }
@@ -34,19 +43,23 @@ func (c *LinearSpeedCurve) Evaluate() (value float64, err error) {
 	if !exists || sensor == nil {
 		return c.Value, fmt.Errorf("sensor not found with id '%s'", c.Config.Linear.Sensor)
 	}
+	if sensor.IsFaulted() {
+		return c.Value, fmt.Errorf("sensor '%s': %w", c.Config.Linear.Sensor, sensors.ErrSensorFaulted)
+	}
 	var avgTemp = sensor.GetMovingAvg()
+	unit, scale := sensorUnit(sensor)
 
 	steps := c.Config.Linear.Steps
 	if steps != nil {
-		interpolatedCurveValue, err := util.CalculateInterpolatedCurveValue(steps, util.InterpolationTypeLinear, avgTemp/1000)
+		interpolatedCurveValue, err := util.CalculateInterpolatedCurveValue(steps, util.InterpolationTypeLinear, avgTemp/scale)
 		if err != nil {
 			ui.Error("Error calculating interpolated curve value for sensor '%s': %v", sensor.GetId(), err)
 			return 0, err
 		}
 		value = interpolatedCurveValue
 	} else {
-		minTemp := float64(c.Config.Linear.Min) * 1000 // degree to milli-degree
-		maxTemp := float64(c.Config.Linear.Max) * 1000
+		minTemp := float64(c.Config.Linear.Min) * scale // f.ex. degree to milli-degree
+		maxTemp := float64(c.Config.Linear.Max) * scale
 
 		if avgTemp >= maxTemp {
 			// full throttle if max temp is reached
@@ -60,7 +73,7 @@ func (c *LinearSpeedCurve) Evaluate() (value float64, err error) {
 		}
 	}
 
-	ui.Debug("Evaluating curve '%s'. Sensor '%s' temp '%.0f°'. Desired speed: %.2f", c.Config.ID, sensor.GetId(), avgTemp/1000, value)
+	ui.Debug("Evaluating curve '%s'. Sensor '%s' value '%.1f%s'. Desired speed: %.2f", c.Config.ID, sensor.GetId(), avgTemp/scale, configuration.UnitSymbol(unit), value)
 	c.SetValue(value)
 	return value, nil
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -71,6 +72,88 @@ func TestLinearCurveWithMinMax(t *testing.T) {
 	assert.Equal(t, 127.5, result)
 }
 
+func TestLinearCurveWithRpmSensor(t *testing.T) {
+	// GIVEN
+	s := &MockSensor{
+		Name:      "pump",
+		MovingAvg: 1500,
+		Unit:      configuration.SensorUnitRpm,
+	}
+	reg := NewMockRegistry()
+	reg.RegisterSensor(s)
+
+	curveConfig := createLinearCurveConfig(
+		"curve",
+		s.GetId(),
+		1000,
+		2000,
+	)
+	curve, _ := NewSpeedCurve(curveConfig)
+	reg.RegisterCurve(curve)
+
+	// WHEN
+	result, err := curve.Evaluate()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 127.5, result)
+}
+
+func TestLinearCurveWithPowerSensorSteps(t *testing.T) {
+	// GIVEN
+	s := &MockSensor{
+		Name:      "gpu_power",
+		MovingAvg: 150000,
+		Unit:      configuration.SensorUnitWatt,
+	}
+	reg := NewMockRegistry()
+	reg.RegisterSensor(s)
+
+	curveConfig := createLinearCurveConfigWithSteps(
+		"curve",
+		s.GetId(),
+		map[int]float64{
+			100: 0,
+			200: 200,
+		},
+	)
+	curve, _ := NewSpeedCurve(curveConfig)
+	reg.RegisterCurve(curve)
+
+	// WHEN
+	result, err := curve.Evaluate()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, result)
+}
+
+func TestLinearCurveWithFaultedSensor(t *testing.T) {
+	// GIVEN
+	s := &MockSensor{
+		Name:        "sensor",
+		MovingAvg:   60000.0,
+		FaultReason: "3 consecutive read errors",
+	}
+	reg := NewMockRegistry()
+	reg.RegisterSensor(s)
+
+	curveConfig := createLinearCurveConfig(
+		"curve",
+		s.GetId(),
+		40,
+		80,
+	)
+	curve, _ := NewSpeedCurve(curveConfig)
+	reg.RegisterCurve(curve)
+
+	// WHEN
+	_, err := curve.Evaluate()
+
+	// THEN
+	assert.ErrorIs(t, err, sensors.ErrSensorFaulted)
+}
+
 func TestLinearCurveWithStepsAtMin(t *testing.T) {
 	// GIVEN
 	avgTmp := 40000.0
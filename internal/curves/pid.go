@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/markusressel/fan2go/internal/util"
 )
@@ -35,18 +36,22 @@ func (c *PidSpeedCurve) Evaluate() (value float64, err error) {
 	if !exists || sensor == nil {
 		return c.Value, fmt.Errorf("sensor not found with id '%s'", c.Config.PID.Sensor)
 	}
+	if sensor.IsFaulted() {
+		return c.Value, fmt.Errorf("sensor '%s': %w", c.Config.PID.Sensor, sensors.ErrSensorFaulted)
+	}
 	var measured float64
-	measured, err = sensor.GetValue()
+	measured, err = sensors.ReadValue(sensor)
 	if err != nil {
 		ui.Warning("Curve %s: Error getting sensor value: %v", c.Config.ID, err)
 		return c.Value, err
 	}
 	pidTarget := c.Config.PID.SetPoint
+	unit, scale := sensorUnit(sensor)
 
-	loopValue := c.pidLoop.Loop(pidTarget, measured/1000.0)
+	loopValue := c.pidLoop.Loop(pidTarget, measured/scale)
 	curveValue := loopValue
 
-	ui.Debug("Evaluating curve '%s'. Sensor '%s' temp '%.0f°'. Desired speed: %.2f", c.Config.ID, sensor.GetId(), measured/1000, curveValue)
+	ui.Debug("Evaluating curve '%s'. Sensor '%s' value '%.1f%s'. Desired speed: %.2f", c.Config.ID, sensor.GetId(), measured/scale, configuration.UnitSymbol(unit), curveValue)
 	c.SetValue(curveValue)
 	return curveValue, nil
 }
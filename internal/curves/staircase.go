@@ -8,6 +8,7 @@ import (
 	"github.com/markusressel/fan2go/internal/ui"
 
 	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/sensors"
 )
 
 type StaircaseSpeedCurve struct {
@@ -36,24 +37,28 @@ func (c *StaircaseSpeedCurve) Evaluate() (value float64, err error) {
 	if !exists || sensor == nil {
 		return c.Value, fmt.Errorf("sensor not found with id '%s'", c.Config.Staircase.Sensor)
 	}
+	if sensor.IsFaulted() {
+		return c.Value, fmt.Errorf("sensor '%s': %w", c.Config.Staircase.Sensor, sensors.ErrSensorFaulted)
+	}
 
 	measured := sensor.GetMovingAvg()
+	unit, scale := sensorUnit(sensor)
 	steps := c.Config.Staircase.Steps
 
 	targetTemp := math.MinInt
 	for temp := range steps {
-		if measured >= float64(temp)*1000 {
+		if measured >= float64(temp)*scale {
 			targetTemp = max(targetTemp, temp)
 		}
 	}
-	if targetTemp < c.LastTemp && (c.LastTemp-int(measured/1000)) < c.Config.Staircase.Hysteresis.Down {
+	if targetTemp < c.LastTemp && (c.LastTemp-int(measured/scale)) < c.Config.Staircase.Hysteresis.Down {
 		targetTemp = c.LastTemp
 	}
 
 	c.LastTemp = targetTemp
 	value = steps[targetTemp]
 
-	ui.Debug("Evaluating curve '%s'. Sensor '%s' temp '%.0f°'. Desired speed: %.2f", c.Config.ID, sensor.GetId(), measured/1000, value)
+	ui.Debug("Evaluating curve '%s'. Sensor '%s' value '%.1f%s'. Desired speed: %.2f", c.Config.ID, sensor.GetId(), measured/scale, configuration.UnitSymbol(unit), value)
 	c.SetValue(value)
 	return value, nil
 }
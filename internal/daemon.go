@@ -16,6 +16,7 @@ import (
 	"github.com/markusressel/fan2go/internal/fans"
 	"github.com/markusressel/fan2go/internal/persistence"
 	"github.com/markusressel/fan2go/internal/registry"
+	"github.com/markusressel/fan2go/internal/systemd"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/markusressel/fan2go/internal/util"
 	"github.com/oklog/run"
@@ -26,7 +27,7 @@ func RunDaemon() {
 
 	pers := persistence.NewPersistence(configuration.CurrentConfig.DbPath)
 
-	fanMap, reg, err := InitializeObjects()
+	fanMap, reg, err := InitializeObjects(pers)
 	if err != nil {
 		ui.Fatal("Error initializing objects: %v", err)
 	}
@@ -198,6 +199,7 @@ func runOrchestratorCycle(ctx context.Context, reloadChan <-chan struct{}, reg *
 	startSensorMonitors(orchestratorCtx, reg, &orchestratorWg)
 	startFanControllers(orchestratorCtx, fanControllers, &orchestratorWg)
 	startWebservers(orchestratorCtx, reg, &orchestratorWg)
+	startSystemdNotifier(orchestratorCtx, fanControllers, &orchestratorWg)
 
 	select {
 	case <-ctx.Done():
@@ -223,7 +225,7 @@ func reloadConfiguration(pers persistence.Persistence) (*registry.Registry, map[
 	oldConfig := configuration.CurrentConfig
 	configuration.CurrentConfig = newConfig
 
-	fanMap, newReg, err := InitializeObjects()
+	fanMap, newReg, err := InitializeObjects(pers)
 	if err != nil {
 		configuration.CurrentConfig = oldConfig
 		return nil, nil, fmt.Errorf("error re-initializing objects: %w", err)
@@ -256,6 +258,7 @@ func runSignalHandler(ctx context.Context, reloadChan chan<- struct{}) error {
 				}
 			} else {
 				ui.Info("Received SIGTERM/SIGINT signal, exiting...")
+				_, _ = systemd.Notify(systemd.StateStopping)
 				return nil
 			}
 		case <-ctx.Done():
@@ -270,6 +273,9 @@ func startSensorMonitors(ctx context.Context, reg *registry.Registry, wg *sync.W
 	for _, sensor := range sensorMapData {
 		s := sensor
 		pollingRate := configuration.CurrentConfig.TempSensorPollingRate
+		if s.GetConfig().PollingRate != nil {
+			pollingRate = *s.GetConfig().PollingRate
+		}
 		mon := NewSensorMonitor(s, pollingRate)
 
 		wg.Add(1)
@@ -292,7 +298,7 @@ func startFanControllers(ctx context.Context, fanControllers map[fans.Fan]contro
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			err := fanController.Run(ctx)
+			err := fanController.RunSupervised(ctx)
 			ui.Info("Fan controller for fan %s stopped.", fan.GetId())
 			if err != nil && !errors.Is(err, context.Canceled) {
 				ui.WarningAndNotify(fmt.Sprintf("Fan Controller: %s", fan.GetId()), "Something went wrong: %v", err)
@@ -16,7 +16,7 @@ import (
 	"github.com/markusressel/fan2go/internal/ui"
 )
 
-func InitializeObjects() (fanMap map[configuration.FanConfig]fans.Fan, reg *registry.Registry, err error) {
+func InitializeObjects(pers persistence.Persistence) (fanMap map[configuration.FanConfig]fans.Fan, reg *registry.Registry, err error) {
 	controllers := hwmon.GetChips()
 	reg = registry.NewRegistry()
 
@@ -31,7 +31,7 @@ func InitializeObjects() (fanMap map[configuration.FanConfig]fans.Fan, reg *regi
 	if err != nil {
 		return nil, nil, fmt.Errorf("error initializing curves: %v", err)
 	}
-	fanMap, err = initializeFans(controllers, reg, config.Fans)
+	fanMap, err = initializeFans(pers, controllers, reg, config.Fans)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error initializing fans: %v", err)
 	}
@@ -47,9 +47,17 @@ func initializeFanControllers(
 	result = map[fans.Fan]controller.FanController{}
 	for config, fan := range fanMap {
 		updateRate := configuration.CurrentConfig.FanController.AdjustmentTickRate
-		controlLoop := createControlLoop(config)
+		controlLoop := createControlLoop(config, fan)
 		curve, _ := reg.GetCurve(fan.GetCurveId())
 		fanController := controller.NewFanController(pers, fan, curve, controlLoop, updateRate, false)
+		if config.ZeroRpm != nil {
+			sensor, ok := reg.GetSensor(config.ZeroRpm.Sensor)
+			if ok {
+				fanController.SetZeroRpmSensor(sensor)
+			} else {
+				ui.Warning("Fan %s: zeroRpm sensor %s not found, zeroRpm mode is disabled", config.ID, config.ZeroRpm.Sensor)
+			}
+		}
 		result[fan] = fanController
 	}
 
@@ -63,7 +71,14 @@ func initializeFanControllers(
 	return result, nil
 }
 
-func createControlLoop(config configuration.FanConfig) control_loop.ControlLoop {
+func createControlLoop(config configuration.FanConfig, fan fans.Fan) control_loop.ControlLoop {
+	if controller.IsRpmControlTarget(fan) {
+		return createRpmControlLoop(config, fan)
+	}
+	if config.ControlTarget == configuration.ControlTargetRpm {
+		ui.Warning("Fan %s: controlTarget rpm requires an RPM sensor, falling back to pwm", config.ID)
+	}
+
 	// 1. Check deprecated config first
 	if config.ControlLoop != nil { //nolint:all
 		ui.Warning("Using deprecated control loop configuration for fan %s...", config.ID)
@@ -94,6 +109,30 @@ func createControlLoop(config configuration.FanConfig) control_loop.ControlLoop
 	return control_loop.NewPidControlLoop(control_loop.DefaultPidConfig.P, control_loop.DefaultPidConfig.I, control_loop.DefaultPidConfig.D)
 }
 
+// createRpmControlLoop creates a control loop approaching a target RPM, using the measured
+// curve data of the fan as feed-forward term
+func createRpmControlLoop(config configuration.FanConfig, fan fans.Fan) control_loop.ControlLoop {
+	gains := control_loop.DefaultRpmConfig
+	if config.ControlAlgorithm != nil && config.ControlAlgorithm.Pid != nil {
+		gains = control_loop.PidControlLoopDefaults{
+			P: config.ControlAlgorithm.Pid.P,
+			I: config.ControlAlgorithm.Pid.I,
+			D: config.ControlAlgorithm.Pid.D,
+		}
+	}
+	return control_loop.NewRpmControlLoop(
+		gains.P, gains.I, gains.D,
+		fan.GetRpmAvg,
+		func() map[int]float64 {
+			curveData := fan.GetFanRpmCurveData()
+			if curveData == nil {
+				return nil
+			}
+			return *curveData
+		},
+	)
+}
+
 func initializeSensors(
 	controllers []*hwmon.HwMonController,
 	reg *registry.Registry,
@@ -110,6 +149,15 @@ func initializeSensors(
 				continue
 			}
 		}
+		if config.Aggregate != nil && config.Aggregate.Source == configuration.AggregateSourceHwMon {
+			err := hwmon.UpdateAggregateSensorConfigFromHwMonControllers(controllers, &config)
+			if err != nil {
+				errMsg := fmt.Sprintf("couldn't find sensors for %s: %v. Skipping.", config.ID, err)
+				ui.Warning("%s", errMsg)
+				ui.NotifyError("Sensor Skipped", errMsg)
+				continue
+			}
+		}
 
 		sensor, err := sensors.NewSensor(config)
 		if err != nil {
@@ -119,14 +167,21 @@ func initializeSensors(
 			continue
 		}
 		sensorList = append(sensorList, sensor)
+		reg.RegisterSensor(sensor)
+	}
 
-		currentValue, err := sensor.GetValue()
-		if err != nil {
-			ui.Warning("Error reading sensor %s: %v", config.ID, err)
+	// read initial values only after all sensors are registered, since expression and derivative sensors
+	// derive their value from other sensors (which therefore need to be read first)
+	var derivedSensors []sensors.Sensor
+	for _, sensor := range sensorList {
+		if sensor.GetConfig().Expression != nil || sensor.GetConfig().Derivative != nil {
+			derivedSensors = append(derivedSensors, sensor)
+			continue
 		}
-		sensor.SetMovingAvg(currentValue)
-
-		reg.RegisterSensor(sensor)
+		initializeSensorValue(sensor)
+	}
+	for _, sensor := range derivedSensors {
+		initializeSensorValue(sensor)
 	}
 
 	sensorCollector := statistics.NewSensorCollector(sensorList)
@@ -135,6 +190,14 @@ func initializeSensors(
 	return nil
 }
 
+func initializeSensorValue(sensor sensors.Sensor) {
+	currentValue, err := sensors.SampleAndReadValue(sensor)
+	if err != nil {
+		ui.Warning("Error reading sensor %s: %v", sensor.GetId(), err)
+	}
+	sensor.SetMovingAvg(currentValue)
+}
+
 func initializeCurves(reg *registry.Registry, configs []configuration.CurveConfig) error {
 	var curveList []curves.SpeedCurve
 	for _, config := range configs {
@@ -153,6 +216,7 @@ func initializeCurves(reg *registry.Registry, configs []configuration.CurveConfi
 }
 
 func initializeFans(
+	pers persistence.Persistence,
 	controllers []*hwmon.HwMonController,
 	reg *registry.Registry,
 	configs []configuration.FanConfig,
@@ -161,7 +225,22 @@ func initializeFans(
 
 	var fanList []fans.Fan
 
+	// members of fan groups are only controlled by their group, so they don't get a controller of their own
+	groupMemberIds := map[string]bool{}
+	for _, config := range configs {
+		if config.Group != nil {
+			for _, member := range config.Group.Members {
+				groupMemberIds[member.Fan] = true
+			}
+		}
+	}
+	memberFans := map[string]fans.Fan{}
+	memberPwmMaps := map[string][]int{}
+
 	for _, config := range configs {
+		if config.Group != nil {
+			continue
+		}
 		if config.HwMon != nil {
 			err := hwmon.UpdateFanConfigFromHwMonControllers(controllers, &config)
 			if err != nil {
@@ -179,6 +258,28 @@ func initializeFans(
 			ui.NotifyError("Fan Skipped", errMsg)
 			continue
 		}
+		fanList = append(fanList, fan)
+
+		if groupMemberIds[config.ID] {
+			memberFans[config.ID] = fan
+			memberPwmMaps[config.ID] = LoadGroupMemberData(pers, fan)
+			continue
+		}
+		reg.RegisterFan(fan)
+		result[config] = fan
+	}
+
+	for _, config := range configs {
+		if config.Group == nil {
+			continue
+		}
+		fan, err := createGroupFan(config, memberFans, memberPwmMaps)
+		if err != nil {
+			errMsg := fmt.Sprintf("unable to process fan configuration of '%s': %v. Skipping.", config.ID, err)
+			ui.Warning("%s", errMsg)
+			ui.NotifyError("Fan Skipped", errMsg)
+			continue
+		}
 		reg.RegisterFan(fan)
 		result[config] = fan
 
@@ -190,3 +291,45 @@ func initializeFans(
 
 	return result, nil
 }
+
+// createGroupFan creates a fan group using the available member fans. Members that couldn't be
+// initialized are left out of the group.
+func createGroupFan(config configuration.FanConfig, memberFans map[string]fans.Fan, memberPwmMaps map[string][]int) (*fans.GroupFan, error) {
+	groupConfig := *config.Group
+	groupConfig.Members = nil
+	for _, member := range config.Group.Members {
+		if _, ok := memberFans[member.Fan]; !ok {
+			ui.Warning("Fan %s: group member %s is not available, leaving it out of the group", config.ID, member.Fan)
+			continue
+		}
+		groupConfig.Members = append(groupConfig.Members, member)
+	}
+	if len(groupConfig.Members) <= 0 {
+		return nil, fmt.Errorf("none of the group members are available")
+	}
+	config.Group = &groupConfig
+	return fans.NewGroupFan(config, memberFans, memberPwmMaps)
+}
+
+// LoadGroupMemberData loads the data measured by a previous analysis of a fan group member, since members are
+// not initialized by a controller of their own: the fan curve data (defining its minPwm and startPwm) is attached
+// to the fan, and the saved pwmMap is returned (nil if there is none).
+func LoadGroupMemberData(pers persistence.Persistence, fan fans.Fan) []int {
+	fanRpmData, err := pers.LoadFanRpmData(fan)
+	if err == nil {
+		err = fan.AttachFanRpmCurveData(&fanRpmData)
+		if err != nil {
+			ui.Warning("Fan %s: unable to attach fan curve data: %v", fan.GetId(), err)
+		}
+	}
+
+	pwmMap, err := pers.LoadFanPwmMap(fan.GetId())
+	if err == nil && len(pwmMap) == fans.MaxPwmValue+1 {
+		return pwmMap
+	}
+	config := fan.GetConfig()
+	if config.HwMon != nil && (config.PwmMap == nil || config.PwmMap.Autodetect != nil) {
+		ui.Warning("Fan %s: no pwmMap found for this group member, using identity. Configure its pwmMap or analyze it using 'fan2go fan --id %s init'", fan.GetId(), fan.GetId())
+	}
+	return nil
+}
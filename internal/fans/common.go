@@ -2,6 +2,8 @@ package fans
 
 import (
 	"fmt"
+	"maps"
+	"math"
 	"sort"
 
 	"github.com/markusressel/fan2go/internal/configuration"
@@ -116,6 +118,10 @@ func NewFan(config configuration.FanConfig) (Fan, error) {
 		}, nil
 	}
 
+	if config.Group != nil {
+		return nil, fmt.Errorf("fan %s: fan groups have to be created using NewGroupFan", config.ID)
+	}
+
 	return nil, fmt.Errorf("no matching fan type for fan: %s", config.ID)
 }
 
@@ -189,3 +195,33 @@ func ComputePwmBoundariesFromCurveData(pwmRpmMap map[int]float64, userStartPwm i
 
 	return startPwm, maxPwm
 }
+
+// ComputeRpmBoundariesFromCurveData calculates the RPM range of a fan from its curve data.
+//
+// minRpm: the lowest RPM of all data points where the fan is likely spinning.
+// maxRpm: the highest RPM of all data points.
+func ComputeRpmBoundariesFromCurveData(pwmRpmMap map[int]float64) (minRpm float64, maxRpm float64) {
+	minRpm = math.MaxFloat64
+	for _, rpm := range pwmRpmMap {
+		if rpm > maxRpm {
+			maxRpm = rpm
+		}
+		if IsRpmLikelySpinning(rpm) && rpm < minRpm {
+			minRpm = rpm
+		}
+	}
+	if minRpm > maxRpm {
+		minRpm = maxRpm
+	}
+	return minRpm, maxRpm
+}
+
+// copyCurveDataWithValue returns a copy of the given PWM -> RPM curve data with the given value set
+func copyCurveDataWithValue(curveData *map[int]float64, pwm int, rpm float64) *map[int]float64 {
+	result := map[int]float64{}
+	if curveData != nil {
+		maps.Copy(result, *curveData)
+	}
+	result[pwm] = rpm
+	return &result
+}
@@ -0,0 +1,312 @@
+package fans
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// GroupFan drives several member fans with the PWM value computed for the group,
+// scaled individually for each member
+type GroupFan struct {
+	Config  configuration.FanConfig `json:"config"`
+	Members []*GroupFanMember       `json:"members"`
+
+	Pwm          int     `json:"pwm"`
+	RpmMovingAvg float64 `json:"rpmMovingAvg"`
+
+	// groups don't have measured fan curve data, so the PWM value is used as RPM value instead
+	fanCurveData map[int]float64
+}
+
+type GroupFanMember struct {
+	Fan    Fan                                `json:"-"`
+	Id     string                             `json:"id"`
+	Config configuration.GroupFanMemberConfig `json:"config"`
+
+	// Pwm is the last (mapped) PWM value applied to this member
+	Pwm int `json:"pwm"`
+	// Rpm is the last RPM value read from this member
+	Rpm int `json:"rpm"`
+
+	pwmMap [MaxPwmValue + 1]int
+}
+
+// NewGroupFan creates a fan group from the given configuration, using the given member fans.
+// members must contain a fan for each member of the group, savedPwmMaps may contain the
+// persisted (autodetected) pwmMap of each member.
+func NewGroupFan(config configuration.FanConfig, members map[string]Fan, savedPwmMaps map[string][]int) (*GroupFan, error) {
+	if config.Group == nil {
+		return nil, fmt.Errorf("fan %s: not a group", config.ID)
+	}
+
+	fanCurveData, err := util.InterpolateLinearly(&map[int]float64{0: 0, 255: 255}, MinPwmValue, MaxPwmValue)
+	if err != nil {
+		return nil, fmt.Errorf("fan %s: %w", config.ID, err)
+	}
+	group := &GroupFan{
+		Config:       config,
+		fanCurveData: fanCurveData,
+	}
+	for _, memberConfig := range config.Group.Members {
+		fan, ok := members[memberConfig.Fan]
+		if !ok {
+			return nil, fmt.Errorf("fan %s: group member %s not found", config.ID, memberConfig.Fan)
+		}
+		pwmMap, err := computeMemberPwmMap(fan.GetConfig().PwmMap, savedPwmMaps[memberConfig.Fan])
+		if err != nil {
+			return nil, fmt.Errorf("fan %s: group member %s: %w", config.ID, memberConfig.Fan, err)
+		}
+		group.Members = append(group.Members, &GroupFanMember{
+			Fan:    fan,
+			Id:     memberConfig.Fan,
+			Config: memberConfig,
+			pwmMap: pwmMap,
+		})
+	}
+	return group, nil
+}
+
+// computeMemberPwmMap expands the pwmMap of a member fan. If none is configured (or it is autodetected),
+// the saved pwmMap of the member is used if available, and identity otherwise.
+func computeMemberPwmMap(cfg *configuration.PwmMapConfig, saved []int) (result [MaxPwmValue + 1]int, err error) {
+	for i := range result {
+		result[i] = i
+	}
+	if cfg == nil || cfg.Autodetect != nil {
+		if len(saved) == len(result) {
+			copy(result[:], saved)
+		}
+		return result, nil
+	}
+
+	var expanded map[int]int
+	if cfg.Linear != nil {
+		pts := map[int]int(*cfg.Linear)
+		expanded, err = util.InterpolateLinearlyInt(&pts, MinPwmValue, MaxPwmValue)
+		if err != nil {
+			return result, fmt.Errorf("error expanding pwmMap (linear): %w", err)
+		}
+	} else if cfg.Values != nil {
+		pts := map[int]int(*cfg.Values)
+		expanded, err = util.InterpolateStepInt(&pts, MinPwmValue, MaxPwmValue)
+		if err != nil {
+			return result, fmt.Errorf("error expanding pwmMap (values): %w", err)
+		}
+	}
+	for i := range expanded {
+		result[i] = expanded[i]
+	}
+	return result, nil
+}
+
+// computePwm scales the given group PWM value for this member and applies its pwmMap.
+// A group PWM of 0 always stops the member, regardless of its offset.
+func (member *GroupFanMember) computePwm(pwm int) int {
+	if pwm <= 0 {
+		return member.pwmMap[0]
+	}
+	scaled := int(math.Round(float64(pwm)*member.Config.GetMultiplier())) + member.Config.Offset
+	return member.pwmMap[util.Coerce(scaled, MinPwmValue, MaxPwmValue)]
+}
+
+// toGroupPwm returns the lowest PWM value of the group, that is scaled to at least the given PWM value
+// (before applying the pwmMap) for this member
+func (member *GroupFanMember) toGroupPwm(memberPwm int) int {
+	if memberPwm <= 0 {
+		return 0
+	}
+	pwm := int(math.Ceil(float64(memberPwm-member.Config.Offset) / member.Config.GetMultiplier()))
+	return util.Coerce(pwm, 1, MaxPwmValue)
+}
+
+func (fan *GroupFan) GetId() string {
+	return fan.Config.ID
+}
+
+func (fan *GroupFan) GetLabel() string {
+	return "Fan Group " + fan.Config.ID
+}
+
+func (fan *GroupFan) GetIndex() int {
+	return 1
+}
+
+// GetStartPwm returns the configured startPwm of the group, or the lowest PWM value of the group
+// that starts all members
+func (fan *GroupFan) GetStartPwm() int {
+	if fan.Config.StartPwm != nil {
+		return *fan.Config.StartPwm
+	}
+	startPwm := 1
+	for _, member := range fan.Members {
+		startPwm = max(startPwm, member.toGroupPwm(member.Fan.GetStartPwm()))
+	}
+	return startPwm
+}
+
+func (fan *GroupFan) SetStartPwm(pwm int, force bool) {
+	// not supported
+}
+
+// GetMinPwm returns the configured minPwm of the group, or the lowest PWM value of the group
+// that keeps all members running
+func (fan *GroupFan) GetMinPwm() int {
+	if fan.Config.MinPwm != nil {
+		return *fan.Config.MinPwm
+	}
+	minPwm := MinPwmValue
+	for _, member := range fan.Members {
+		minPwm = max(minPwm, member.toGroupPwm(member.Fan.GetMinPwm()))
+	}
+	return minPwm
+}
+
+func (fan *GroupFan) SetMinPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan *GroupFan) GetMaxPwm() int {
+	if fan.Config.MaxPwm != nil {
+		return *fan.Config.MaxPwm
+	}
+	return MaxPwmValue
+}
+
+func (fan *GroupFan) SetMaxPwm(pwm int, force bool) {
+	// not supported
+}
+
+// GetRpm returns the average RPM of all members with an RPM sensor
+func (fan *GroupFan) GetRpm() (int, error) {
+	var errs []error
+	sum := 0
+	count := 0
+	for _, member := range fan.Members {
+		if !member.Fan.Supports(FeatureRpmSensor) {
+			continue
+		}
+		rpm, err := member.Fan.GetRpm()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("group member %s: %w", member.Id, err))
+			continue
+		}
+		member.Rpm = rpm
+		sum += rpm
+		count++
+	}
+	if count <= 0 {
+		if len(errs) > 0 {
+			return 0, fmt.Errorf("fan %s: %w", fan.GetId(), errors.Join(errs...))
+		}
+		return 0, fmt.Errorf("fan %s: no group member supports rpm readings", fan.GetId())
+	}
+	return int(math.Round(float64(sum) / float64(count))), nil
+}
+
+func (fan *GroupFan) GetRpmAvg() float64 {
+	return fan.RpmMovingAvg
+}
+
+func (fan *GroupFan) SetRpmAvg(rpm float64) {
+	fan.RpmMovingAvg = rpm
+}
+
+// GetPwm returns the last PWM value set for the group, since members may run at different values
+func (fan *GroupFan) GetPwm() (int, error) {
+	return fan.Pwm, nil
+}
+
+// SetPwm applies the given PWM value to all members, scaled individually for each member.
+// All members are updated, even if setting the value of one of them fails.
+func (fan *GroupFan) SetPwm(pwm int) (err error) {
+	var errs []error
+	for _, member := range fan.Members {
+		memberPwm := member.computePwm(pwm)
+		err := member.Fan.SetPwm(memberPwm)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("group member %s: %w", member.Id, err))
+			continue
+		}
+		member.Pwm = memberPwm
+	}
+	fan.Pwm = pwm
+	if len(errs) > 0 {
+		return fmt.Errorf("fan %s: %w", fan.GetId(), errors.Join(errs...))
+	}
+	return nil
+}
+
+func (fan *GroupFan) GetFanRpmCurveData() *map[int]float64 {
+	return &fan.fanCurveData
+}
+
+func (fan *GroupFan) AttachFanRpmCurveData(curveData *map[int]float64) (err error) {
+	// not supported
+	return
+}
+
+func (fan *GroupFan) UpdateFanRpmCurveValue(pwm int, rpm float64) {
+	// not supported
+}
+
+func (fan *GroupFan) GetCurveId() string {
+	return fan.Config.Curve
+}
+
+func (fan *GroupFan) ShouldNeverStop() bool {
+	return fan.Config.NeverStop
+}
+
+// GetControlMode returns the ControlMode of the first member that supports reading it
+func (fan *GroupFan) GetControlMode() (ControlMode, error) {
+	for _, member := range fan.Members {
+		if member.Fan.Supports(FeatureControlModeRead) {
+			return member.Fan.GetControlMode()
+		}
+	}
+	return ControlModeUnknown, nil
+}
+
+// SetControlMode sets the ControlMode of all members that support it
+func (fan *GroupFan) SetControlMode(value ControlMode) (err error) {
+	var errs []error
+	for _, member := range fan.Members {
+		if !member.Fan.Supports(FeatureControlModeWrite) {
+			continue
+		}
+		err := member.Fan.SetControlMode(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("group member %s: %w", member.Id, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("fan %s: %w", fan.GetId(), errors.Join(errs...))
+	}
+	return nil
+}
+
+func (fan *GroupFan) GetConfig() configuration.FanConfig {
+	return fan.Config
+}
+
+func (fan *GroupFan) SetConfig(config configuration.FanConfig) {
+	fan.Config = config
+}
+
+func (fan *GroupFan) Supports(feature FeatureFlag) bool {
+	switch feature {
+	case FeaturePwmSensor:
+		return false
+	case FeatureRpmSensor, FeatureControlModeRead, FeatureControlModeWrite:
+		for _, member := range fan.Members {
+			if member.Fan.Supports(feature) {
+				return true
+			}
+		}
+	}
+	return false
+}
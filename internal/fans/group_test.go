@@ -0,0 +1,226 @@
+package fans
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createGroupMemberFan(t *testing.T, id string, rpm *int, pwmMap *configuration.PwmMapConfig) *FileFan {
+	dir := t.TempDir()
+	config := configuration.FanConfig{
+		ID:     id,
+		PwmMap: pwmMap,
+		File: &configuration.FileFanConfig{
+			Path: filepath.Join(dir, "pwm"),
+		},
+	}
+	require.NoError(t, os.WriteFile(config.File.Path, []byte("0"), 0644))
+	if rpm != nil {
+		config.File.RpmPath = filepath.Join(dir, "rpm")
+		require.NoError(t, util.WriteIntToFile(*rpm, config.File.RpmPath))
+	}
+	return &FileFan{Config: config}
+}
+
+func createGroupFan(t *testing.T, memberConfigs []configuration.GroupFanMemberConfig, members ...Fan) *GroupFan {
+	memberMap := map[string]Fan{}
+	for _, member := range members {
+		memberMap[member.GetId()] = member
+	}
+	group, err := NewGroupFan(configuration.FanConfig{
+		ID:    "group",
+		Group: &configuration.GroupFanConfig{Members: memberConfigs},
+	}, memberMap, nil)
+	require.NoError(t, err)
+	return group
+}
+
+func TestGroupFan_NewFan(t *testing.T) {
+	// GIVEN
+	config := configuration.FanConfig{
+		ID: "group",
+		Group: &configuration.GroupFanConfig{Members: []configuration.GroupFanMemberConfig{
+			{Fan: "front1"},
+		}},
+	}
+
+	// WHEN
+	_, err := NewFan(config)
+	_, errMissingMember := NewGroupFan(config, map[string]Fan{}, nil)
+
+	// THEN
+	assert.EqualError(t, err, "fan group: fan groups have to be created using NewGroupFan")
+	assert.EqualError(t, errMissingMember, "fan group: group member front1 not found")
+}
+
+func TestGroupFan_SetPwm(t *testing.T) {
+	// GIVEN
+	front1 := createGroupMemberFan(t, "front1", nil, nil)
+	front2 := createGroupMemberFan(t, "front2", nil, nil)
+	rear := createGroupMemberFan(t, "rear", nil, &configuration.PwmMapConfig{
+		Linear: &configuration.PwmMapLinearConfig{0: 0, 255: 100},
+	})
+	group := createGroupFan(t, []configuration.GroupFanMemberConfig{
+		{Fan: "front1"},
+		{Fan: "front2", Multiplier: 0.5, Offset: 10},
+		{Fan: "rear", Multiplier: 2},
+	}, front1, front2, rear)
+
+	tests := []struct {
+		pwm            int
+		expectedFront1 int
+		expectedFront2 int
+		expectedRear   int
+	}{
+		{pwm: 0, expectedFront1: 0, expectedFront2: 0, expectedRear: 0},
+		{pwm: 100, expectedFront1: 100, expectedFront2: 60, expectedRear: 78},
+		{pwm: 255, expectedFront1: 255, expectedFront2: 138, expectedRear: 100},
+	}
+
+	for _, tt := range tests {
+		// WHEN
+		err := group.SetPwm(tt.pwm)
+
+		// THEN
+		require.NoError(t, err)
+		front1Pwm, _ := front1.GetPwm()
+		front2Pwm, _ := front2.GetPwm()
+		rearPwm, _ := rear.GetPwm()
+		assert.Equal(t, tt.expectedFront1, front1Pwm)
+		assert.Equal(t, tt.expectedFront2, front2Pwm)
+		assert.Equal(t, tt.expectedRear, rearPwm)
+		groupPwm, _ := group.GetPwm()
+		assert.Equal(t, tt.pwm, groupPwm)
+	}
+}
+
+func TestGroupFan_GetRpm(t *testing.T) {
+	// GIVEN
+	front1Rpm := 1000
+	front2Rpm := 1201
+	front1 := createGroupMemberFan(t, "front1", &front1Rpm, nil)
+	front2 := createGroupMemberFan(t, "front2", &front2Rpm, nil)
+	noRpm := createGroupMemberFan(t, "noRpm", nil, nil)
+	group := createGroupFan(t, []configuration.GroupFanMemberConfig{
+		{Fan: "front1"},
+		{Fan: "front2"},
+		{Fan: "noRpm"},
+	}, front1, front2, noRpm)
+
+	// WHEN
+	rpm, err := group.GetRpm()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 1101, rpm)
+	assert.True(t, group.Supports(FeatureRpmSensor))
+	assert.False(t, group.Supports(FeaturePwmSensor))
+	assert.Equal(t, 1000, group.Members[0].Rpm)
+	assert.Equal(t, 1201, group.Members[1].Rpm)
+}
+
+func TestGroupFan_GetRpm_NoRpmSensor(t *testing.T) {
+	// GIVEN
+	front1 := createGroupMemberFan(t, "front1", nil, nil)
+	group := createGroupFan(t, []configuration.GroupFanMemberConfig{{Fan: "front1"}}, front1)
+
+	// WHEN
+	_, err := group.GetRpm()
+
+	// THEN
+	assert.False(t, group.Supports(FeatureRpmSensor))
+	assert.EqualError(t, err, "fan group: no group member supports rpm readings")
+}
+
+func TestGroupFan_MinAndStartPwm(t *testing.T) {
+	// GIVEN
+	minPwm1, startPwm1 := 30, 60
+	minPwm2, startPwm2 := 40, 50
+	front := &HwMonFan{Config: configuration.FanConfig{ID: "front"}, MinPwm: &minPwm1, StartPwm: &startPwm1}
+	rear := &HwMonFan{Config: configuration.FanConfig{ID: "rear"}, MinPwm: &minPwm2, StartPwm: &startPwm2}
+	group := createGroupFan(t, []configuration.GroupFanMemberConfig{
+		{Fan: "front"},
+		{Fan: "rear", Multiplier: 0.5, Offset: 10},
+	}, front, rear)
+
+	// WHEN
+	groupMinPwm := group.GetMinPwm()
+	groupStartPwm := group.GetStartPwm()
+
+	// THEN
+	// rear: (40 - 10) / 0.5 = 60 and (50 - 10) / 0.5 = 80
+	assert.Equal(t, 60, groupMinPwm)
+	assert.Equal(t, 80, groupStartPwm)
+}
+
+func TestGroupFan_MinAndStartPwm_Configured(t *testing.T) {
+	// GIVEN
+	minPwm, startPwm := 100, 120
+	front := &HwMonFan{Config: configuration.FanConfig{ID: "front"}, MinPwm: &minPwm, StartPwm: &startPwm}
+	group := createGroupFan(t, []configuration.GroupFanMemberConfig{{Fan: "front"}}, front)
+	groupMinPwm, groupStartPwm := 20, 40
+	group.Config.MinPwm = &groupMinPwm
+	group.Config.StartPwm = &groupStartPwm
+
+	// WHEN
+	resultMinPwm := group.GetMinPwm()
+	resultStartPwm := group.GetStartPwm()
+
+	// THEN
+	assert.Equal(t, 20, resultMinPwm)
+	assert.Equal(t, 40, resultStartPwm)
+}
+
+func TestGroupFan_SetPwm_SavedPwmMap(t *testing.T) {
+	// GIVEN
+	front := createGroupMemberFan(t, "front", nil, nil)
+	rear := createGroupMemberFan(t, "rear", nil, &configuration.PwmMapConfig{
+		Linear: &configuration.PwmMapLinearConfig{0: 0, 255: 100},
+	})
+	savedPwmMap := make([]int, MaxPwmValue+1)
+	for i := range savedPwmMap {
+		savedPwmMap[i] = i / 2
+	}
+	group, err := NewGroupFan(configuration.FanConfig{
+		ID: "group",
+		Group: &configuration.GroupFanConfig{Members: []configuration.GroupFanMemberConfig{
+			{Fan: "front"},
+			{Fan: "rear"},
+		}},
+	}, map[string]Fan{"front": front, "rear": rear}, map[string][]int{"front": savedPwmMap, "rear": savedPwmMap})
+	require.NoError(t, err)
+
+	// WHEN
+	err = group.SetPwm(200)
+
+	// THEN
+	require.NoError(t, err)
+	frontPwm, _ := front.GetPwm()
+	rearPwm, _ := rear.GetPwm()
+	// the saved pwmMap is used, unless one is configured
+	assert.Equal(t, 100, frontPwm)
+	assert.Equal(t, 78, rearPwm)
+}
+
+func TestGroupFan_GetFanRpmCurveData(t *testing.T) {
+	// GIVEN
+	front := createGroupMemberFan(t, "front", nil, nil)
+	group := createGroupFan(t, []configuration.GroupFanMemberConfig{{Fan: "front"}}, front)
+
+	// WHEN
+	curveData := group.GetFanRpmCurveData()
+
+	// THEN
+	require.NotNil(t, curveData)
+	assert.Len(t, *curveData, MaxPwmValue+1)
+	assert.Equal(t, 0.0, (*curveData)[0])
+	assert.Equal(t, 128.0, (*curveData)[128])
+	assert.Equal(t, 255.0, (*curveData)[255])
+	assert.NotSame(t, &interpolated, curveData)
+}
@@ -141,11 +141,10 @@ func (fan *HwMonFan) AttachFanRpmCurveData(curveData *map[int]float64) (err erro
 	return err
 }
 
+// UpdateFanRpmCurveValue replaces the curve data of this fan with a copy containing the given value.
+// The attached map is never modified, since it may be read concurrently (f.ex. by the API).
 func (fan *HwMonFan) UpdateFanRpmCurveValue(pwm int, rpm float64) {
-	if fan.FanCurveData == nil {
-		fan.FanCurveData = &map[int]float64{}
-	}
-	(*fan.FanCurveData)[pwm] = rpm
+	fan.FanCurveData = copyCurveDataWithValue(fan.FanCurveData, pwm, rpm)
 }
 
 func (fan *HwMonFan) GetCurveId() string {
@@ -248,6 +247,12 @@ func (fan *HwMonFan) SetConfig(config configuration.FanConfig) {
 	fan.Config = config
 }
 
+// SetHwMonConfig replaces the resolved hwmon paths of this fan, f.ex. after its hwmon device was renumbered
+func (fan *HwMonFan) SetHwMonConfig(config *configuration.HwMonFanConfig) {
+	fan.pwmEnableReadable = nil
+	fan.Config.HwMon = config
+}
+
 func (fan *HwMonFan) Supports(feature FeatureFlag) bool {
 	switch feature {
 	case FeatureControlModeWrite:
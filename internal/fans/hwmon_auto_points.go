@@ -0,0 +1,102 @@
+package fans
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// AutoPoint is a trip point of the automatic fan control of a hwmon chip
+// (pwmN_auto_pointM_temp and pwmN_auto_pointM_pwm)
+type AutoPoint struct {
+	// Temp is the temperature of this point in milli-degrees
+	Temp int `json:"temp"`
+	// Pwm is the raw PWM value applied at this temperature
+	Pwm int `json:"pwm"`
+}
+
+func (fan *HwMonFan) autoPointPath(point int, kind string) string {
+	return path.Join(fan.Config.HwMon.SysfsPath, fmt.Sprintf("pwm%d_auto_point%d_%s", fan.Config.HwMon.PwmChannel, point, kind))
+}
+
+// GetAutoPointCount returns the number of trip points the hwmon chip of this fan supports,
+// 0 if the driver doesn't expose trip points
+func (fan *HwMonFan) GetAutoPointCount() int {
+	count := 0
+	for {
+		if _, err := os.Stat(fan.autoPointPath(count+1, "temp")); err != nil {
+			return count
+		}
+		if _, err := os.Stat(fan.autoPointPath(count+1, "pwm")); err != nil {
+			return count
+		}
+		count++
+	}
+}
+
+// GetAutoPoints reads the trip points currently programmed into the hwmon chip of this fan
+func (fan *HwMonFan) GetAutoPoints() ([]AutoPoint, error) {
+	count := fan.GetAutoPointCount()
+	if count <= 0 {
+		return nil, fmt.Errorf("fan %s: driver doesn't expose auto points", fan.GetId())
+	}
+	points := make([]AutoPoint, count)
+	for i := range points {
+		temp, err := util.ReadIntFromFile(fan.autoPointPath(i+1, "temp"))
+		if err != nil {
+			return nil, fmt.Errorf("fan %s: error reading auto point %d: %w", fan.GetId(), i+1, err)
+		}
+		pwm, err := util.ReadIntFromFile(fan.autoPointPath(i+1, "pwm"))
+		if err != nil {
+			return nil, fmt.Errorf("fan %s: error reading auto point %d: %w", fan.GetId(), i+1, err)
+		}
+		points[i] = AutoPoint{Temp: temp, Pwm: pwm}
+	}
+	return points, nil
+}
+
+// SetAutoPoints programs the given trip points into the hwmon chip of this fan.
+// The number of points has to match GetAutoPointCount.
+func (fan *HwMonFan) SetAutoPoints(points []AutoPoint) error {
+	count := fan.GetAutoPointCount()
+	if count <= 0 {
+		return fmt.Errorf("fan %s: driver doesn't expose auto points", fan.GetId())
+	}
+	if len(points) != count {
+		return fmt.Errorf("fan %s: expected %d auto points, got %d", fan.GetId(), count, len(points))
+	}
+	for i, point := range points {
+		err := util.WriteIntToFile(point.Temp, fan.autoPointPath(i+1, "temp"))
+		if err != nil {
+			return fmt.Errorf("fan %s: error writing auto point %d: %w", fan.GetId(), i+1, err)
+		}
+		err = util.WriteIntToFile(point.Pwm, fan.autoPointPath(i+1, "pwm"))
+		if err != nil {
+			return fmt.Errorf("fan %s: error writing auto point %d: %w", fan.GetId(), i+1, err)
+		}
+	}
+	return nil
+}
+
+// GetAutoPointTempInput returns the path of the temperature input the trip points of this fan refer to,
+// as selected by pwmN_temp_sel. Returns an empty path, if the driver doesn't expose the selection.
+func (fan *HwMonFan) GetAutoPointTempInput() (string, error) {
+	selectionPath := path.Join(fan.Config.HwMon.SysfsPath, fmt.Sprintf("pwm%d_temp_sel", fan.Config.HwMon.PwmChannel))
+	channel, err := util.ReadIntFromFile(selectionPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("fan %s: error reading temperature source of auto points: %w", fan.GetId(), err)
+	}
+	return path.Join(fan.Config.HwMon.SysfsPath, fmt.Sprintf("temp%d_input", channel)), nil
+}
+
+// SetAutomaticControlMode sets the pwm_enable value written for ControlModeAutomatic,
+// f.ex. 5 for the "Smart Fan IV" trip point mode of nct6775 chips
+func (fan *HwMonFan) SetAutomaticControlMode(value int) {
+	fan.lastKnownAutomaticControlMode = &value
+}
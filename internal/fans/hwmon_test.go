@@ -1,12 +1,15 @@
 package fans
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHwMonFan_GetId(t *testing.T) {
@@ -320,6 +323,24 @@ func TestHwMonFan_AttachFanCurveData(t *testing.T) {
 	assert.Equal(t, 200, fan.GetMaxPwm())
 }
 
+func TestHwMonFan_UpdateFanRpmCurveValue_DoesNotModifyAttachedData(t *testing.T) {
+	// GIVEN
+	curveData := map[int]float64{
+		0:   0,
+		200: 2000,
+	}
+	fan := HwMonFan{
+		FanCurveData: &curveData,
+	}
+
+	// WHEN
+	fan.UpdateFanRpmCurveValue(200, 1900)
+
+	// THEN
+	assert.Equal(t, map[int]float64{0: 0, 200: 2000}, curveData)
+	assert.Equal(t, map[int]float64{0: 0, 200: 1900}, *fan.GetFanRpmCurveData())
+}
+
 func TestHwMonFan_GetCurveId(t *testing.T) {
 	// GIVEN
 	expected := "test"
@@ -484,3 +505,71 @@ func TestHwMonFan_Supports_RpmSensor_False(t *testing.T) {
 	// THEN
 	assert.False(t, result)
 }
+
+// createAutoPointFiles creates empty trip point files for the second pwm channel in a temporary directory
+func createAutoPointFiles(t *testing.T, count int) string {
+	dir := t.TempDir()
+	for i := 1; i <= count; i++ {
+		for _, kind := range []string{"temp", "pwm"} {
+			err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("pwm2_auto_point%d_%s", i, kind)), []byte("0"), 0644)
+			require.NoError(t, err)
+		}
+	}
+	return dir
+}
+
+func TestHwMonFan_AutoPoints(t *testing.T) {
+	// GIVEN
+	fan := HwMonFan{
+		Config: configuration.FanConfig{
+			ID:    "cpu",
+			HwMon: &configuration.HwMonFanConfig{PwmChannel: 2, SysfsPath: createAutoPointFiles(t, 3)},
+		},
+	}
+	points := []AutoPoint{{Temp: 30000, Pwm: 60}, {Temp: 50000, Pwm: 128}, {Temp: 70000, Pwm: 255}}
+
+	// WHEN
+	count := fan.GetAutoPointCount()
+	err := fan.SetAutoPoints(points)
+	result, readErr := fan.GetAutoPoints()
+
+	// THEN
+	assert.Equal(t, 3, count)
+	require.NoError(t, err)
+	require.NoError(t, readErr)
+	assert.Equal(t, points, result)
+}
+
+func TestHwMonFan_SetAutoPoints_CountMismatch(t *testing.T) {
+	// GIVEN
+	fan := HwMonFan{
+		Config: configuration.FanConfig{
+			ID:    "cpu",
+			HwMon: &configuration.HwMonFanConfig{PwmChannel: 2, SysfsPath: createAutoPointFiles(t, 3)},
+		},
+	}
+
+	// WHEN
+	err := fan.SetAutoPoints([]AutoPoint{{Temp: 30000, Pwm: 60}})
+
+	// THEN
+	assert.EqualError(t, err, "fan cpu: expected 3 auto points, got 1")
+}
+
+func TestHwMonFan_GetAutoPoints_Unsupported(t *testing.T) {
+	// GIVEN
+	fan := HwMonFan{
+		Config: configuration.FanConfig{
+			ID:    "cpu",
+			HwMon: &configuration.HwMonFanConfig{PwmChannel: 2, SysfsPath: t.TempDir()},
+		},
+	}
+
+	// WHEN
+	count := fan.GetAutoPointCount()
+	_, err := fan.GetAutoPoints()
+
+	// THEN
+	assert.Equal(t, 0, count)
+	assert.EqualError(t, err, "fan cpu: driver doesn't expose auto points")
+}
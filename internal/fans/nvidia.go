@@ -208,11 +208,10 @@ func (fan *NvidiaFan) AttachFanRpmCurveData(curveData *map[int]float64) (err err
 	return err
 }
 
+// UpdateFanRpmCurveValue replaces the curve data of this fan with a copy containing the given value.
+// The attached map is never modified, since it may be read concurrently (f.ex. by the API).
 func (fan *NvidiaFan) UpdateFanRpmCurveValue(pwm int, rpm float64) {
-	if fan.FanCurveData == nil {
-		fan.FanCurveData = &map[int]float64{}
-	}
-	(*fan.FanCurveData)[pwm] = rpm
+	fan.FanCurveData = copyCurveDataWithValue(fan.FanCurveData, pwm, rpm)
 }
 
 func (fan *NvidiaFan) GetCurveId() string {
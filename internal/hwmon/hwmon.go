@@ -6,6 +6,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/markusressel/fan2go/internal/ui"
@@ -34,11 +35,15 @@ type HwMonController struct {
 	Modalias string
 	Platform string
 	Path     string
+	// PciPath is the resolved sysfs path of the PCI device of this chip, if any
+	PciPath string
 
 	// Fans (can be matched either by enumeration index or channel number)
 	Fans []fans.HwMonFan
 	// Sensors maps from hwmon channel number -> HwmonSensor instance
 	Sensors map[int]*sensors.HwmonSensor
+	// Inputs maps from input type (power, curr, in, fan) -> hwmon channel number -> HwmonSensor instance
+	Inputs map[string]map[int]*sensors.HwmonSensor
 }
 
 func GetChips() []*HwMonController {
@@ -61,8 +66,9 @@ func GetChips() []*HwMonController {
 
 		fanSlice := GetFans(chip)
 		sensorMap := GetTempSensors(chip)
+		inputMap := GetInputSensors(chip)
 
-		if len(fanSlice) <= 0 && len(sensorMap) <= 0 {
+		if len(fanSlice) <= 0 && len(sensorMap) <= 0 && len(inputMap) <= 0 {
 			continue
 		}
 
@@ -72,8 +78,10 @@ func GetChips() []*HwMonController {
 			Modalias: modalias,
 			Platform: platform,
 			Path:     chip.Path,
+			PciPath:  getDevicePciPath(chip.Path),
 			Fans:     fanSlice,
 			Sensors:  sensorMap,
+			Inputs:   inputMap,
 		}
 		list = append(list, c)
 	}
@@ -96,6 +104,22 @@ func getDeviceModalias(devicePath string) string {
 	return strings.TrimSpace(string(content))
 }
 
+// getDevicePciPath resolves the sysfs path of the PCI device a hwmon device belongs to,
+// f.ex. "/sys/devices/pci0000:00/0000:00:03.1/0000:09:00.0". Returns an empty string for non-PCI devices.
+func getDevicePciPath(devicePath string) string {
+	resolved, err := filepath.EvalSymlinks(path.Join(devicePath, "device"))
+	if err != nil {
+		return ""
+	}
+	return findPciPath(resolved)
+}
+
+// findPciPath returns the prefix of the given sysfs device path up to its last PCI device address
+func findPciPath(devicePath string) string {
+	pciPathRegex := regexp.MustCompile(`^.*/pci[0-9a-f]{4,5}:[0-9a-f]{2}(/[0-9a-f]{4,5}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-9a-f])+`)
+	return pciPathRegex.FindString(devicePath)
+}
+
 // getDeviceType read the type of a device
 func getDeviceType(devicePath string) string {
 	modaliasPath := path.Join(devicePath, "device", "type")
@@ -159,6 +183,75 @@ func GetTempSensors(chip gosensors.Chip) map[int]*sensors.HwmonSensor {
 	return result
 }
 
+// GetInputSensors returns all non-temperature inputs of the given chip, that can be used as sensors,
+// mapped by input type and channel
+func GetInputSensors(chip gosensors.Chip) map[string]map[int]*sensors.HwmonSensor {
+	result := map[string]map[int]*sensors.HwmonSensor{}
+
+	indices := map[string]int{}
+	features := chip.GetFeatures()
+	for j := 0; j < len(features); j++ {
+		feature := features[j]
+		subfeatures := feature.GetSubFeatures()
+
+		var inputType string
+		var inputSubFeature *gosensors.SubFeature
+		switch feature.Type {
+		case gosensors.FeatureTypePower:
+			inputType = configuration.HwMonSensorTypePower
+			inputSubFeature = getSubFeature(subfeatures, gosensors.SubFeatureTypePowerInput)
+			if inputSubFeature == nil {
+				inputSubFeature = getSubFeature(subfeatures, gosensors.SubFeatureTypePowerAverage)
+			}
+		case gosensors.FeatureTypeCurr:
+			inputType = configuration.HwMonSensorTypeCurrent
+			inputSubFeature = getSubFeature(subfeatures, gosensors.SubFeatureTypeCurrInput)
+		case gosensors.FeatureTypeIn:
+			inputType = configuration.HwMonSensorTypeVoltage
+			inputSubFeature = getSubFeature(subfeatures, gosensors.SubFeatureTypeInInput)
+		case gosensors.FeatureTypeFan:
+			inputType = configuration.HwMonSensorTypeFan
+			inputSubFeature = getSubFeature(subfeatures, gosensors.SubFeatureTypeFanInput)
+		default:
+			continue
+		}
+		if inputSubFeature == nil {
+			continue
+		}
+
+		var channel int
+		_, err := fmt.Sscanf(feature.Name, inputType+"%d", &channel)
+		if err != nil {
+			ui.Warning("No channel found for '%s', ignoring.", feature.Name)
+			continue
+		}
+
+		indices[inputType]++
+		if result[inputType] == nil {
+			result[inputType] = map[int]*sensors.HwmonSensor{}
+		}
+		result[inputType][channel] = &sensors.HwmonSensor{
+			Label:   getLabel(chip.Path, feature.Name),
+			Index:   indices[inputType],
+			Channel: channel,
+			Type:    inputType,
+			Input:   path.Join(chip.Path, inputSubFeature.Name),
+			Max:     -1,
+			Min:     -1,
+		}
+	}
+
+	return result
+}
+
+// sensorsOfType returns the sensors of the given input type (temp if empty) of a controller, mapped by channel
+func sensorsOfType(controller *HwMonController, inputType string) map[int]*sensors.HwmonSensor {
+	if len(inputType) <= 0 || inputType == configuration.HwMonSensorTypeTemp {
+		return controller.Sensors
+	}
+	return controller.Inputs[inputType]
+}
+
 var (
 	FeatureTypePwm        gosensors.FeatureType    = 7
 	SubFeatureTypeFanMode gosensors.SubFeatureType = 1920
@@ -330,9 +423,9 @@ func findPlatform(devicePath string) string {
 
 func UpdateFanConfigFromHwMonControllers(controllers []*HwMonController, config *configuration.FanConfig) error {
 	for _, controller := range controllers {
-		matched, err := regexp.MatchString("(?i)"+config.HwMon.Platform, controller.Platform)
+		matched, err := controllerMatches(controller, config.HwMon.Platform, config.HwMon.Modalias, config.HwMon.PciPath)
 		if err != nil {
-			return fmt.Errorf("failed to match platform regex of %s (%s) against controller platform %s", config.ID, config.HwMon.Platform, controller.Platform)
+			return fmt.Errorf("fan %s: %w", config.ID, err)
 		}
 		if !matched {
 			continue
@@ -345,6 +438,9 @@ func UpdateFanConfigFromHwMonControllers(controllers []*HwMonController, config
 			if config.HwMon.RpmChannel > 0 && controllerConfig.RpmChannel != config.HwMon.RpmChannel {
 				continue
 			}
+			if len(config.HwMon.Label) > 0 && !labelMatches(config.HwMon.Label, fan.Label) {
+				continue
+			}
 			config.HwMon.Index = controllerConfig.Index
 			config.HwMon.RpmChannel = controllerConfig.RpmChannel
 			config.HwMon.SysfsPath = controllerConfig.SysfsPath
@@ -360,20 +456,33 @@ func UpdateFanConfigFromHwMonControllers(controllers []*HwMonController, config
 
 func UpdateSensorConfigFromHwMonControllers(controllers []*HwMonController, config *configuration.SensorConfig) error {
 	for _, controller := range controllers {
-		matched, err := regexp.MatchString("(?i)"+config.HwMon.Platform, controller.Platform)
+		matched, err := controllerMatches(controller, config.HwMon.Platform, config.HwMon.Modalias, config.HwMon.PciPath)
 		if err != nil {
-			return fmt.Errorf("failed to match platform regex of %s (%s) against controller platform %s", config.ID, config.HwMon.Platform, controller.Platform)
+			return fmt.Errorf("sensor %s: %w", config.ID, err)
 		}
 		if !matched {
 			continue
 		}
-		for _, sensor := range controller.Sensors {
+
+		// iterate in channel order, so a label regex matching multiple sensors is resolved deterministically
+		controllerSensors := sensorsOfType(controller, config.HwMon.Type)
+		channels := make([]int, 0, len(controllerSensors))
+		for channel := range controllerSensors {
+			channels = append(channels, channel)
+		}
+		sort.Ints(channels)
+
+		for _, channel := range channels {
+			sensor := controllerSensors[channel]
 			if config.HwMon.Index > 0 && sensor.Index != config.HwMon.Index {
 				continue
 			}
 			if config.HwMon.Channel > 0 && sensor.Channel != config.HwMon.Channel {
 				continue
 			}
+			if len(config.HwMon.Label) > 0 && !labelMatches(config.HwMon.Label, sensor.Label) {
+				continue
+			}
 			config.HwMon.Index = sensor.Index
 			config.HwMon.Channel = sensor.Channel
 			config.HwMon.TempInput = sensor.Input
@@ -383,6 +492,89 @@ func UpdateSensorConfigFromHwMonControllers(controllers []*HwMonController, conf
 	return fmt.Errorf("no hwmon sensor matched sensor config: %+v", config)
 }
 
+// UpdateAggregateSensorConfigFromHwMonControllers resolves the temperature inputs of all hwmon sensors
+// matched by the platform and label patterns of the given aggregate sensor config
+func UpdateAggregateSensorConfigFromHwMonControllers(controllers []*HwMonController, config *configuration.SensorConfig) error {
+	var inputs []string
+	for _, controller := range controllers {
+		matched, err := path.Match(config.Aggregate.Platform, controller.Platform)
+		if err != nil {
+			return fmt.Errorf("sensor %s: invalid platform pattern '%s': %w", config.ID, config.Aggregate.Platform, err)
+		}
+		if !matched {
+			continue
+		}
+
+		channels := make([]int, 0, len(controller.Sensors))
+		for channel := range controller.Sensors {
+			channels = append(channels, channel)
+		}
+		sort.Ints(channels)
+
+		for _, channel := range channels {
+			sensor := controller.Sensors[channel]
+			if len(config.Aggregate.LabelMatch) > 0 {
+				matched, err = path.Match(config.Aggregate.LabelMatch, sensor.Label)
+				if err != nil {
+					return fmt.Errorf("sensor %s: invalid label pattern '%s': %w", config.ID, config.Aggregate.LabelMatch, err)
+				}
+				if !matched {
+					continue
+				}
+			}
+			inputs = append(inputs, sensor.Input)
+		}
+	}
+
+	if len(inputs) <= 0 {
+		return fmt.Errorf("no hwmon sensor matched aggregate sensor config: %+v", *config.Aggregate)
+	}
+	config.Aggregate.HwMonInputs = inputs
+	return nil
+}
+
+// controllerMatches checks whether the given controller matches the chip selectors of a fan or sensor config.
+// Empty selectors match any controller.
+func controllerMatches(controller *HwMonController, platform string, modalias string, pciPath string) (bool, error) {
+	matched, err := regexp.MatchString("(?i)"+platform, controller.Platform)
+	if err != nil {
+		return false, fmt.Errorf("failed to match platform regex (%s) against controller platform %s", platform, controller.Platform)
+	}
+	if !matched {
+		return false, nil
+	}
+
+	if len(modalias) > 0 {
+		matched, err = regexp.MatchString("(?i)"+modalias, controller.Modalias)
+		if err != nil {
+			return false, fmt.Errorf("failed to match modalias regex (%s) against controller modalias %s", modalias, controller.Modalias)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	if len(pciPath) > 0 {
+		// accept the full path as well as any trailing part of it, f.ex. just the PCI address "0000:09:00.0"
+		trimmed := strings.TrimSuffix(pciPath, "/")
+		if len(controller.PciPath) <= 0 || (controller.PciPath != trimmed && !strings.HasSuffix(controller.PciPath, "/"+trimmed)) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// labelMatches checks whether a hwmon label matches the configured label, either exactly
+// or as a regular expression that has to match the whole label
+func labelMatches(pattern string, label string) bool {
+	if pattern == label {
+		return true
+	}
+	matched, err := regexp.MatchString("^(?:"+pattern+")$", label)
+	return err == nil && matched
+}
+
 func setFanConfigPaths(config *configuration.HwMonFanConfig) {
 	config.RpmInputPath = path.Join(config.SysfsPath, fmt.Sprintf("fan%d_input", config.RpmChannel))
 	config.PwmPath = path.Join(config.SysfsPath, fmt.Sprintf("pwm%d", config.PwmChannel))
@@ -81,6 +81,103 @@ func TestFindPlatform(t *testing.T) {
 	assert.Equal(t, "", platform)
 }
 
+func TestFindPciPath(t *testing.T) {
+	// GIVEN
+	devicePath := "/sys/devices/pci0000:00/0000:00:0e.0/pci10000:e0/10000:e0:06.0/10000:e1:00.0/nvme/nvme0"
+
+	// WHEN
+	pciPath := findPciPath(devicePath)
+
+	// THEN
+	assert.Equal(t, "/sys/devices/pci0000:00/0000:00:0e.0/pci10000:e0/10000:e0:06.0/10000:e1:00.0", pciPath)
+}
+
+func TestFindPciPath_NonPciDevice(t *testing.T) {
+	// GIVEN
+	devicePath := "/sys/devices/platform/nct6775.656"
+
+	// WHEN
+	pciPath := findPciPath(devicePath)
+
+	// THEN
+	assert.Equal(t, "", pciPath)
+}
+
+func TestControllerMatches(t *testing.T) {
+	controller := &HwMonController{
+		Platform: "amdgpu-pci-0900",
+		Modalias: "pci:v00001002d0000744Csv00001DA2sd0000E471bc03sc00i00",
+		PciPath:  "/sys/devices/pci0000:00/0000:00:03.1/0000:09:00.0",
+	}
+
+	var tests = []struct {
+		tn       string
+		platform string
+		modalias string
+		pciPath  string
+		want     bool
+	}{
+		{tn: "platform only", platform: "amdgpu", want: true},
+		{tn: "modalias regex", modalias: "pci:v00001002d0000744C.*", want: true},
+		{tn: "modalias mismatch", modalias: "pci:v000010DE.*", want: false},
+		{tn: "pci address", pciPath: "0000:09:00.0", want: true},
+		{tn: "full pci path", pciPath: "/sys/devices/pci0000:00/0000:00:03.1/0000:09:00.0", want: true},
+		{tn: "pci address mismatch", pciPath: "0000:0a:00.0", want: false},
+		{tn: "partial pci address", pciPath: "9:00.0", want: false},
+		{tn: "platform mismatch", platform: "nct6775", pciPath: "0000:09:00.0", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tn, func(t *testing.T) {
+			// WHEN
+			result, err := controllerMatches(controller, tt.platform, tt.modalias, tt.pciPath)
+
+			// THEN
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
+
+func TestUpdateFanConfigFromHwMonControllers_MatchByLabel(t *testing.T) {
+	// GIVEN
+	controllers := []*HwMonController{
+		{
+			Platform: "nct6798",
+			Fans: []fans.HwMonFan{
+				{
+					Label: "SYS_FAN1",
+					Config: configuration.FanConfig{
+						HwMon: &configuration.HwMonFanConfig{Index: 1, RpmChannel: 1, PwmChannel: 1, SysfsPath: "/sys/hwmon2"},
+					},
+				},
+				{
+					Label: "CPU_FAN",
+					Config: configuration.FanConfig{
+						HwMon: &configuration.HwMonFanConfig{Index: 2, RpmChannel: 2, PwmChannel: 2, SysfsPath: "/sys/hwmon2"},
+					},
+				},
+			},
+		},
+	}
+	config := configuration.FanConfig{
+		ID: "cpu",
+		HwMon: &configuration.HwMonFanConfig{
+			Platform: "nct6798",
+			Label:    "CPU_FAN",
+		},
+	}
+
+	// WHEN
+	err := UpdateFanConfigFromHwMonControllers(controllers, &config)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 2, config.HwMon.Index)
+	assert.Equal(t, 2, config.HwMon.RpmChannel)
+	assert.Equal(t, "/sys/hwmon2/pwm2", config.HwMon.PwmPath)
+}
+
 func TestUpdateFanConfigFromHwMonControllers(t *testing.T) {
 	var tests = []struct {
 		tn            string
@@ -267,6 +364,46 @@ func TestUpdateSensorConfigFromHwMonControllers(t *testing.T) {
 			Channel:   3,
 			TempInput: "/sys/hwmon1/temp3_input",
 		},
+	}, {
+		tn: "match by label",
+		hwmonSensors: map[int]*sensors.HwmonSensor{
+			1: {Index: 1, Channel: 1, Label: "Tctl", Input: "/sys/hwmon1/temp1_input"},
+			3: {Index: 2, Channel: 3, Label: "Tccd1", Input: "/sys/hwmon1/temp3_input"},
+		},
+		configConfig: configuration.HwMonSensorConfig{
+			Label: "Tccd1",
+		},
+		wantConfig: &configuration.HwMonSensorConfig{
+			Index:     2,
+			Channel:   3,
+			Label:     "Tccd1",
+			TempInput: "/sys/hwmon1/temp3_input",
+		},
+	}, {
+		tn: "match by label regex uses lowest channel",
+		hwmonSensors: map[int]*sensors.HwmonSensor{
+			4: {Index: 3, Channel: 4, Label: "Tccd2", Input: "/sys/hwmon1/temp4_input"},
+			1: {Index: 1, Channel: 1, Label: "Tctl", Input: "/sys/hwmon1/temp1_input"},
+			3: {Index: 2, Channel: 3, Label: "Tccd1", Input: "/sys/hwmon1/temp3_input"},
+		},
+		configConfig: configuration.HwMonSensorConfig{
+			Label: "Tccd[0-9]",
+		},
+		wantConfig: &configuration.HwMonSensorConfig{
+			Index:     2,
+			Channel:   3,
+			Label:     "Tccd[0-9]",
+			TempInput: "/sys/hwmon1/temp3_input",
+		},
+	}, {
+		tn: "label must match completely",
+		hwmonSensors: map[int]*sensors.HwmonSensor{
+			1: {Index: 1, Channel: 1, Label: "Tctl2", Input: "/sys/hwmon1/temp1_input"},
+		},
+		configConfig: configuration.HwMonSensorConfig{
+			Label: "Tctl",
+		},
+		wantErr: "no hwmon sensor matched sensor config",
 	}, {
 		tn: "no hwmon sensors",
 		configConfig: configuration.HwMonSensorConfig{
@@ -340,3 +477,99 @@ func TestUpdateSensorConfigFromHwMonControllers(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateAggregateSensorConfigFromHwMonControllers(t *testing.T) {
+	// GIVEN
+	controllers := []*HwMonController{
+		{
+			Platform: "coretemp-isa-0000",
+			Sensors: map[int]*sensors.HwmonSensor{
+				3: {Index: 3, Channel: 3, Label: "Core 1", Input: "/sys/hwmon2/temp3_input"},
+				1: {Index: 1, Channel: 1, Label: "Package id 0", Input: "/sys/hwmon2/temp1_input"},
+				2: {Index: 2, Channel: 2, Label: "Core 0", Input: "/sys/hwmon2/temp2_input"},
+			},
+		},
+		{
+			Platform: "nvme-pci-0100",
+			Sensors: map[int]*sensors.HwmonSensor{
+				1: {Index: 1, Channel: 1, Label: "Composite", Input: "/sys/hwmon3/temp1_input"},
+			},
+		},
+	}
+	config := configuration.SensorConfig{
+		ID: "cores",
+		Aggregate: &configuration.AggregateSensorConfig{
+			Source:     configuration.AggregateSourceHwMon,
+			Platform:   "coretemp-*",
+			LabelMatch: "Core *",
+		},
+	}
+
+	// WHEN
+	err := UpdateAggregateSensorConfigFromHwMonControllers(controllers, &config)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/sys/hwmon2/temp2_input", "/sys/hwmon2/temp3_input"}, config.Aggregate.HwMonInputs)
+}
+
+func TestUpdateAggregateSensorConfigFromHwMonControllers_NoMatch(t *testing.T) {
+	// GIVEN
+	controllers := []*HwMonController{
+		{
+			Platform: "k10temp-pci-00c3",
+			Sensors: map[int]*sensors.HwmonSensor{
+				1: {Index: 1, Channel: 1, Label: "Tctl", Input: "/sys/hwmon1/temp1_input"},
+			},
+		},
+	}
+	config := configuration.SensorConfig{
+		ID: "cores",
+		Aggregate: &configuration.AggregateSensorConfig{
+			Source:   configuration.AggregateSourceHwMon,
+			Platform: "coretemp-*",
+		},
+	}
+
+	// WHEN
+	err := UpdateAggregateSensorConfigFromHwMonControllers(controllers, &config)
+
+	// THEN
+	assert.ErrorContains(t, err, "no hwmon sensor matched aggregate sensor config")
+}
+
+func TestUpdateSensorConfigFromHwMonControllers_InputType(t *testing.T) {
+	// GIVEN
+	controllers := []*HwMonController{
+		{
+			Platform: "amdgpu-pci-0900",
+			Sensors: map[int]*sensors.HwmonSensor{
+				1: {Index: 1, Channel: 1, Label: "edge", Input: "/sys/hwmon4/temp1_input"},
+			},
+			Inputs: map[string]map[int]*sensors.HwmonSensor{
+				configuration.HwMonSensorTypePower: {
+					1: {Index: 1, Channel: 1, Label: "PPT", Type: configuration.HwMonSensorTypePower, Input: "/sys/hwmon4/power1_average"},
+				},
+				configuration.HwMonSensorTypeFan: {
+					1: {Index: 1, Channel: 1, Label: "fan1", Type: configuration.HwMonSensorTypeFan, Input: "/sys/hwmon4/fan1_input"},
+				},
+			},
+		},
+	}
+	config := configuration.SensorConfig{
+		ID: "gpu_power",
+		HwMon: &configuration.HwMonSensorConfig{
+			Platform: "amdgpu",
+			Type:     configuration.HwMonSensorTypePower,
+			Channel:  1,
+		},
+	}
+
+	// WHEN
+	err := UpdateSensorConfigFromHwMonControllers(controllers, &config)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, "/sys/hwmon4/power1_average", config.HwMon.TempInput)
+	assert.Equal(t, configuration.SensorUnitWatt, config.GetUnit())
+}
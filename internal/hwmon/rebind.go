@@ -0,0 +1,80 @@
+package hwmon
+
+import (
+	"errors"
+	"io/fs"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/sensors"
+)
+
+// rescanInterval is the minimum time between two rescans of all hwmon devices,
+// since multiple fans and sensors usually fail at the same time when a device disappears
+const rescanInterval = 5 * time.Second
+
+var (
+	rescanMu       sync.Mutex
+	lastRescan     time.Time
+	lastRescanList []*HwMonController
+
+	// getChips scans all hwmon devices, replaceable for tests
+	getChips = GetChips
+)
+
+// IsDeviceGoneError returns true if the given error indicates that the hwmon device of a sysfs file
+// disappeared, f.ex. because its driver was reloaded or the hwmon devices were renumbered
+func IsDeviceGoneError(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENODEV) || errors.Is(err, syscall.ENXIO)
+}
+
+// RescanChips returns the currently available hwmon devices. The result of a scan is reused
+// for rescanInterval, so devices failing at the same time only trigger a single scan.
+func RescanChips() []*HwMonController {
+	rescanMu.Lock()
+	defer rescanMu.Unlock()
+	if lastRescanList == nil || time.Since(lastRescan) >= rescanInterval {
+		lastRescanList = getChips()
+		lastRescan = time.Now()
+	}
+	return lastRescanList
+}
+
+// RebindFan resolves the sysfs paths of the given fan again, using a rescan of all hwmon devices.
+// Returns true, if the paths of the fan changed.
+func RebindFan(fan *fans.HwMonFan) (bool, error) {
+	config := fan.GetConfig()
+	hwMonConfig := *config.HwMon
+	config.HwMon = &hwMonConfig
+
+	err := UpdateFanConfigFromHwMonControllers(RescanChips(), &config)
+	if err != nil {
+		return false, err
+	}
+	changed := hwMonConfig.PwmPath != fan.GetConfig().HwMon.PwmPath ||
+		hwMonConfig.RpmInputPath != fan.GetConfig().HwMon.RpmInputPath
+	if changed {
+		fan.SetHwMonConfig(&hwMonConfig)
+	}
+	return changed, nil
+}
+
+// RebindSensor resolves the sysfs input of the given sensor again, using a rescan of all hwmon devices.
+// Returns true, if the input of the sensor changed.
+func RebindSensor(sensor *sensors.HwmonSensor) (bool, error) {
+	config := sensor.GetConfig()
+	hwMonConfig := *config.HwMon
+	config.HwMon = &hwMonConfig
+
+	err := UpdateSensorConfigFromHwMonControllers(RescanChips(), &config)
+	if err != nil {
+		return false, err
+	}
+	if hwMonConfig.TempInput == sensor.GetInput() {
+		return false, nil
+	}
+	sensor.SetInput(hwMonConfig.TempInput)
+	return true, nil
+}
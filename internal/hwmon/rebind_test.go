@@ -0,0 +1,125 @@
+package hwmon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRescan replaces the hwmon scan with the given controllers for the duration of a test
+func mockRescan(t *testing.T, controllers []*HwMonController) {
+	originalGetChips := getChips
+	getChips = func() []*HwMonController {
+		return controllers
+	}
+	lastRescanList = nil
+	t.Cleanup(func() {
+		getChips = originalGetChips
+		lastRescanList = nil
+	})
+}
+
+func TestIsDeviceGoneError(t *testing.T) {
+	// GIVEN
+	_, notExistErr := os.ReadFile(filepath.Join(t.TempDir(), "pwm1"))
+	noDeviceErr := &os.PathError{Op: "write", Path: "/sys/class/hwmon/hwmon2/pwm1", Err: syscall.ENODEV}
+	permissionErr := &os.PathError{Op: "write", Path: "/sys/class/hwmon/hwmon2/pwm1", Err: syscall.EACCES}
+
+	// THEN
+	assert.True(t, IsDeviceGoneError(notExistErr))
+	assert.True(t, IsDeviceGoneError(fmt.Errorf("sensor cpu: %w", noDeviceErr)))
+	assert.False(t, IsDeviceGoneError(permissionErr))
+	assert.False(t, IsDeviceGoneError(nil))
+}
+
+func TestRebindFan(t *testing.T) {
+	// GIVEN
+	mockRescan(t, []*HwMonController{
+		{
+			Platform: "nct6798",
+			Fans: []fans.HwMonFan{
+				{
+					Label: "CPU_FAN",
+					Config: configuration.FanConfig{
+						HwMon: &configuration.HwMonFanConfig{Index: 2, RpmChannel: 2, PwmChannel: 2, SysfsPath: "/sys/hwmon5"},
+					},
+				},
+			},
+		},
+	})
+	hwMonConfig := &configuration.HwMonFanConfig{Platform: "nct6798", Label: "CPU_FAN", Index: 2, RpmChannel: 2, PwmChannel: 2, SysfsPath: "/sys/hwmon2"}
+	setFanConfigPaths(hwMonConfig)
+	fan := &fans.HwMonFan{
+		Config: configuration.FanConfig{ID: "cpu", HwMon: hwMonConfig},
+	}
+
+	// WHEN
+	changed, err := RebindFan(fan)
+	unchanged, errUnchanged := RebindFan(fan)
+
+	// THEN
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "/sys/hwmon5/pwm2", fan.GetConfig().HwMon.PwmPath)
+	assert.Equal(t, "/sys/hwmon5/fan2_input", fan.GetConfig().HwMon.RpmInputPath)
+	// the original config is not modified
+	assert.Equal(t, "/sys/hwmon2/pwm2", hwMonConfig.PwmPath)
+	require.NoError(t, errUnchanged)
+	assert.False(t, unchanged)
+}
+
+func TestRebindFan_DeviceMissing(t *testing.T) {
+	// GIVEN
+	mockRescan(t, []*HwMonController{})
+	fan := &fans.HwMonFan{
+		Config: configuration.FanConfig{
+			ID:    "cpu",
+			HwMon: &configuration.HwMonFanConfig{Platform: "nct6798", Index: 2, RpmChannel: 2, PwmChannel: 2, SysfsPath: "/sys/hwmon2"},
+		},
+	}
+
+	// WHEN
+	changed, err := RebindFan(fan)
+
+	// THEN
+	assert.Error(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, "/sys/hwmon2", fan.GetConfig().HwMon.SysfsPath)
+}
+
+func TestRebindSensor(t *testing.T) {
+	// GIVEN
+	mockRescan(t, []*HwMonController{
+		{
+			Platform: "coretemp",
+			Sensors: map[int]*sensors.HwmonSensor{
+				1: {Index: 1, Channel: 1, Label: "Package id 0", Input: "/sys/hwmon7/temp1_input"},
+			},
+		},
+	})
+	sensor := &sensors.HwmonSensor{
+		Index: 1,
+		Input: "/sys/hwmon3/temp1_input",
+		Config: configuration.SensorConfig{
+			ID:    "cpu_package",
+			HwMon: &configuration.HwMonSensorConfig{Platform: "coretemp", Index: 1, Channel: 1, TempInput: "/sys/hwmon3/temp1_input"},
+		},
+	}
+
+	// WHEN
+	changed, err := RebindSensor(sensor)
+
+	// THEN
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "/sys/hwmon7/temp1_input", sensor.GetInput())
+	assert.Equal(t, "/sys/hwmon7/temp1_input", sensor.GetConfig().HwMon.TempInput)
+}
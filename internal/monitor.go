@@ -2,9 +2,13 @@ package internal
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"math"
 	"time"
 
 	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/hwmon"
 	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/markusressel/fan2go/internal/util"
@@ -15,50 +19,201 @@ type SensorMonitor interface {
 }
 
 type sensorMonitor struct {
-	sensor      sensors.Sensor
-	pollingRate time.Duration
+	sensor         sensors.Sensor
+	pollingRate    time.Duration
+	filter         sensors.Filter
+	faultDetector  *sensorFaultDetector
+	resumeDetector *util.ResumeDetector
 }
 
 func NewSensorMonitor(sensor sensors.Sensor, pollingRate time.Duration) SensorMonitor {
-	return sensorMonitor{
-		sensor:      sensor,
-		pollingRate: pollingRate,
+	config := sensor.GetConfig()
+	faultDetection := config.FaultDetection
+	if !isTemperatureSensor(config) {
+		// rates of change, utilization, power, etc. can be any value (and stay at 0 for a long time),
+		// so only failed reads indicate a fault
+		faultDetection.InvalidValues = &[]float64{}
+		faultDetection.MaxUnchangedDuration = 0
 	}
+	return &sensorMonitor{
+		sensor:         sensor,
+		pollingRate:    pollingRate,
+		filter:         sensors.NewFilter(config.Filter, configuration.CurrentConfig.TempRollingWindowSize),
+		faultDetector:  newSensorFaultDetector(faultDetection),
+		resumeDetector: util.NewResumeDetector(),
+	}
+}
+
+// isTemperatureSensor returns whether the given sensor reports a temperature
+func isTemperatureSensor(config configuration.SensorConfig) bool {
+	return config.GetUnit() == configuration.SensorUnitCelsius
 }
 
-func (s sensorMonitor) Run(ctx context.Context) error {
+func (s *sensorMonitor) Run(ctx context.Context) error {
 	tick := time.NewTicker(s.pollingRate)
 
-	err := updateSensor(s.sensor)
-	if err != nil {
-		ui.Warning("Error updating sensor: %v", err)
-	}
+	s.update()
 
 	for {
 		select {
 		case <-ctx.Done():
 			ui.Info("Stopping sensor monitor for sensor %s...", s.sensor.GetId())
+			// stop background processes of the sensor, like the command of a streaming cmd sensor
+			if closer, ok := s.sensor.(io.Closer); ok {
+				_ = closer.Close()
+			}
 			return nil
 		case <-tick.C:
-			err := updateSensor(s.sensor)
-			if err != nil {
-				ui.Warning("Error updating sensor: %v", err)
+			if suspended, resumed := s.resumeDetector.Check(); resumed {
+				s.resume(suspended)
+			} else {
+				s.update()
 			}
 		}
 	}
 }
 
-// read the current value of a sensors and append it to the moving window
-func updateSensor(s sensors.Sensor) (err error) {
-	value, err := s.GetValue()
+// update reads the current value of the sensor and updates both its moving average and its fault state
+func (s *sensorMonitor) update() {
+	value, err := sensors.SampleAndReadValue(s.sensor)
+	s.process(value, err)
+}
+
+// process updates the moving average and the fault state of the sensor with the given reading
+func (s *sensorMonitor) process(value float64, err error) {
 	if err != nil {
-		return err
+		ui.Warning("Error updating sensor: %v", err)
+		s.rebindIfDeviceGone(err)
 	}
 
-	var n = configuration.CurrentConfig.TempRollingWindowSize
-	lastAvg := s.GetMovingAvg()
-	newAvg := util.UpdateSimpleMovingAvg(lastAvg, n, value)
-	s.SetMovingAvg(newAvg)
+	// while a disk is in standby, its value is the configured standbyValue (or the last temperature
+	// read before the standby), which must not be mistaken for an invalid or stuck reading
+	standby := err == nil && s.isInStandby()
+	var reason string
+	if standby {
+		s.faultDetector.standby()
+	} else {
+		reason = s.faultDetector.check(value, err, time.Now())
+	}
+	if err == nil && (standby || !s.faultDetector.isInvalidValue(value)) {
+		lastAvg := s.sensor.GetMovingAvg()
+		s.sensor.SetMovingAvg(s.filter.Apply(lastAvg, value))
+	}
+
+	if len(reason) > 0 && !s.sensor.IsFaulted() {
+		s.sensor.SetFault(reason)
+		ui.ErrorAndNotify("Sensor Fault", "Sensor %s is faulted: %s", s.sensor.GetId(), reason)
+	} else if len(reason) <= 0 && s.sensor.IsFaulted() {
+		s.sensor.SetFault("")
+		ui.Info("Sensor %s recovered", s.sensor.GetId())
+	}
+}
+
+// resume reads the sensor again after the system resumed from suspend. Readings from before the suspend
+// are outdated, so the filter is reset and the moving average starts over at the current value.
+func (s *sensorMonitor) resume(suspended time.Duration) {
+	ui.Info("Sensor %s: system resumed after %s, re-reading sensor", s.sensor.GetId(), suspended.Round(time.Second))
+	s.filter = sensors.NewFilter(s.sensor.GetConfig().Filter, configuration.CurrentConfig.TempRollingWindowSize)
+
+	value, err := sensors.SampleAndReadValue(s.sensor)
+	if err == nil && (s.isInStandby() || !s.faultDetector.isInvalidValue(value)) {
+		s.sensor.SetMovingAvg(value)
+	}
+	s.process(value, err)
+}
+
+// isInStandby returns true if the sensor is a disk, which was in standby during the last read
+func (s *sensorMonitor) isInStandby() bool {
+	sensor, ok := s.sensor.(*sensors.DiskSensor)
+	return ok && sensor.IsInStandby()
+}
+
+// rebindIfDeviceGone resolves the input of a hwmon sensor again, if the given error indicates that its
+// hwmon device disappeared, f.ex. after a driver reload or a resume that renumbered the hwmon devices
+func (s *sensorMonitor) rebindIfDeviceGone(err error) {
+	sensor, ok := s.sensor.(*sensors.HwmonSensor)
+	if !ok || !hwmon.IsDeviceGoneError(err) {
+		return
+	}
+	changed, err := hwmon.RebindSensor(sensor)
+	if err != nil {
+		ui.Warning("Unable to rebind sensor %s: %v", sensor.GetId(), err)
+		return
+	}
+	if changed {
+		ui.Info("Sensor %s: hwmon device changed, now reading %s", sensor.GetId(), sensor.GetInput())
+	}
+}
 
-	return nil
+// sensorFaultDetector keeps track of the readings of a single sensor to decide whether it is faulted
+type sensorFaultDetector struct {
+	config configuration.SensorFaultDetectionConfig
+
+	consecutiveErrors int
+	hasValue          bool
+	lastValue         float64
+	lastValueChange   time.Time
+}
+
+func newSensorFaultDetector(config configuration.SensorFaultDetectionConfig) *sensorFaultDetector {
+	return &sensorFaultDetector{
+		config: config,
+	}
+}
+
+// check processes the result of a single sensor reading and returns the reason why the sensor
+// should be considered faulted, or an empty string if it is healthy
+func (d *sensorFaultDetector) check(value float64, err error, now time.Time) string {
+	if !d.config.Enabled.Get() {
+		return ""
+	}
+
+	if err != nil {
+		d.consecutiveErrors++
+		if d.consecutiveErrors >= max(d.config.MaxConsecutiveErrors, 1) {
+			return fmt.Sprintf("%d consecutive read errors, last error: %v", d.consecutiveErrors, err)
+		}
+		return ""
+	}
+	d.consecutiveErrors = 0
+
+	if d.isInvalidValue(value) {
+		return fmt.Sprintf("sensor reported invalid value %.3f", value/1000)
+	}
+
+	if !d.hasValue || value != d.lastValue {
+		d.hasValue = true
+		d.lastValue = value
+		d.lastValueChange = now
+		return ""
+	}
+
+	if d.config.MaxUnchangedDuration > 0 {
+		unchangedDuration := now.Sub(d.lastValueChange)
+		if unchangedDuration >= d.config.MaxUnchangedDuration {
+			return fmt.Sprintf("sensor value %.3f did not change for %s", value/1000, unchangedDuration.Round(time.Second))
+		}
+	}
+
+	return ""
+}
+
+// standby resets the state of the detector while the sensor doesn't report actual readings,
+// so the stuck value check starts over once it does again
+func (d *sensorFaultDetector) standby() {
+	d.consecutiveErrors = 0
+	d.hasValue = false
+}
+
+// isInvalidValue returns true if the given value (in milli-degrees) is one of the configured invalid values
+func (d *sensorFaultDetector) isInvalidValue(value float64) bool {
+	if !d.config.Enabled.Get() {
+		return false
+	}
+	for _, invalidValue := range d.config.GetInvalidValues() {
+		if math.Abs(value-invalidValue*1000) < 0.5 {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/stretchr/testify/assert"
+)
+
+func createFaultDetectionConfig() configuration.SensorFaultDetectionConfig {
+	return configuration.SensorFaultDetectionConfig{
+		MaxConsecutiveErrors: 3,
+		MaxUnchangedDuration: 1 * time.Minute,
+	}
+}
+
+func TestSensorFaultDetector_HealthyValues(t *testing.T) {
+	// GIVEN
+	detector := newSensorFaultDetector(createFaultDetectionConfig())
+	now := time.Now()
+
+	// WHEN
+	first := detector.check(40000, nil, now)
+	second := detector.check(41000, nil, now.Add(time.Second))
+
+	// THEN
+	assert.Empty(t, first)
+	assert.Empty(t, second)
+}
+
+func TestSensorFaultDetector_ConsecutiveErrors(t *testing.T) {
+	// GIVEN
+	detector := newSensorFaultDetector(createFaultDetectionConfig())
+	now := time.Now()
+	readErr := errors.New("read failed")
+
+	// WHEN
+	first := detector.check(0, readErr, now)
+	second := detector.check(0, readErr, now)
+	third := detector.check(0, readErr, now)
+	recovered := detector.check(40000, nil, now)
+
+	// THEN
+	assert.Empty(t, first)
+	assert.Empty(t, second)
+	assert.Equal(t, "3 consecutive read errors, last error: read failed", third)
+	assert.Empty(t, recovered)
+}
+
+func TestSensorFaultDetector_ErrorCounterResetsOnSuccess(t *testing.T) {
+	// GIVEN
+	detector := newSensorFaultDetector(createFaultDetectionConfig())
+	now := time.Now()
+	readErr := errors.New("read failed")
+
+	// WHEN
+	detector.check(0, readErr, now)
+	detector.check(0, readErr, now)
+	detector.check(40000, nil, now)
+	result := detector.check(0, readErr, now)
+
+	// THEN
+	assert.Empty(t, result)
+}
+
+func TestSensorFaultDetector_InvalidValue(t *testing.T) {
+	// GIVEN
+	detector := newSensorFaultDetector(createFaultDetectionConfig())
+	now := time.Now()
+
+	// WHEN
+	result := detector.check(127000, nil, now)
+
+	// THEN
+	assert.Equal(t, "sensor reported invalid value 127.000", result)
+	assert.True(t, detector.isInvalidValue(-273000))
+	assert.False(t, detector.isInvalidValue(45000))
+}
+
+func TestSensorFaultDetector_InvalidValuesDisabled(t *testing.T) {
+	// GIVEN
+	config := createFaultDetectionConfig()
+	config.InvalidValues = &[]float64{}
+	detector := newSensorFaultDetector(config)
+	now := time.Now()
+
+	// WHEN
+	result := detector.check(127000, nil, now)
+
+	// THEN
+	assert.Empty(t, result)
+	assert.False(t, detector.isInvalidValue(0))
+}
+
+func TestSensorFaultDetector_CustomInvalidValues(t *testing.T) {
+	// GIVEN
+	config := createFaultDetectionConfig()
+	config.InvalidValues = &[]float64{-40}
+	detector := newSensorFaultDetector(config)
+
+	// THEN
+	assert.True(t, detector.isInvalidValue(-40000))
+	assert.False(t, detector.isInvalidValue(127000))
+}
+
+func TestSensorFaultDetector_StuckValue(t *testing.T) {
+	// GIVEN
+	detector := newSensorFaultDetector(createFaultDetectionConfig())
+	now := time.Now()
+
+	// WHEN
+	first := detector.check(40000, nil, now)
+	notYetStuck := detector.check(40000, nil, now.Add(30*time.Second))
+	stuck := detector.check(40000, nil, now.Add(1*time.Minute))
+	changed := detector.check(41000, nil, now.Add(61*time.Second))
+
+	// THEN
+	assert.Empty(t, first)
+	assert.Empty(t, notYetStuck)
+	assert.Equal(t, "sensor value 40.000 did not change for 1m0s", stuck)
+	assert.Empty(t, changed)
+}
+
+func TestSensorFaultDetector_StandbyRestartsStuckValueCheck(t *testing.T) {
+	// GIVEN
+	detector := newSensorFaultDetector(createFaultDetectionConfig())
+	now := time.Now()
+
+	// WHEN
+	detector.check(40000, nil, now)
+	// the disk is in standby for a while
+	detector.standby()
+	// and reports the same temperature after waking up
+	afterStandby := detector.check(40000, nil, now.Add(1*time.Minute))
+	stuck := detector.check(40000, nil, now.Add(2*time.Minute))
+
+	// THEN
+	assert.Empty(t, afterStandby)
+	assert.Equal(t, "sensor value 40.000 did not change for 1m0s", stuck)
+}
+
+func TestSensorFaultDetector_StuckValueDisabled(t *testing.T) {
+	// GIVEN
+	config := createFaultDetectionConfig()
+	config.MaxUnchangedDuration = 0
+	detector := newSensorFaultDetector(config)
+	now := time.Now()
+
+	// WHEN
+	detector.check(40000, nil, now)
+	result := detector.check(40000, nil, now.Add(24*time.Hour))
+
+	// THEN
+	assert.Empty(t, result)
+}
+
+func TestSensorFaultDetector_Disabled(t *testing.T) {
+	// GIVEN
+	config := createFaultDetectionConfig()
+	config.Enabled = configuration.DefaultTrueBool{
+		Optional: configuration.Optional[bool]{Value: false, Present: true},
+	}
+	detector := newSensorFaultDetector(config)
+	now := time.Now()
+	readErr := errors.New("read failed")
+
+	// WHEN
+	detector.check(0, readErr, now)
+	detector.check(0, readErr, now)
+	errorResult := detector.check(0, readErr, now)
+	invalidResult := detector.check(127000, nil, now)
+
+	// THEN
+	assert.Empty(t, errorResult)
+	assert.Empty(t, invalidResult)
+	assert.False(t, detector.isInvalidValue(127000))
+}
+
+// samplingSensor reports the number of samples taken so far
+type samplingSensor struct {
+	sensors.FileSensor
+	samples int
+}
+
+func (s *samplingSensor) Sample() error {
+	s.samples++
+	return nil
+}
+
+func (s *samplingSensor) GetValue() (float64, error) {
+	return float64(s.samples), nil
+}
+
+func TestSensorMonitor_SamplesOncePerUpdate(t *testing.T) {
+	// GIVEN
+	sensor := &samplingSensor{
+		FileSensor: sensors.FileSensor{Config: configuration.SensorConfig{ID: "sensor", CpuUsage: &configuration.CpuUsageSensorConfig{}}},
+	}
+	monitor := NewSensorMonitor(sensor, time.Second).(*sensorMonitor)
+
+	// WHEN
+	monitor.update()
+	monitor.resume(time.Hour)
+
+	// THEN
+	assert.Equal(t, 2, sensor.samples)
+	// the moving average starts over at the value after the resume
+	assert.Equal(t, 2.0, sensor.GetMovingAvg())
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/systemd"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+// startSystemdNotifier notifies systemd once all fan controllers completed their first cycle (or failed and are
+// restarted by their supervisor), and sends watchdog pings as long as all of them keep completing cycles.
+// Does nothing, if fan2go wasn't started by systemd with Type=notify.
+func startSystemdNotifier(ctx context.Context, fanControllers map[fans.Fan]controller.FanController, wg *sync.WaitGroup) {
+	sent, err := systemd.Notify(systemd.Status("Initializing fan controllers..."))
+	if err != nil {
+		ui.Warning("Unable to notify systemd: %v", err)
+		return
+	}
+	if !sent {
+		return
+	}
+
+	watchdogInterval, err := systemd.WatchdogInterval()
+	if err != nil {
+		ui.Warning("Invalid systemd watchdog interval: %v", err)
+	}
+	pollingRate := 1 * time.Second
+	if watchdogInterval > 0 {
+		// ping at least twice per interval
+		pollingRate = min(pollingRate, watchdogInterval/2)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tick := time.NewTicker(pollingRate)
+		defer tick.Stop()
+
+		ready := false
+		lastStatus := ""
+		lastStalledFan := ""
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+			}
+
+			cycles := checkFanControllerCycles(fanControllers, watchdogInterval)
+			status := cycles.status(len(fanControllers))
+			state := systemd.Status(status)
+			notify := status != lastStatus
+			if !ready && cycles.started {
+				ready = true
+				state = systemd.StateReady + "\n" + state
+				notify = true
+			}
+			if notify {
+				lastStatus = status
+				_, err = systemd.Notify(state)
+				if err != nil {
+					ui.Warning("Unable to notify systemd: %v", err)
+				}
+			}
+			if !ready || watchdogInterval <= 0 {
+				continue
+			}
+
+			if cycles.stalledFan != lastStalledFan && cycles.stalledFan != "" {
+				ui.Warning("Fan controller %s didn't complete a cycle within %s, skipping systemd watchdog pings", cycles.stalledFan, watchdogInterval)
+			}
+			lastStalledFan = cycles.stalledFan
+			if cycles.stalledFan == "" {
+				_, err = systemd.Notify(systemd.StateWatchdog)
+				if err != nil {
+					ui.Warning("Unable to notify systemd watchdog: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// fanControllerCycles is the progress of all fan controllers, as reported to systemd
+type fanControllerCycles struct {
+	// true if all fan controllers completed their first cycle or are restarted by their supervisor
+	started bool
+	// the id of a fan whose controller didn't complete a cycle (within the watchdog interval)
+	stalledFan string
+	// the ids of fans whose controller didn't complete its first cycle yet
+	waitingFans []string
+	// the ids of fans whose controller failed and didn't complete a cycle since it was restarted
+	restartingFans []string
+}
+
+// status returns the status text of the service
+func (c fanControllerCycles) status(fanCount int) string {
+	if !c.started {
+		return fmt.Sprintf("Initializing fan controllers, waiting for: %s", strings.Join(c.waitingFans, ", "))
+	}
+	status := fmt.Sprintf("Controlling %d fans", fanCount)
+	if len(c.restartingFans) > 0 {
+		status += fmt.Sprintf(", restarting failed controllers of: %s", strings.Join(c.restartingFans, ", "))
+	}
+	return status
+}
+
+// checkFanControllerCycles checks whether all fan controllers completed their first cycle,
+// and whether one of them didn't complete a cycle within maxAge (if maxAge > 0).
+// Controllers that failed are ignored until their supervisor restarted them successfully,
+// since they don't complete cycles during the restart backoff.
+func checkFanControllerCycles(fanControllers map[fans.Fan]controller.FanController, maxAge time.Duration) fanControllerCycles {
+	result := fanControllerCycles{started: true}
+	for fan, fanController := range fanControllers {
+		if fanController.IsRestarting() {
+			result.restartingFans = append(result.restartingFans, fan.GetId())
+			continue
+		}
+		lastCycleTime := fanController.GetLastCycleTime()
+		if lastCycleTime.IsZero() {
+			result.started = false
+			result.stalledFan = fan.GetId()
+			result.waitingFans = append(result.waitingFans, fan.GetId())
+		} else if maxAge > 0 && time.Since(lastCycleTime) >= maxAge {
+			result.stalledFan = fan.GetId()
+		}
+	}
+	slices.Sort(result.waitingFans)
+	slices.Sort(result.restartingFans)
+	return result
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockFanController struct {
+	controller.FanController
+	lastCycleTime atomic.Int64
+	restarting    atomic.Bool
+}
+
+func (c *mockFanController) IsRestarting() bool {
+	return c.restarting.Load()
+}
+
+func (c *mockFanController) GetLastCycleTime() time.Time {
+	lastCycleTime := c.lastCycleTime.Load()
+	if lastCycleTime == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, lastCycleTime)
+}
+
+func readNotifications(t *testing.T, conn *net.UnixConn, timeout time.Duration) []string {
+	var messages []string
+	buf := make([]byte, 256)
+	deadline := time.Now().Add(timeout)
+	for {
+		_ = conn.SetReadDeadline(deadline)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return messages
+		}
+		messages = append(messages, string(buf[:n]))
+	}
+}
+
+func TestSystemdNotifier(t *testing.T) {
+	// GIVEN
+	socketPath := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+	t.Setenv("NOTIFY_SOCKET", socketPath)
+	t.Setenv("WATCHDOG_USEC", "100000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	fanController := &mockFanController{}
+	fanControllers := map[fans.Fan]controller.FanController{
+		&fans.FileFan{Config: configuration.FanConfig{ID: "cpu"}}: fanController,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	// WHEN
+	startSystemdNotifier(ctx, fanControllers, &wg)
+	initializing := readNotifications(t, conn, 200*time.Millisecond)
+
+	fanController.lastCycleTime.Store(time.Now().UnixNano())
+	running := readNotifications(t, conn, 80*time.Millisecond)
+
+	// the controller stops completing cycles
+	time.Sleep(100 * time.Millisecond)
+	_ = readNotifications(t, conn, 10*time.Millisecond)
+	stalled := readNotifications(t, conn, 200*time.Millisecond)
+
+	cancel()
+	wg.Wait()
+
+	// THEN
+	assert.Equal(t, []string{"STATUS=Initializing fan controllers...", "STATUS=Initializing fan controllers, waiting for: cpu"}, initializing)
+	require.NotEmpty(t, running)
+	assert.Equal(t, "READY=1\nSTATUS=Controlling 1 fans", running[0])
+	assert.Contains(t, running[1:], "WATCHDOG=1")
+	assert.Empty(t, stalled)
+}
+
+func TestSystemdNotifier_IgnoresRestartingControllers(t *testing.T) {
+	// GIVEN
+	socketPath := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+	t.Setenv("NOTIFY_SOCKET", socketPath)
+	t.Setenv("WATCHDOG_USEC", "100000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	cpuController := &mockFanController{}
+	cpuController.lastCycleTime.Store(time.Now().UnixNano())
+	// the controller failed before completing its first cycle and waits to be restarted
+	gpuController := &mockFanController{}
+	gpuController.restarting.Store(true)
+	fanControllers := map[fans.Fan]controller.FanController{
+		&fans.FileFan{Config: configuration.FanConfig{ID: "cpu"}}: cpuController,
+		&fans.FileFan{Config: configuration.FanConfig{ID: "gpu"}}: gpuController,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	// WHEN
+	startSystemdNotifier(ctx, fanControllers, &wg)
+	go func() {
+		for ctx.Err() == nil {
+			cpuController.lastCycleTime.Store(time.Now().UnixNano())
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	messages := readNotifications(t, conn, 200*time.Millisecond)
+
+	cancel()
+	wg.Wait()
+
+	// THEN
+	require.Greater(t, len(messages), 2)
+	assert.Equal(t, "STATUS=Initializing fan controllers...", messages[0])
+	assert.Equal(t, "READY=1\nSTATUS=Controlling 2 fans, restarting failed controllers of: gpu", messages[1])
+	for _, message := range messages[2:] {
+		assert.Equal(t, "WATCHDOG=1", message)
+	}
+}
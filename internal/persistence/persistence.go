@@ -17,8 +17,50 @@ const (
 	BucketFans                 = "fans"
 	BucketFanPwmMap            = "fanPwmMapping"
 	BucketFanSetPwmToSetPwmMap = "fanSetPwmToGetPwmMap"
+	BucketFanOriginalState     = "fanOriginalState"
+	BucketFanMinPwmOffset      = "fanMinPwmOffset"
+	BucketFanRpmDataReference  = "fanRpmDataReference"
+
+	// maxMinPwmOffsetHistory is the maximum number of changes kept in the history of a MinPwmOffsetState
+	maxMinPwmOffsetHistory = 50
 )
 
+// FanStateSnapshot is the state of a fan before fan2go took control of it
+type FanStateSnapshot struct {
+	// the ControlMode the fan was in before the controller started
+	ControlMode fans.ControlMode `json:"controlMode"`
+	// the raw pwm value read from the fan before the controller started
+	// Note: this is the raw value, no pwmMap is applied to it
+	PwmValue int `json:"pwmValue"`
+	// the state of each member of a fan group by fan id, since members may be in different states
+	Members map[string]FanStateSnapshot `json:"members,omitempty"`
+}
+
+// MinPwmOffsetChange is a single change of the learned minPwm offset of a fan
+type MinPwmOffsetChange struct {
+	Time time.Time `json:"time"`
+	// the offset after the change
+	Offset int `json:"offset"`
+	// why the offset changed, f.ex. because the fan stalled
+	Reason string `json:"reason"`
+}
+
+// MinPwmOffsetState is the offset learned for the minPwm of a fan that should never stop
+type MinPwmOffsetState struct {
+	Offset  int                  `json:"offset"`
+	History []MinPwmOffsetChange `json:"history"`
+}
+
+// AddChange sets the offset of this state and records the change in its history,
+// dropping the oldest changes if the history is full
+func (s *MinPwmOffsetState) AddChange(at time.Time, offset int, reason string) {
+	s.Offset = offset
+	s.History = append(s.History, MinPwmOffsetChange{Time: at, Offset: offset, Reason: reason})
+	if len(s.History) > maxMinPwmOffsetHistory {
+		s.History = s.History[len(s.History)-maxMinPwmOffsetHistory:]
+	}
+}
+
 type Persistence interface {
 	Init() error
 
@@ -35,6 +77,22 @@ type Persistence interface {
 	// pwmMapping must have exactly 256 elements (it's an array mapping PWM i to pwmMapping[i] for PWMs 0-255)
 	SaveFanPwmMap(fanId string, pwmMapping []int) (err error)
 	DeleteFanPwmMap(fanId string) (err error)
+
+	// the original state is persisted while fan2go controls a fan, so it can be restored after an unclean shutdown
+	LoadFanOriginalState(fanId string) (*FanStateSnapshot, error)
+	SaveFanOriginalState(fanId string, state FanStateSnapshot) (err error)
+	DeleteFanOriginalState(fanId string) (err error)
+
+	// the minPwm offset learned for fans that should never stop, including the history of its changes
+	LoadFanMinPwmOffset(fanId string) (*MinPwmOffsetState, error)
+	SaveFanMinPwmOffset(fanId string, state MinPwmOffsetState) (err error)
+	DeleteFanMinPwmOffset(fanId string) (err error)
+
+	// the fan curve data as it was initially measured, used as a reference to detect drift
+	// while the stored fan curve data is refined during normal operation
+	LoadFanRpmDataReference(fanId string) (map[int]float64, error)
+	SaveFanRpmDataReference(fanId string, curveData map[int]float64) (err error)
+	DeleteFanRpmDataReference(fanId string) (err error)
 }
 
 type persistence struct {
@@ -384,3 +442,291 @@ func (p persistence) DeleteFanPwmMap(fanId string) error {
 		return b.Delete([]byte(key))
 	})
 }
+
+// SaveFanOriginalState saves the state of the given fan before fan2go took control of it to persistence
+func (p persistence) SaveFanOriginalState(fanId string, state FanStateSnapshot) (err error) {
+	db, err := p.openPersistence()
+	if err != nil {
+		return err
+	}
+	defer func(db *bolt.DB) {
+		_ = db.Close()
+	}(db)
+
+	key := fanId
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(BucketFanOriginalState))
+		if err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		err = b.Put([]byte(key), data)
+		return err
+	})
+}
+
+// LoadFanOriginalState loads the state of the given fan before fan2go took control of it from persistence.
+// Returns os.ErrNotExist, if fan2go was shut down cleanly since.
+func (p persistence) LoadFanOriginalState(fanId string) (*FanStateSnapshot, error) {
+	db, err := p.openPersistence()
+	if err != nil {
+		return nil, err
+	}
+	defer func(db *bolt.DB) {
+		_ = db.Close()
+	}(db)
+
+	key := fanId
+
+	var state *FanStateSnapshot
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketFanOriginalState))
+		if b == nil {
+			return os.ErrNotExist
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return os.ErrNotExist
+		}
+
+		err := json.Unmarshal(v, &state)
+		if err != nil {
+			// if we cannot read the saved data, delete it
+			ui.Warning("Unable to unmarshal saved original state for %s: %v", key, err)
+			err := b.Delete([]byte(key))
+			if err != nil {
+				ui.Error("Unable to delete corrupt data key %s: %v", key, err)
+			}
+			return os.ErrNotExist
+		}
+
+		return nil
+	})
+
+	return state, err
+}
+
+// DeleteFanOriginalState deletes the state of the given fan before fan2go took control of it from persistence
+func (p persistence) DeleteFanOriginalState(fanId string) error {
+	db, err := p.openPersistence()
+	if err != nil {
+		return err
+	}
+	defer func(db *bolt.DB) {
+		_ = db.Close()
+	}(db)
+
+	key := fanId
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketFanOriginalState))
+		if b == nil {
+			// no bucket yet
+			return nil
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			// no data for given key
+			return nil
+		}
+
+		return b.Delete([]byte(key))
+	})
+}
+
+// SaveFanMinPwmOffset saves the learned minPwm offset of the given fan to persistence
+func (p persistence) SaveFanMinPwmOffset(fanId string, state MinPwmOffsetState) (err error) {
+	db, err := p.openPersistence()
+	if err != nil {
+		return err
+	}
+	defer func(db *bolt.DB) {
+		_ = db.Close()
+	}(db)
+
+	key := fanId
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(BucketFanMinPwmOffset))
+		if err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		err = b.Put([]byte(key), data)
+		return err
+	})
+}
+
+// LoadFanMinPwmOffset loads the learned minPwm offset of the given fan from persistence.
+// Returns os.ErrNotExist, if no offset was learned yet.
+func (p persistence) LoadFanMinPwmOffset(fanId string) (*MinPwmOffsetState, error) {
+	db, err := p.openPersistence()
+	if err != nil {
+		return nil, err
+	}
+	defer func(db *bolt.DB) {
+		_ = db.Close()
+	}(db)
+
+	key := fanId
+
+	var state *MinPwmOffsetState
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketFanMinPwmOffset))
+		if b == nil {
+			return os.ErrNotExist
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return os.ErrNotExist
+		}
+
+		err := json.Unmarshal(v, &state)
+		if err != nil {
+			// if we cannot read the saved data, delete it
+			ui.Warning("Unable to unmarshal saved minPwm offset for %s: %v", key, err)
+			err := b.Delete([]byte(key))
+			if err != nil {
+				ui.Error("Unable to delete corrupt data key %s: %v", key, err)
+			}
+			return os.ErrNotExist
+		}
+
+		return nil
+	})
+
+	return state, err
+}
+
+// DeleteFanMinPwmOffset deletes the learned minPwm offset of the given fan from persistence
+func (p persistence) DeleteFanMinPwmOffset(fanId string) error {
+	db, err := p.openPersistence()
+	if err != nil {
+		return err
+	}
+	defer func(db *bolt.DB) {
+		_ = db.Close()
+	}(db)
+
+	key := fanId
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketFanMinPwmOffset))
+		if b == nil {
+			// no bucket yet
+			return nil
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			// no data for given key
+			return nil
+		}
+
+		return b.Delete([]byte(key))
+	})
+}
+
+// SaveFanRpmDataReference saves the initially measured fan curve data of the given fan to persistence
+func (p persistence) SaveFanRpmDataReference(fanId string, curveData map[int]float64) (err error) {
+	db, err := p.openPersistence()
+	if err != nil {
+		return err
+	}
+	defer func(db *bolt.DB) {
+		_ = db.Close()
+	}(db)
+
+	key := fanId
+
+	data, err := json.Marshal(curveData)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(BucketFanRpmDataReference))
+		if err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		err = b.Put([]byte(key), data)
+		return err
+	})
+}
+
+// LoadFanRpmDataReference loads the initially measured fan curve data of the given fan from persistence.
+// Returns os.ErrNotExist, if no reference was saved yet.
+func (p persistence) LoadFanRpmDataReference(fanId string) (map[int]float64, error) {
+	db, err := p.openPersistence()
+	if err != nil {
+		return nil, err
+	}
+	defer func(db *bolt.DB) {
+		_ = db.Close()
+	}(db)
+
+	key := fanId
+
+	var curveData map[int]float64
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketFanRpmDataReference))
+		if b == nil {
+			return os.ErrNotExist
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return os.ErrNotExist
+		}
+
+		err := json.Unmarshal(v, &curveData)
+		if err != nil {
+			// if we cannot read the saved data, delete it
+			ui.Warning("Unable to unmarshal saved fan curve reference for %s: %v", key, err)
+			err := b.Delete([]byte(key))
+			if err != nil {
+				ui.Error("Unable to delete corrupt data key %s: %v", key, err)
+			}
+			return os.ErrNotExist
+		}
+
+		return nil
+	})
+
+	return curveData, err
+}
+
+// DeleteFanRpmDataReference deletes the initially measured fan curve data of the given fan from persistence
+func (p persistence) DeleteFanRpmDataReference(fanId string) error {
+	db, err := p.openPersistence()
+	if err != nil {
+		return err
+	}
+	defer func(db *bolt.DB) {
+		_ = db.Close()
+	}(db)
+
+	key := fanId
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketFanRpmDataReference))
+		if b == nil {
+			// no bucket yet
+			return nil
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			// no data for given key
+			return nil
+		}
+
+		return b.Delete([]byte(key))
+	})
+}
@@ -3,6 +3,7 @@ package persistence
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/fans"
@@ -213,3 +214,79 @@ func createFan(neverStop bool, curveData map[int]float64) (fan fans.Fan, err err
 
 	return fan, err
 }
+
+func TestPersistence_FanOriginalState(t *testing.T) {
+	// GIVEN
+	p := NewPersistence(dbTestingPath)
+	state := FanStateSnapshot{ControlMode: fans.ControlModeAutomatic, PwmValue: 128}
+
+	// WHEN
+	err := p.SaveFanOriginalState("fan1", state)
+	loaded, loadErr := p.LoadFanOriginalState("fan1")
+	deleteErr := p.DeleteFanOriginalState("fan1")
+	_, missingErr := p.LoadFanOriginalState("fan1")
+
+	// THEN
+	assert.NoError(t, err)
+	assert.NoError(t, loadErr)
+	assert.Equal(t, state, *loaded)
+	assert.NoError(t, deleteErr)
+	assert.ErrorIs(t, missingErr, os.ErrNotExist)
+}
+
+func TestPersistence_FanMinPwmOffset(t *testing.T) {
+	// GIVEN
+	p := NewPersistence(dbTestingPath)
+	state := MinPwmOffsetState{}
+	state.AddChange(time.Unix(1000, 0).UTC(), 1, "stall")
+	state.AddChange(time.Unix(2000, 0).UTC(), 0, "reprobe")
+
+	// WHEN
+	err := p.SaveFanMinPwmOffset("fan1", state)
+	loaded, loadErr := p.LoadFanMinPwmOffset("fan1")
+	deleteErr := p.DeleteFanMinPwmOffset("fan1")
+	_, missingErr := p.LoadFanMinPwmOffset("fan1")
+
+	// THEN
+	assert.NoError(t, err)
+	assert.NoError(t, loadErr)
+	assert.Equal(t, state, *loaded)
+	assert.Equal(t, 0, loaded.Offset)
+	assert.Len(t, loaded.History, 2)
+	assert.NoError(t, deleteErr)
+	assert.ErrorIs(t, missingErr, os.ErrNotExist)
+}
+
+func TestPersistence_FanRpmDataReference(t *testing.T) {
+	// GIVEN
+	p := NewPersistence(dbTestingPath)
+	curveData := map[int]float64{0: 0, 50: 400, 255: 2000}
+
+	// WHEN
+	err := p.SaveFanRpmDataReference("fan1", curveData)
+	loaded, loadErr := p.LoadFanRpmDataReference("fan1")
+	deleteErr := p.DeleteFanRpmDataReference("fan1")
+	_, missingErr := p.LoadFanRpmDataReference("fan1")
+
+	// THEN
+	assert.NoError(t, err)
+	assert.NoError(t, loadErr)
+	assert.Equal(t, curveData, loaded)
+	assert.NoError(t, deleteErr)
+	assert.ErrorIs(t, missingErr, os.ErrNotExist)
+}
+
+func TestMinPwmOffsetState_AddChange_LimitsHistory(t *testing.T) {
+	// GIVEN
+	state := MinPwmOffsetState{}
+
+	// WHEN
+	for i := 1; i <= maxMinPwmOffsetHistory+5; i++ {
+		state.AddChange(time.Now(), i, "stall")
+	}
+
+	// THEN
+	assert.Equal(t, maxMinPwmOffsetHistory+5, state.Offset)
+	assert.Len(t, state.History, maxMinPwmOffsetHistory)
+	assert.Equal(t, 6, state.History[0].Offset)
+}
@@ -41,6 +41,9 @@ func (r *Registry) SnapshotFans() map[string]fans.Fan {
 
 // Sensors
 func (r *Registry) RegisterSensor(sensor sensors.Sensor) {
+	if binder, ok := sensor.(interface{ BindRegistry(sensors.RegistryReader) }); ok {
+		binder.BindRegistry(r)
+	}
 	r.sensors.Set(sensor.GetId(), sensor)
 }
 
@@ -0,0 +1,205 @@
+package sensors
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+const diskByIdBase = "/dev/disk/by-id"
+
+var partitionSuffixPattern = regexp.MustCompile(`-part\d+$`)
+
+// AggregateSensor combines the values of all devices matched by a wildcard pattern.
+// Devices are discovered when the sensor is created, so newly added devices are picked up on config reload.
+type AggregateSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+	FaultState
+
+	members []Sensor
+	// ids of the members that couldn't be read on the last read, to log changes only once
+	failedMembers map[string]bool
+
+	mu sync.Mutex
+}
+
+// CreateAggregateSensor discovers all devices matched by the given config and creates a sensor for each of them
+func CreateAggregateSensor(config configuration.SensorConfig) (Sensor, error) {
+	var members []Sensor
+	switch config.Aggregate.Source {
+	case configuration.AggregateSourceDisk:
+		devices, err := findDiskDevicesAt(diskByIdBase, config.Aggregate.Match)
+		if err != nil {
+			return nil, fmt.Errorf("sensor %s: %w", config.ID, err)
+		}
+		for _, device := range devices {
+			members = append(members, &DiskSensor{
+				Config: configuration.SensorConfig{
+					ID:   fmt.Sprintf("%s/%s", config.ID, filepath.Base(device)),
+					Disk: &configuration.DiskSensorConfig{Device: device},
+				},
+			})
+		}
+	case configuration.AggregateSourceHwMon:
+		for _, input := range config.Aggregate.HwMonInputs {
+			members = append(members, &HwmonSensor{
+				Input: input,
+				Config: configuration.SensorConfig{
+					ID:    fmt.Sprintf("%s/%s", config.ID, input),
+					HwMon: &configuration.HwMonSensorConfig{TempInput: input},
+				},
+			})
+		}
+	default:
+		return nil, fmt.Errorf("sensor %s: unsupported aggregate source '%s'", config.ID, config.Aggregate.Source)
+	}
+
+	if len(members) <= 0 {
+		return nil, fmt.Errorf("sensor %s: no devices matched the aggregate sensor config", config.ID)
+	}
+
+	return &AggregateSensor{
+		Config:  config,
+		members: members,
+	}, nil
+}
+
+// findDiskDevicesAt returns all entries of byIdBase matching the given glob, excluding partitions
+// and aliases that resolve to an already matched device
+func findDiskDevicesAt(byIdBase string, pattern string) ([]string, error) {
+	entries, err := os.ReadDir(byIdBase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disks in %s: %w", byIdBase, err)
+	}
+
+	var devices []string
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		name := entry.Name()
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid match pattern '%s': %w", pattern, err)
+		}
+		if !matched || partitionSuffixPattern.MatchString(name) {
+			continue
+		}
+
+		device := filepath.Join(byIdBase, name)
+		resolved, err := filepath.EvalSymlinks(device)
+		if err != nil {
+			continue
+		}
+		if seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		devices = append(devices, device)
+	}
+
+	if len(devices) <= 0 {
+		return nil, fmt.Errorf("no disk in %s matches '%s'", byIdBase, pattern)
+	}
+	sort.Strings(devices)
+	return devices, nil
+}
+
+func (s *AggregateSensor) GetId() string {
+	return s.Config.ID
+}
+
+func (s *AggregateSensor) GetLabel() string {
+	return fmt.Sprintf("Aggregate (%s of %d %s sensors)", s.function(), len(s.members), s.Config.Aggregate.Source)
+}
+
+func (s *AggregateSensor) GetConfig() configuration.SensorConfig {
+	return s.Config
+}
+
+// GetMembers returns the sensors of all devices matched by this sensor
+func (s *AggregateSensor) GetMembers() []Sensor {
+	return s.members
+}
+
+// GetValue returns the aggregated value of all members that could be read.
+// It only fails if none of the members could be read.
+func (s *AggregateSensor) GetValue() (float64, error) {
+	var values []float64
+	var lastErr error
+	failed := map[string]error{}
+	for _, member := range s.members {
+		value, err := member.GetValue()
+		if err != nil {
+			lastErr = err
+			failed[member.GetId()] = err
+			continue
+		}
+		values = append(values, value)
+	}
+	s.reportFailedMembers(failed)
+
+	if len(values) <= 0 {
+		return 0, fmt.Errorf("sensor %s: none of the %d aggregated sensors could be read, last error: %w", s.Config.ID, len(s.members), lastErr)
+	}
+
+	switch s.function() {
+	case configuration.AggregateFunctionAverage:
+		return util.Avg(values), nil
+	case configuration.AggregateFunctionMedian:
+		return util.MedianFloat64(values), nil
+	default:
+		return util.MaxValOrElse(values, 0), nil
+	}
+}
+
+// reportFailedMembers logs members that can't be read anymore or recovered, so a value that covers
+// fewer sensors than configured (f.ex. because of a dead CPU die) doesn't go unnoticed.
+// Only changes are logged, not every failed read.
+func (s *AggregateSensor) reportFailedMembers(failed map[string]error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, err := range failed {
+		if !s.failedMembers[id] {
+			ui.Warning("Sensor %s: aggregated sensor %s can't be read, the value only covers %d of %d sensors: %v",
+				s.Config.ID, id, len(s.members)-len(failed), len(s.members), err)
+		}
+	}
+	for id := range s.failedMembers {
+		if _, ok := failed[id]; !ok {
+			ui.Info("Sensor %s: aggregated sensor %s recovered", s.Config.ID, id)
+		}
+	}
+
+	s.failedMembers = map[string]bool{}
+	for id := range failed {
+		s.failedMembers[id] = true
+	}
+}
+
+func (s *AggregateSensor) function() string {
+	if len(s.Config.Aggregate.Function) <= 0 {
+		return configuration.AggregateFunctionMax
+	}
+	return s.Config.Aggregate.Function
+}
+
+func (s *AggregateSensor) GetMovingAvg() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.MovingAvg
+}
+
+func (s *AggregateSensor) SetMovingAvg(avg float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MovingAvg = avg
+}
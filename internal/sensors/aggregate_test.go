@@ -0,0 +1,136 @@
+package sensors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTempInputs creates fake hwmon temperature inputs with the given values and returns their paths
+func createTempInputs(t *testing.T, values ...string) []string {
+	dir := t.TempDir()
+	var inputs []string
+	for i, value := range values {
+		input := filepath.Join(dir, fmt.Sprintf("temp%d_input", i+1))
+		require.NoError(t, os.WriteFile(input, []byte(value+"\n"), 0644))
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+func createHwMonAggregateSensor(t *testing.T, function string, inputs []string) Sensor {
+	sensor, err := CreateAggregateSensor(configuration.SensorConfig{
+		ID: "cores",
+		Aggregate: &configuration.AggregateSensorConfig{
+			Source:      configuration.AggregateSourceHwMon,
+			Platform:    "coretemp-*",
+			Function:    function,
+			HwMonInputs: inputs,
+		},
+	})
+	require.NoError(t, err)
+	return sensor
+}
+
+func TestFindDiskDevicesAt(t *testing.T) {
+	// GIVEN
+	devBase := t.TempDir()
+	byIdBase := t.TempDir()
+	for _, name := range []string{"sda", "sda1", "sdb", "sdc"} {
+		require.NoError(t, os.WriteFile(filepath.Join(devBase, name), nil, 0644))
+	}
+	links := map[string]string{
+		"ata-WDC_WD40EFRX_1":       "sda",
+		"ata-WDC_WD40EFRX_1-part1": "sda1",
+		"ata-WDC_WD40EFRX_2":       "sdb",
+		"wwn-0x50014ee2b5a1c2d3":   "sdb",
+		"ata-ST4000VN008_1":        "sdc",
+	}
+	for name, target := range links {
+		require.NoError(t, os.Symlink(filepath.Join(devBase, target), filepath.Join(byIdBase, name)))
+	}
+
+	// WHEN
+	wdc, errWdc := findDiskDevicesAt(byIdBase, "ata-WDC*")
+	all, errAll := findDiskDevicesAt(byIdBase, "*")
+	_, errNone := findDiskDevicesAt(byIdBase, "nvme-*")
+
+	// THEN
+	require.NoError(t, errWdc)
+	assert.Equal(t, []string{
+		filepath.Join(byIdBase, "ata-WDC_WD40EFRX_1"),
+		filepath.Join(byIdBase, "ata-WDC_WD40EFRX_2"),
+	}, wdc)
+	require.NoError(t, errAll)
+	assert.Len(t, all, 3)
+	assert.ErrorContains(t, errNone, "matches 'nvme-*'")
+}
+
+func TestAggregateSensor_GetValue(t *testing.T) {
+	// GIVEN
+	inputs := createTempInputs(t, "41000", "58000", "44000", "43000")
+
+	// WHEN
+	maxValue, errMax := createHwMonAggregateSensor(t, configuration.AggregateFunctionMax, inputs).GetValue()
+	avgValue, errAvg := createHwMonAggregateSensor(t, configuration.AggregateFunctionAverage, inputs).GetValue()
+	medianValue, errMedian := createHwMonAggregateSensor(t, configuration.AggregateFunctionMedian, inputs).GetValue()
+
+	// THEN
+	require.NoError(t, errMax)
+	assert.Equal(t, 58000.0, maxValue)
+	require.NoError(t, errAvg)
+	assert.Equal(t, 46500.0, avgValue)
+	require.NoError(t, errMedian)
+	assert.Equal(t, 43500.0, medianValue)
+}
+
+func TestAggregateSensor_GetValue_SkipsUnreadableMembers(t *testing.T) {
+	// GIVEN
+	inputs := createTempInputs(t, "41000", "58000")
+	require.NoError(t, os.Remove(inputs[1]))
+	sensor := createHwMonAggregateSensor(t, "", inputs)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 41000.0, value)
+	assert.Equal(t, map[string]bool{"cores/" + inputs[1]: true}, sensor.(*AggregateSensor).failedMembers)
+}
+
+func TestAggregateSensor_GetValue_MemberRecovers(t *testing.T) {
+	// GIVEN
+	inputs := createTempInputs(t, "41000", "58000")
+	require.NoError(t, os.Remove(inputs[1]))
+	sensor := createHwMonAggregateSensor(t, "", inputs)
+	_, err := sensor.GetValue()
+	require.NoError(t, err)
+
+	// WHEN
+	require.NoError(t, os.WriteFile(inputs[1], []byte("58000\n"), 0644))
+	value, err := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 58000.0, value)
+	assert.Empty(t, sensor.(*AggregateSensor).failedMembers)
+}
+
+func TestAggregateSensor_GetValue_AllMembersUnreadable(t *testing.T) {
+	// GIVEN
+	inputs := createTempInputs(t, "41000")
+	require.NoError(t, os.Remove(inputs[0]))
+	sensor := createHwMonAggregateSensor(t, configuration.AggregateFunctionMax, inputs)
+
+	// WHEN
+	_, err := sensor.GetValue()
+
+	// THEN
+	assert.ErrorContains(t, err, "sensor cores: none of the 1 aggregated sensors could be read")
+}
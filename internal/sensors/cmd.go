@@ -14,8 +14,12 @@ type CmdSensor struct {
 	Name      string                     `json:"name"`
 	Config    configuration.SensorConfig `json:"configuration"`
 	MovingAvg float64                    `json:"movingAvg"`
+	FaultState
 
 	mu sync.Mutex
+
+	// stream runs the command in the background, if streaming is enabled
+	stream *cmdStream
 }
 
 func (sensor *CmdSensor) GetId() string {
@@ -31,6 +35,14 @@ func (sensor *CmdSensor) GetConfig() configuration.SensorConfig {
 }
 
 func (sensor *CmdSensor) GetValue() (float64, error) {
+	if sensor.stream != nil {
+		value, err := sensor.stream.get()
+		if err != nil {
+			return 0, fmt.Errorf("sensor %s: %w", sensor.GetId(), err)
+		}
+		return value, nil
+	}
+
 	timeout := 2 * time.Second
 	exec := sensor.Config.Cmd.Exec
 	args := sensor.Config.Cmd.Args
@@ -48,6 +60,19 @@ func (sensor *CmdSensor) GetValue() (float64, error) {
 	return temp, nil
 }
 
+// GetLastValue returns the last value printed by the command of a streaming sensor, without waiting
+// for a value or restarting the command. Other sensors run their command, like GetValue.
+func (sensor *CmdSensor) GetLastValue() (float64, error) {
+	if sensor.stream == nil {
+		return sensor.GetValue()
+	}
+	value, err := sensor.stream.last()
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %w", sensor.GetId(), err)
+	}
+	return value, nil
+}
+
 func (sensor *CmdSensor) GetMovingAvg() (avg float64) {
 	sensor.mu.Lock()
 	defer sensor.mu.Unlock()
@@ -59,3 +84,11 @@ func (sensor *CmdSensor) SetMovingAvg(avg float64) {
 	defer sensor.mu.Unlock()
 	sensor.MovingAvg = avg
 }
+
+// Close stops the background command of a streaming sensor, it is restarted on the next read
+func (sensor *CmdSensor) Close() error {
+	if sensor.stream != nil {
+		sensor.stream.stop()
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package sensors
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// cmdStream runs a long-running command in the background and keeps track of the last value it printed.
+// The command is restarted with an exponential backoff whenever it exits.
+type cmdStream struct {
+	exec   string
+	args   []string
+	config configuration.CmdStreamingConfig
+
+	mu         sync.Mutex
+	cancel     context.CancelFunc
+	startTime  time.Time
+	value      float64
+	lastUpdate time.Time
+	// firstValue is closed once the first value was received after starting the command
+	firstValue chan struct{}
+}
+
+func newCmdStream(exec string, args []string, config configuration.CmdStreamingConfig) *cmdStream {
+	return &cmdStream{
+		exec:   exec,
+		args:   args,
+		config: config,
+	}
+}
+
+// start launches the command in the background, unless it is already running
+func (s *cmdStream) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.startTime = time.Now()
+	s.lastUpdate = time.Time{}
+	s.firstValue = make(chan struct{})
+	go s.run(ctx)
+}
+
+// stop terminates the command, it is started again on the next call to start
+func (s *cmdStream) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+func (s *cmdStream) run(ctx context.Context) {
+	delay := s.config.RestartDelay
+	for {
+		received, err := s.runOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			delay = s.config.RestartDelay
+		}
+
+		ui.Warning("Streaming command %s exited: %v. Restarting in %s...", s.exec, err, delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, s.config.MaxRestartDelay)
+	}
+}
+
+// runOnce runs the command until it exits and reports whether it printed at least one valid value
+func (s *cmdStream) runOnce(ctx context.Context) (received bool, err error) {
+	if _, err := util.CheckFilePermissionsForExecution(s.exec); err != nil {
+		return false, fmt.Errorf("cannot execute %s: %w", s.exec, err)
+	}
+
+	cmd := exec.CommandContext(ctx, s.exec, s.args...)
+	// run the command in its own process group, so children spawned by it
+	// (which may hold on to stdout) are terminated as well
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return false, err
+	}
+	if err = cmd.Start(); err != nil {
+		return false, err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) <= 0 {
+			continue
+		}
+		value, err := parseStreamLine(line, s.config.JsonField)
+		if err != nil {
+			ui.Warning("Unable to parse output of streaming command %s: %v", s.exec, err)
+			continue
+		}
+		s.setValue(ctx, value)
+		received = true
+	}
+
+	err = cmd.Wait()
+	if err == nil {
+		err = errors.New("end of output")
+	}
+	return received, err
+}
+
+// parseStreamLine parses a single line of output, which is either a plain value
+// or a JSON object containing the value in the given field
+func parseStreamLine(line string, jsonField string) (float64, error) {
+	if len(jsonField) <= 0 {
+		return strconv.ParseFloat(line, 64)
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &object); err != nil {
+		return 0, fmt.Errorf("invalid JSON object '%s': %w", line, err)
+	}
+	switch value := object[jsonField].(type) {
+	case float64:
+		return value, nil
+	case string:
+		return strconv.ParseFloat(value, 64)
+	case nil:
+		return 0, fmt.Errorf("field '%s' not found in '%s'", jsonField, line)
+	default:
+		return 0, fmt.Errorf("field '%s' is not a number in '%s'", jsonField, line)
+	}
+}
+
+func (s *cmdStream) setValue(ctx context.Context, value float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ctx.Err() != nil {
+		// the command was stopped, the value belongs to an outdated run
+		return
+	}
+	if s.lastUpdate.IsZero() {
+		close(s.firstValue)
+	}
+	s.value = value
+	s.lastUpdate = time.Now()
+}
+
+// get returns the last value printed by the command, or an error if it is stale.
+// Right after the command was started, this waits for the first value for up to the stale timeout.
+func (s *cmdStream) get() (float64, error) {
+	s.start()
+
+	s.mu.Lock()
+	firstValue := s.firstValue
+	remaining := s.config.StaleTimeout - time.Since(s.startTime)
+	s.mu.Unlock()
+
+	if remaining > 0 {
+		select {
+		case <-firstValue:
+		case <-time.After(remaining):
+		}
+	}
+
+	return s.last()
+}
+
+// last returns the last value printed by the command, or an error if there is none or it is stale.
+// Unlike get, this neither starts the command nor waits for a value.
+func (s *cmdStream) last() (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastUpdate.IsZero() {
+		return 0, fmt.Errorf("no value received from %s within %s", s.exec, s.config.StaleTimeout)
+	}
+	if age := time.Since(s.lastUpdate); age > s.config.StaleTimeout {
+		return 0, fmt.Errorf("no value received from %s for %s, last value is stale", s.exec, age.Truncate(time.Second))
+	}
+	return s.value, nil
+}
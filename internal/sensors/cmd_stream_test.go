@@ -0,0 +1,181 @@
+package sensors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createStreamingCmdSensor(t *testing.T, script string, streaming configuration.CmdStreamingConfig) *CmdSensor {
+	sensor, err := NewSensor(configuration.SensorConfig{
+		ID: "ipmi",
+		Cmd: &configuration.CmdSensorConfig{
+			Exec:      "/bin/sh",
+			Args:      []string{"-c", script},
+			Streaming: &streaming,
+		},
+	})
+	require.NoError(t, err)
+	cmdSensor := sensor.(*CmdSensor)
+	t.Cleanup(func() {
+		_ = cmdSensor.Close()
+	})
+	return cmdSensor
+}
+
+func TestParseStreamLine(t *testing.T) {
+	var tests = []struct {
+		tn        string
+		line      string
+		jsonField string
+		want      float64
+		wantErr   string
+	}{{
+		tn:   "plain value",
+		line: "42000",
+		want: 42000,
+	}, {
+		tn:        "json number",
+		line:      `{"temp": 42000, "fan": 1200}`,
+		jsonField: "temp",
+		want:      42000,
+	}, {
+		tn:        "json string",
+		line:      `{"temp": "42500"}`,
+		jsonField: "temp",
+		want:      42500,
+	}, {
+		tn:        "json field missing",
+		line:      `{"fan": 1200}`,
+		jsonField: "temp",
+		wantErr:   `field 'temp' not found in '{"fan": 1200}'`,
+	}, {
+		tn:        "json field not a number",
+		line:      `{"temp": [1, 2]}`,
+		jsonField: "temp",
+		wantErr:   `field 'temp' is not a number in '{"temp": [1, 2]}'`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.tn, func(t *testing.T) {
+			// WHEN
+			value, err := parseStreamLine(tt.line, tt.jsonField)
+
+			// THEN
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, value)
+			}
+		})
+	}
+}
+
+func TestCmdSensor_Streaming_ReadsValue(t *testing.T) {
+	// GIVEN
+	sensor := createStreamingCmdSensor(t, "echo 42000; sleep 10", configuration.CmdStreamingConfig{
+		StaleTimeout:    5 * time.Second,
+		RestartDelay:    time.Second,
+		MaxRestartDelay: time.Second,
+	})
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 42000.0, value)
+}
+
+func TestCmdSensor_Streaming_Stale(t *testing.T) {
+	// GIVEN
+	sensor := createStreamingCmdSensor(t, "echo 42000; sleep 10", configuration.CmdStreamingConfig{
+		StaleTimeout:    200 * time.Millisecond,
+		RestartDelay:    time.Second,
+		MaxRestartDelay: time.Second,
+	})
+	_, err := sensor.GetValue()
+	require.NoError(t, err)
+
+	// WHEN
+	time.Sleep(300 * time.Millisecond)
+	_, err = sensor.GetValue()
+
+	// THEN
+	assert.ErrorContains(t, err, "sensor ipmi: no value received from /bin/sh")
+	assert.ErrorContains(t, err, "last value is stale")
+}
+
+func TestCmdSensor_Streaming_RestartsExitedCommand(t *testing.T) {
+	// GIVEN
+	sensor := createStreamingCmdSensor(t, "echo 42000", configuration.CmdStreamingConfig{
+		StaleTimeout:    200 * time.Millisecond,
+		RestartDelay:    10 * time.Millisecond,
+		MaxRestartDelay: 20 * time.Millisecond,
+	})
+	_, err := sensor.GetValue()
+	require.NoError(t, err)
+
+	// WHEN
+	time.Sleep(300 * time.Millisecond)
+	value, err := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 42000.0, value)
+}
+
+func TestCmdSensor_Streaming_NoValue(t *testing.T) {
+	// GIVEN
+	sensor := createStreamingCmdSensor(t, "echo invalid; sleep 10", configuration.CmdStreamingConfig{
+		StaleTimeout:    100 * time.Millisecond,
+		RestartDelay:    time.Second,
+		MaxRestartDelay: time.Second,
+	})
+
+	// WHEN
+	_, err := sensor.GetValue()
+
+	// THEN
+	assert.EqualError(t, err, "sensor ipmi: no value received from /bin/sh within 100ms")
+}
+
+func TestCmdSensor_Streaming_GetLastValue(t *testing.T) {
+	// GIVEN
+	sensor := createStreamingCmdSensor(t, "echo 42000; sleep 10", configuration.CmdStreamingConfig{
+		StaleTimeout:    5 * time.Second,
+		RestartDelay:    time.Second,
+		MaxRestartDelay: time.Second,
+	})
+	_, err := sensor.GetValue()
+	require.NoError(t, err)
+
+	// WHEN
+	value, err := ReadLastValue(sensor)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 42000.0, value)
+}
+
+func TestCmdSensor_Streaming_GetLastValue_DoesNotWaitOrStart(t *testing.T) {
+	// GIVEN
+	sensor := createStreamingCmdSensor(t, "echo 42000; sleep 10", configuration.CmdStreamingConfig{
+		StaleTimeout:    5 * time.Second,
+		RestartDelay:    time.Second,
+		MaxRestartDelay: time.Second,
+	})
+
+	// WHEN
+	start := time.Now()
+	_, err := sensor.GetLastValue()
+
+	// THEN
+	assert.EqualError(t, err, "sensor ipmi: no value received from /bin/sh within 5s")
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Nil(t, sensor.stream.cancel)
+}
@@ -3,6 +3,7 @@ package sensors
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/markusressel/fan2go/internal/configuration"
 )
@@ -20,12 +21,85 @@ type Sensor interface {
 	// GetMovingAvg returns the moving average of this sensor's value
 	GetMovingAvg() float64
 	SetMovingAvg(avg float64)
+
+	// IsFaulted returns true if this sensor is currently considered faulted
+	IsFaulted() bool
+	// GetFaultReason returns the reason why this sensor is considered faulted, if any
+	GetFaultReason() string
+	// SetFault marks this sensor as faulted for the given reason, an empty reason clears the fault
+	SetFault(reason string)
+}
+
+// Sampler is implemented by sensors that compute their value from the difference between two samples,
+// like CpuUsageSensor, RaplSensor and DerivativeSensor. Sample is called on each polling tick of the sensor monitor, and
+// GetValue returns the value computed by the last sample, so reading the sensor (f.ex. by the
+// statistics collector or a pid curve) doesn't shorten the interval of the next value.
+type Sampler interface {
+	Sample() error
+}
+
+// minSampleInterval is the minimum time between two samples of a Sampler
+var minSampleInterval = 100 * time.Millisecond
+
+// SampleAndReadValue takes a new sample of the given sensor, if it is a Sampler, and reads its current value
+// like ReadValue. Samples should only be taken by the owner of a sensor, f.ex. its monitor.
+func SampleAndReadValue(sensor Sensor) (float64, error) {
+	if sampler, ok := sensor.(Sampler); ok {
+		err := sampler.Sample()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return ReadValue(sensor)
+}
+
+// waitForMinSampleInterval waits until minSampleInterval passed since the given time of the last sample,
+// f.ex. when a sensor is read once right after it was created
+func waitForMinSampleInterval(last time.Time, now time.Time) {
+	if wait := minSampleInterval - now.Sub(last); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// RegistryReader provides access to other sensors, used by sensors that derive their value from them
+type RegistryReader interface {
+	GetSensor(id string) (Sensor, bool)
+}
+
+// ReadValue reads the current value of the given sensor and applies the value correction
+// (input unit conversion, scale and offset) of its configuration
+func ReadValue(sensor Sensor) (float64, error) {
+	value, err := sensor.GetValue()
+	if err != nil {
+		return value, err
+	}
+	return sensor.GetConfig().CorrectValue(value), nil
+}
+
+// LastValueReader is implemented by sensors whose value is produced in the background
+type LastValueReader interface {
+	// GetLastValue returns the last known value, without waiting for a new one
+	GetLastValue() (float64, error)
+}
+
+// ReadLastValue is like ReadValue, but doesn't wait for sensors producing their value in the background
+func ReadLastValue(sensor Sensor) (float64, error) {
+	reader, ok := sensor.(LastValueReader)
+	if !ok {
+		return ReadValue(sensor)
+	}
+	value, err := reader.GetLastValue()
+	if err != nil {
+		return value, err
+	}
+	return sensor.GetConfig().CorrectValue(value), nil
 }
 
 func NewSensor(config configuration.SensorConfig) (Sensor, error) {
 	if config.HwMon != nil {
 		return &HwmonSensor{
 			Index:  config.HwMon.Index,
+			Type:   config.HwMon.Type,
 			Input:  config.HwMon.TempInput,
 			Config: config,
 
@@ -46,11 +120,15 @@ func NewSensor(config configuration.SensorConfig) (Sensor, error) {
 	}
 
 	if config.Cmd != nil {
-		return &CmdSensor{
+		sensor := &CmdSensor{
 			Config: config,
 
 			mu: sync.Mutex{},
-		}, nil
+		}
+		if config.Cmd.Streaming != nil {
+			sensor.stream = newCmdStream(config.Cmd.Exec, config.Cmd.Args, *config.Cmd.Streaming)
+		}
+		return sensor, nil
 	}
 
 	if config.Disk != nil {
@@ -60,5 +138,39 @@ func NewSensor(config configuration.SensorConfig) (Sensor, error) {
 		}, nil
 	}
 
+	if config.ThermalZone != nil {
+		return &ThermalZoneSensor{
+			Config: config,
+			mu:     sync.Mutex{},
+		}, nil
+	}
+
+	if config.CpuUsage != nil {
+		return CreateCpuUsageSensor(config), nil
+	}
+
+	if config.Rapl != nil {
+		return CreateRaplSensor(config), nil
+	}
+
+	if config.Remote != nil {
+		return CreateRemoteSensor(config), nil
+	}
+
+	if config.Aggregate != nil {
+		return CreateAggregateSensor(config)
+	}
+
+	if config.Expression != nil {
+		return CreateExpressionSensor(config)
+	}
+
+	if config.Derivative != nil {
+		return &DerivativeSensor{
+			Config: config,
+			mu:     sync.Mutex{},
+		}, nil
+	}
+
 	return nil, fmt.Errorf("no matching sensor type for sensor: %s", config.ID)
 }
@@ -0,0 +1,195 @@
+package sensors
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+const procBase = "/proc"
+
+// cpuTimes are the accumulated busy and total times of a single cpu line in /proc/stat (in USER_HZ)
+type cpuTimes struct {
+	busy  uint64
+	total uint64
+}
+
+// readCpuTimes parses /proc/stat and returns the times of the "cpu" (all cores) and "cpuN" lines by name
+func readCpuTimes(procBase string) (map[string]cpuTimes, error) {
+	file, err := os.Open(filepath.Join(procBase, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	result := map[string]cpuTimes{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+
+		// user nice system idle iowait irq softirq steal (guest and guest_nice are already part of user and nice)
+		var times cpuTimes
+		for i, field := range fields[1:min(len(fields), 9)] {
+			value, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse %s times: %w", fields[0], err)
+			}
+			times.total += value
+			// idle and iowait
+			if i != 3 && i != 4 {
+				times.busy += value
+			}
+		}
+		result[fields[0]] = times
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if _, ok := result["cpu"]; !ok {
+		return nil, fmt.Errorf("no cpu times found in %s", filepath.Join(procBase, "stat"))
+	}
+	return result, nil
+}
+
+// CpuUsageSensor reports the CPU utilization between the last two samples in milli-percent (100% = 100000)
+type CpuUsageSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+	FaultState
+
+	// procBase is the procfs mount point, "/proc" if empty
+	procBase string
+	// last are the cpu times of the previous sample, nil until the baseline was taken
+	last     map[string]cpuTimes
+	lastTime time.Time
+	// value and err are the result of the last sample
+	value float64
+	err   error
+
+	mu sync.Mutex
+	// sampleMu serializes samples
+	sampleMu sync.Mutex
+}
+
+// CreateCpuUsageSensor creates a CpuUsageSensor and takes the baseline sample,
+// so the first value is not the utilization since boot
+func CreateCpuUsageSensor(config configuration.SensorConfig) *CpuUsageSensor {
+	sensor := &CpuUsageSensor{
+		Config: config,
+		mu:     sync.Mutex{},
+	}
+	_ = sensor.Sample()
+	return sensor
+}
+
+func (s *CpuUsageSensor) GetId() string {
+	return s.Config.ID
+}
+
+func (s *CpuUsageSensor) GetLabel() string {
+	return fmt.Sprintf("CPU Usage (%s)", s.mode())
+}
+
+func (s *CpuUsageSensor) GetConfig() configuration.SensorConfig {
+	return s.Config
+}
+
+// GetValue returns the utilization computed by the last sample
+func (s *CpuUsageSensor) GetValue() (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value, s.err
+}
+
+// Sample reads the cpu times and computes the utilization since the previous sample.
+// The first sample only takes the baseline and results in a utilization of 0.
+func (s *CpuUsageSensor) Sample() error {
+	s.sampleMu.Lock()
+	defer s.sampleMu.Unlock()
+
+	if s.last != nil {
+		waitForMinSampleInterval(s.lastTime, time.Now())
+	}
+
+	base := s.procBase
+	if len(base) <= 0 {
+		base = procBase
+	}
+	current, err := readCpuTimes(base)
+	if err != nil {
+		err = fmt.Errorf("sensor %s: %w", s.Config.ID, err)
+		s.setResult(0, err)
+		return err
+	}
+	last := s.last
+	s.last = current
+	s.lastTime = time.Now()
+
+	if last == nil {
+		s.setResult(0, nil)
+		return nil
+	}
+
+	if s.mode() != configuration.CpuUsageModeMaxCore {
+		s.setResult(computeCpuUsage(last["cpu"], current["cpu"]), nil)
+		return nil
+	}
+
+	result := 0.0
+	for name, times := range current {
+		if name == "cpu" {
+			continue
+		}
+		result = max(result, computeCpuUsage(last[name], times))
+	}
+	s.setResult(result, nil)
+	return nil
+}
+
+func (s *CpuUsageSensor) setResult(value float64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.value = value
+	s.err = err
+}
+
+// computeCpuUsage returns the utilization between two readings in milli-percent
+func computeCpuUsage(last cpuTimes, current cpuTimes) float64 {
+	if current.total <= last.total || current.busy < last.busy {
+		return 0
+	}
+	busy := float64(current.busy - last.busy)
+	total := float64(current.total - last.total)
+	return min(busy/total, 1) * 100 * 1000
+}
+
+func (s *CpuUsageSensor) mode() string {
+	if len(s.Config.CpuUsage.Mode) <= 0 {
+		return configuration.CpuUsageModeTotal
+	}
+	return s.Config.CpuUsage.Mode
+}
+
+func (s *CpuUsageSensor) GetMovingAvg() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.MovingAvg
+}
+
+func (s *CpuUsageSensor) SetMovingAvg(avg float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MovingAvg = avg
+}
@@ -0,0 +1,120 @@
+package sensors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeProcStat writes a fake /proc/stat below procBase
+func writeProcStat(t *testing.T, procBase string, content string) {
+	require.NoError(t, os.WriteFile(filepath.Join(procBase, "stat"), []byte(content), 0644))
+}
+
+func createCpuUsageSensor(procBase string, mode string) *CpuUsageSensor {
+	return &CpuUsageSensor{
+		Config: configuration.SensorConfig{
+			ID:       "cpu_load",
+			CpuUsage: &configuration.CpuUsageSensorConfig{Mode: mode},
+		},
+		procBase: procBase,
+	}
+}
+
+func TestReadCpuTimes(t *testing.T) {
+	// GIVEN
+	procBase := t.TempDir()
+	writeProcStat(t, procBase, `cpu  100 10 50 800 40 0 0 0 20 0
+cpu0 60 5 30 400 5 0 0 0 10 0
+cpu1 40 5 20 400 35 0 0 0 10 0
+intr 12345 0 0
+ctxt 67890
+`)
+
+	// WHEN
+	times, err := readCpuTimes(procBase)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Len(t, times, 3)
+	assert.Equal(t, cpuTimes{busy: 160, total: 1000}, times["cpu"])
+	assert.Equal(t, cpuTimes{busy: 95, total: 500}, times["cpu0"])
+}
+
+// disableMinSampleInterval allows taking samples right after each other
+func disableMinSampleInterval(t *testing.T) {
+	interval := minSampleInterval
+	minSampleInterval = 0
+	t.Cleanup(func() {
+		minSampleInterval = interval
+	})
+}
+
+func TestCpuUsageSensor_Sample_Total(t *testing.T) {
+	// GIVEN
+	disableMinSampleInterval(t)
+	procBase := t.TempDir()
+	sensor := createCpuUsageSensor(procBase, configuration.CpuUsageModeTotal)
+	writeProcStat(t, procBase, "cpu  100 0 100 800 0 0 0 0 0 0\n")
+
+	// WHEN
+	errBaseline := sensor.Sample()
+	baseline, _ := sensor.GetValue()
+	writeProcStat(t, procBase, "cpu  250 0 200 850 0 0 0 0 0 0\n")
+	errCurrent := sensor.Sample()
+	current, _ := sensor.GetValue()
+	// reading the value again doesn't start a new interval
+	writeProcStat(t, procBase, "cpu  250 0 200 900 0 0 0 0 0 0\n")
+	again, errAgain := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, errBaseline)
+	// the utilization since boot is not reported
+	assert.Equal(t, 0.0, baseline)
+	require.NoError(t, errCurrent)
+	assert.InDelta(t, 83333.3333, current, 0.001)
+	require.NoError(t, errAgain)
+	assert.Equal(t, current, again)
+}
+
+func TestCpuUsageSensor_Sample_MaxCore(t *testing.T) {
+	// GIVEN
+	disableMinSampleInterval(t)
+	procBase := t.TempDir()
+	sensor := createCpuUsageSensor(procBase, configuration.CpuUsageModeMaxCore)
+	writeProcStat(t, procBase, `cpu  200 0 0 1800 0 0 0 0 0 0
+cpu0 100 0 0 900 0 0 0 0 0 0
+cpu1 100 0 0 900 0 0 0 0 0 0
+`)
+	err := sensor.Sample()
+	require.NoError(t, err)
+
+	// WHEN
+	writeProcStat(t, procBase, `cpu  300 0 0 1900 0 0 0 0 0 0
+cpu0 110 0 0 990 0 0 0 0 0 0
+cpu1 190 0 0 910 0 0 0 0 0 0
+`)
+	err = sensor.Sample()
+	value, _ := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, err)
+	assert.InDelta(t, 90000.0, value, 0.0001)
+}
+
+func TestCpuUsageSensor_Sample_Missing(t *testing.T) {
+	// GIVEN
+	sensor := createCpuUsageSensor(t.TempDir(), configuration.CpuUsageModeTotal)
+
+	// WHEN
+	err := sensor.Sample()
+	_, valueErr := sensor.GetValue()
+
+	// THEN
+	assert.ErrorContains(t, err, "sensor cpu_load: ")
+	assert.Equal(t, err, valueErr)
+}
@@ -0,0 +1,148 @@
+package sensors
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+// DerivativeSensor computes the rate of change of another sensor in milli-degrees per second.
+// The slope is computed by a linear regression over timestamped samples of the configured window,
+// so it doesn't depend on the exact polling interval. Samples are only taken by the monitor of the sensor.
+type DerivativeSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+	FaultState
+
+	registry RegistryReader
+	samples  []derivativeSample
+	// now returns the current time, replaceable for tests
+	now func() time.Time
+	// value and err are the result of the last sample
+	value float64
+	err   error
+
+	mu sync.Mutex
+	// samplesMu serializes samples
+	samplesMu sync.Mutex
+}
+
+type derivativeSample struct {
+	time  time.Time
+	value float64
+}
+
+func (sensor *DerivativeSensor) BindRegistry(registry RegistryReader) {
+	sensor.registry = registry
+}
+
+func (sensor *DerivativeSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *DerivativeSensor) GetLabel() string {
+	return fmt.Sprintf("Derivative (%s)", sensor.Config.Derivative.Sensor)
+}
+
+func (sensor *DerivativeSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+// GetValue returns the slope computed by the last sample
+func (sensor *DerivativeSensor) GetValue() (float64, error) {
+	sensor.mu.Lock()
+	defer sensor.mu.Unlock()
+	return sensor.value, sensor.err
+}
+
+// Sample records the monitored value of the referenced sensor and computes its slope over the configured window.
+// Until at least two samples are available, the slope is 0.
+func (sensor *DerivativeSensor) Sample() error {
+	sensor.samplesMu.Lock()
+	defer sensor.samplesMu.Unlock()
+
+	value, err := sensor.readSource()
+	if err != nil {
+		sensor.setResult(0, err)
+		return err
+	}
+
+	now := time.Now()
+	if sensor.now != nil {
+		now = sensor.now()
+	}
+	sensor.samples = append(sensor.samples, derivativeSample{time: now, value: value})
+	cutoff := now.Add(-sensor.Config.Derivative.Window)
+	for len(sensor.samples) > 2 && sensor.samples[0].time.Before(cutoff) {
+		sensor.samples = sensor.samples[1:]
+	}
+
+	// value/s, as the values of the referenced sensor are in milli-degrees, the result is in milli-degrees/s
+	sensor.setResult(computeSlope(sensor.samples), nil)
+	return nil
+}
+
+// readSource returns the value of the referenced sensor as last polled by its monitor,
+// so the sensor hardware isn't read a second time
+func (sensor *DerivativeSensor) readSource() (float64, error) {
+	if sensor.registry == nil {
+		return 0, fmt.Errorf("no registry bound to derivative sensor '%s'", sensor.Config.ID)
+	}
+	sourceId := sensor.Config.Derivative.Sensor
+	source, exists := sensor.registry.GetSensor(sourceId)
+	if !exists || source == nil {
+		return 0, fmt.Errorf("sensor %s: referenced sensor not found with id '%s'", sensor.Config.ID, sourceId)
+	}
+	if source.IsFaulted() {
+		return 0, fmt.Errorf("sensor %s: referenced sensor '%s': %w", sensor.Config.ID, sourceId, ErrSensorFaulted)
+	}
+	return source.GetMovingAvg(), nil
+}
+
+func (sensor *DerivativeSensor) setResult(value float64, err error) {
+	sensor.mu.Lock()
+	defer sensor.mu.Unlock()
+	sensor.value = value
+	sensor.err = err
+}
+
+// computeSlope returns the slope (per second) of the least squares regression line through the given samples
+func computeSlope(samples []derivativeSample) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+
+	start := samples[0].time
+	var meanT, meanV float64
+	for _, sample := range samples {
+		meanT += sample.time.Sub(start).Seconds()
+		meanV += sample.value
+	}
+	meanT /= float64(len(samples))
+	meanV /= float64(len(samples))
+
+	var covariance, variance float64
+	for _, sample := range samples {
+		dt := sample.time.Sub(start).Seconds() - meanT
+		covariance += dt * (sample.value - meanV)
+		variance += dt * dt
+	}
+	if variance == 0 {
+		return 0
+	}
+	return covariance / variance
+}
+
+func (sensor *DerivativeSensor) GetMovingAvg() (avg float64) {
+	sensor.mu.Lock()
+	defer sensor.mu.Unlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *DerivativeSensor) SetMovingAvg(avg float64) {
+	sensor.mu.Lock()
+	defer sensor.mu.Unlock()
+	sensor.MovingAvg = avg
+}
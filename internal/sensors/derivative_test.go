@@ -0,0 +1,128 @@
+package sensors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createDerivativeSensor creates a derivative sensor for a hwmon sensor with id "cpu",
+// with a manually advanced clock
+func createDerivativeSensor(t *testing.T, window time.Duration) (*DerivativeSensor, Sensor, *time.Time) {
+	cpu := CreateSensor("cpu", configuration.HwMonSensorConfig{}, 40000)
+
+	sensor, err := NewSensor(configuration.SensorConfig{
+		ID: "cpu_slope",
+		Derivative: &configuration.DerivativeSensorConfig{
+			Sensor: "cpu",
+			Window: window,
+		},
+	})
+	require.NoError(t, err)
+	derivative := sensor.(*DerivativeSensor)
+	derivative.BindRegistry(&mockRegistry{sensors: map[string]Sensor{"cpu": cpu}})
+
+	now := time.Now()
+	derivative.now = func() time.Time { return now }
+	return derivative, cpu, &now
+}
+
+// sampleDerivativeAt samples the derivative sensor at the given time, after the monitor
+// of the source sensor polled the given value
+func sampleDerivativeAt(t *testing.T, sensor *DerivativeSensor, source Sensor, now *time.Time, at time.Time, value float64) float64 {
+	*now = at
+	source.SetMovingAvg(value)
+	require.NoError(t, sensor.Sample())
+	result, err := sensor.GetValue()
+	require.NoError(t, err)
+	return result
+}
+
+func TestComputeSlope(t *testing.T) {
+	// GIVEN
+	start := time.Now()
+	samples := []derivativeSample{
+		{time: start, value: 40000},
+		{time: start.Add(1 * time.Second), value: 41000},
+		// irregular polling interval
+		{time: start.Add(1500 * time.Millisecond), value: 41500},
+		{time: start.Add(4 * time.Second), value: 44000},
+	}
+
+	// WHEN
+	slope := computeSlope(samples)
+	single := computeSlope(samples[:1])
+
+	// THEN
+	assert.InDelta(t, 1000.0, slope, 0.0001)
+	assert.Equal(t, 0.0, single)
+}
+
+func TestDerivativeSensor_Sample(t *testing.T) {
+	// GIVEN
+	sensor, source, now := createDerivativeSensor(t, 10*time.Second)
+	start := *now
+
+	// WHEN
+	first := sampleDerivativeAt(t, sensor, source, now, start, 40000)
+	second := sampleDerivativeAt(t, sensor, source, now, start.Add(2*time.Second), 44000)
+	third := sampleDerivativeAt(t, sensor, source, now, start.Add(3*time.Second), 46000)
+
+	// THEN
+	assert.Equal(t, 0.0, first)
+	assert.InDelta(t, 2000.0, second, 0.0001)
+	assert.InDelta(t, 2000.0, third, 0.0001)
+}
+
+func TestDerivativeSensor_Sample_DropsSamplesOutsideOfWindow(t *testing.T) {
+	// GIVEN
+	sensor, source, now := createDerivativeSensor(t, 5*time.Second)
+	start := *now
+
+	// WHEN
+	sampleDerivativeAt(t, sensor, source, now, start, 40000)
+	sampleDerivativeAt(t, sensor, source, now, start.Add(5*time.Second), 50000)
+	sampleDerivativeAt(t, sensor, source, now, start.Add(10*time.Second), 50000)
+	result := sampleDerivativeAt(t, sensor, source, now, start.Add(11*time.Second), 50000)
+
+	// THEN
+	assert.Equal(t, 0.0, result)
+	assert.Len(t, sensor.samples, 2)
+}
+
+func TestDerivativeSensor_Sample_SourceFaulted(t *testing.T) {
+	// GIVEN
+	sensor, _, _ := createDerivativeSensor(t, 10*time.Second)
+	cpu, _ := sensor.registry.GetSensor("cpu")
+	cpu.SetFault("sensor reported invalid value 127.000")
+
+	// WHEN
+	sampleErr := sensor.Sample()
+	_, err := sensor.GetValue()
+
+	// THEN
+	assert.ErrorIs(t, sampleErr, ErrSensorFaulted)
+	assert.ErrorIs(t, err, ErrSensorFaulted)
+}
+
+func TestDerivativeSensor_GetValue_DoesNotSample(t *testing.T) {
+	// GIVEN
+	sensor, source, now := createDerivativeSensor(t, 10*time.Second)
+	start := *now
+	sampleDerivativeAt(t, sensor, source, now, start, 40000)
+	sampleDerivativeAt(t, sensor, source, now, start.Add(2*time.Second), 44000)
+
+	// WHEN
+	*now = start.Add(4 * time.Second)
+	source.SetMovingAvg(60000)
+	first, _ := sensor.GetValue()
+	second, _ := sensor.GetValue()
+
+	// THEN
+	assert.InDelta(t, 2000.0, first, 0.0001)
+	assert.InDelta(t, 2000.0, second, 0.0001)
+	assert.Len(t, sensor.samples, 2)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"syscall"
@@ -16,21 +17,50 @@ import (
 const (
 	hdioDriverCmd    = 0x031f // ioctl: ATA drive command
 	ataOpSmart       = 0xb0   // WIN_SMART ATA command
+	ataOpCheckPower  = 0xe5   // CHECK POWER MODE ATA command
+	ataPowerStandby  = 0x00   // CHECK POWER MODE result: standby
 	smartReadData    = 0xd0   // SMART READ DATA subcommand
 	smartAttrAirflow = 190    // SMART attribute: airflow temp
 	smartAttrTemp    = 194    // SMART attribute: drive temp
+
+	nvmeIoctlAdminCmd     = 0xc0484e41 // ioctl: NVME_IOCTL_ADMIN_CMD
+	nvmeAdminGetLogPage   = 0x02       // Get Log Page admin command
+	nvmeLogSmartHealth    = 0x02       // log identifier: SMART / Health Information
+	nvmeSmartLogSize      = 512        // size of the SMART / Health Information log page
+	nvmeTempSensorCount   = 8          // number of individual temperature sensors in the SMART log
+	nvmeTempSensorsOffset = 200        // offset of "Temperature Sensor 1" in the SMART log
 )
 
 type DiskSensor struct {
 	Config    configuration.SensorConfig `json:"configuration"`
 	MovingAvg float64                    `json:"movingAvg"`
-	mu        sync.Mutex
+	// InStandby is true if the drive was in standby during the last read
+	InStandby bool `json:"inStandby"`
+	FaultState
+	mu sync.Mutex
+
+	// sysBase is the sysfs mount point, "/sys" if empty
+	sysBase string
+	// resolvedDevice caches the result of resolveDevice until a read fails
+	resolvedDevice string
+	// lastValue is the last temperature read while the drive was active
+	lastValue *float64
+	// readMu serializes reads, since the sensor is read by the monitor as well as the statistics collector
+	readMu sync.Mutex
 }
 
 func (s *DiskSensor) GetId() string {
 	return s.Config.ID
 }
 
+// IsInStandby returns true if the drive was in standby during the last read, in which case the
+// value of the sensor is the configured standbyValue or the last temperature read before the standby
+func (s *DiskSensor) IsInStandby() bool {
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
+	return s.InStandby
+}
+
 func (s *DiskSensor) GetLabel() string {
 	return fmt.Sprintf("Disk (%s)", s.Config.Disk.Device)
 }
@@ -70,19 +100,122 @@ func resolveDeviceAt(device, devBase, byIdBase string) (string, error) {
 }
 
 func (s *DiskSensor) GetValue() (float64, error) {
-	resolved, err := resolveDevice(s.Config.Disk.Device)
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
+
+	if len(s.resolvedDevice) <= 0 {
+		resolved, err := resolveDevice(s.Config.Disk.Device)
+		if err != nil {
+			return 0, err
+		}
+		s.resolvedDevice = resolved
+	}
+	resolved := s.resolvedDevice
+	deviceName := filepath.Base(resolved)
+
+	sysBase := s.sysBase
+	if len(sysBase) <= 0 {
+		sysBase = "/sys"
+	}
+
+	// Reading the temperature (via drivetemp or SMART) wakes up a drive in standby,
+	// so check the power state first
+	s.InStandby = isDiskInStandby(sysBase, deviceName, resolved)
+	if s.InStandby {
+		if s.Config.Disk.StandbyValue != nil {
+			return *s.Config.Disk.StandbyValue * 1000, nil
+		}
+		if s.lastValue != nil {
+			return *s.lastValue, nil
+		}
+		return 0, fmt.Errorf("disk %s is in standby and its temperature is not known yet", s.Config.Disk.Device)
+	}
+
+	temp, err := readDiskTemp(sysBase, deviceName, resolved, s.Config.Disk.NvmeSensor)
 	if err != nil {
+		// the device might have been re-plugged under a different name, resolve it again on the next read
+		s.resolvedDevice = ""
 		return 0, err
 	}
-	deviceName := filepath.Base(resolved)
+	s.lastValue = &temp
+	return temp, nil
+}
+
+func readDiskTemp(sysBase, deviceName, devicePath string, nvmeSensor int) (float64, error) {
+	// Primary: sysfs hwmon (drivetemp for SATA, nvme-hwmon for NVMe),
+	// which only exposes the composite temperature of NVMe drives
+	if nvmeSensor == 0 {
+		if temp, err := readDiskTempFromSysfsAt(sysBase, deviceName); err == nil {
+			return temp, nil
+		}
+	}
 
-	// Primary: sysfs hwmon (drivetemp for SATA, nvme-hwmon for NVMe)
-	if temp, err := readDiskTempFromSysfs(deviceName); err == nil {
+	// Fallback: NVMe SMART log page
+	if strings.HasPrefix(deviceName, "nvme") {
+		return readNvmeSmartTemp(devicePath, nvmeSensor)
+	}
+
+	// Fallback: ATA SMART ioctl (SATA/IDE only), then NVMe for drives behind HBAs with a different name
+	temp, ataErr := readAtaSmartTemp(devicePath)
+	if ataErr == nil {
 		return temp, nil
 	}
+	temp, nvmeErr := readNvmeSmartTemp(devicePath, nvmeSensor)
+	if nvmeErr == nil {
+		return temp, nil
+	}
+	return 0, fmt.Errorf("%v; %v", ataErr, nvmeErr)
+}
 
-	// Fallback: ATA SMART ioctl (SATA/IDE only)
-	return readAtaSmartTemp(resolved)
+// isDiskInStandby checks whether the given drive is spun down, without waking it up.
+// Drives whose power state can't be determined are considered active.
+func isDiskInStandby(sysBase, deviceName, devicePath string) bool {
+	if isRuntimeSuspendedAt(sysBase, deviceName) {
+		return true
+	}
+	standby, err := readAtaStandby(devicePath)
+	return err == nil && standby
+}
+
+// isRuntimeSuspendedAt checks the runtime power management status of a block device
+func isRuntimeSuspendedAt(sysBase, deviceName string) bool {
+	content, err := os.ReadFile(fmt.Sprintf("%s/class/block/%s/device/power/runtime_status", sysBase, deviceName))
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(content)) == "suspended"
+}
+
+// readAtaStandby issues an ATA CHECK POWER MODE command, which doesn't spin up the drive
+func readAtaStandby(device string) (bool, error) {
+	f, err := os.Open(device)
+	if err != nil {
+		return false, fmt.Errorf("cannot open %s: %w", device, err)
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	buf := make([]byte, 4)
+	buf[0] = ataOpCheckPower
+
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		f.Fd(),
+		hdioDriverCmd,
+		uintptr(unsafe.Pointer(&buf[0])),
+	)
+	if errno != 0 {
+		return false, fmt.Errorf("HDIO_DRIVE_CMD ioctl on %s: %w", device, errno)
+	}
+
+	return parseAtaPowerMode(buf), nil
+}
+
+// parseAtaPowerMode returns true if the result of a CHECK POWER MODE command indicates standby.
+// The power mode is returned in the sector count register (byte 2).
+func parseAtaPowerMode(result []byte) bool {
+	return len(result) > 2 && result[2] == ataPowerStandby
 }
 
 func (s *DiskSensor) GetMovingAvg() float64 {
@@ -97,10 +230,6 @@ func (s *DiskSensor) SetMovingAvg(avg float64) {
 	s.MovingAvg = avg
 }
 
-func readDiskTempFromSysfs(deviceName string) (float64, error) {
-	return readDiskTempFromSysfsAt("/sys", deviceName)
-}
-
 func readDiskTempFromSysfsAt(sysBase, deviceName string) (float64, error) {
 	patterns := []string{
 		fmt.Sprintf("%s/class/block/%s/device/hwmon/hwmon*/temp*_input", sysBase, deviceName),
@@ -191,3 +320,87 @@ func parseAtaSmartAttributes(data []byte, device string) (float64, error) {
 	}
 	return 0, fmt.Errorf("no temperature attribute in SMART data for %s", device)
 }
+
+// nvmePassthruCmd mirrors struct nvme_passthru_cmd from linux/nvme_ioctl.h
+type nvmePassthruCmd struct {
+	opcode      uint8
+	flags       uint8
+	rsvd1       uint16
+	nsid        uint32
+	cdw2        uint32
+	cdw3        uint32
+	metadata    uint64
+	addr        uint64
+	metadataLen uint32
+	dataLen     uint32
+	cdw10       uint32
+	cdw11       uint32
+	cdw12       uint32
+	cdw13       uint32
+	cdw14       uint32
+	cdw15       uint32
+	timeoutMs   uint32
+	result      uint32
+}
+
+// readNvmeSmartTemp reads the SMART / Health Information log page via an NVMe admin command.
+// Returns millidegrees Celsius.
+func readNvmeSmartTemp(device string, sensor int) (float64, error) {
+	f, err := os.Open(device)
+	if err != nil {
+		return 0, fmt.Errorf("cannot open %s: %w", device, err)
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	data := make([]byte, nvmeSmartLogSize)
+	cmd := nvmePassthruCmd{
+		opcode:  nvmeAdminGetLogPage,
+		nsid:    0xffffffff, // controller wide log
+		addr:    uint64(uintptr(unsafe.Pointer(&data[0]))),
+		dataLen: nvmeSmartLogSize,
+		// number of dwords to read (0-based) in the upper half, log identifier in the lower half
+		cdw10: uint32(nvmeSmartLogSize/4-1)<<16 | nvmeLogSmartHealth,
+	}
+
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		f.Fd(),
+		nvmeIoctlAdminCmd,
+		uintptr(unsafe.Pointer(&cmd)),
+	)
+	// data is only referenced by address in cmd, make sure it stays alive during the ioctl
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return 0, fmt.Errorf("NVME_IOCTL_ADMIN_CMD ioctl on %s: %w", device, errno)
+	}
+
+	return parseNvmeSmartLog(data, sensor, device)
+}
+
+// parseNvmeSmartLog parses the SMART / Health Information log page and returns
+// the requested temperature in millidegrees Celsius.
+// sensor 0 selects the composite temperature, 1-8 the respective temperature sensor.
+func parseNvmeSmartLog(data []byte, sensor int, device string) (float64, error) {
+	// SMART / Health Information log layout (temperatures in Kelvin, little endian):
+	// offset 1: composite temperature (2 bytes)
+	// offset 200: temperature sensor 1-8 (2 bytes each, 0 if not implemented)
+	if sensor < 0 || sensor > nvmeTempSensorCount {
+		return 0, fmt.Errorf("invalid NVMe temperature sensor %d for %s, must be in range [0..%d]", sensor, device, nvmeTempSensorCount)
+	}
+
+	offset := 1
+	if sensor > 0 {
+		offset = nvmeTempSensorsOffset + (sensor-1)*2
+	}
+	if offset+2 > len(data) {
+		return 0, fmt.Errorf("NVMe SMART log of %s is too short", device)
+	}
+
+	kelvin := int(data[offset]) | int(data[offset+1])<<8
+	if kelvin == 0 {
+		return 0, fmt.Errorf("NVMe temperature sensor %d not implemented by %s", sensor, device)
+	}
+	return float64(kelvin*1000 - 273150), nil
+}
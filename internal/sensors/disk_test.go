@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"unsafe"
 
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/stretchr/testify/assert"
@@ -80,6 +81,58 @@ func TestParseAtaSmartAttributes_TruncatedData(t *testing.T) {
 	assert.Contains(t, err.Error(), "no temperature attribute")
 }
 
+// --- Pure function tests: parseNvmeSmartLog ---
+
+// buildNvmeSmartLog builds a 512-byte SMART / Health Information log page with the given
+// composite temperature and individual temperature sensors (all in Kelvin).
+func buildNvmeSmartLog(composite uint16, sensors ...uint16) []byte {
+	data := make([]byte, 512)
+	data[1] = byte(composite)
+	data[2] = byte(composite >> 8)
+	for i, kelvin := range sensors {
+		data[200+i*2] = byte(kelvin)
+		data[200+i*2+1] = byte(kelvin >> 8)
+	}
+	return data
+}
+
+func TestParseNvmeSmartLog_Composite(t *testing.T) {
+	data := buildNvmeSmartLog(318, 320, 325) // 318 K = 44.85 °C
+	temp, err := parseNvmeSmartLog(data, 0, "/dev/nvme0n1")
+	require.NoError(t, err)
+	assert.Equal(t, float64(44850), temp)
+}
+
+func TestParseNvmeSmartLog_TemperatureSensor(t *testing.T) {
+	data := buildNvmeSmartLog(318, 320, 325)
+	temp, err := parseNvmeSmartLog(data, 2, "/dev/nvme0n1")
+	require.NoError(t, err)
+	assert.Equal(t, float64(51850), temp)
+}
+
+func TestParseNvmeSmartLog_SensorNotImplemented(t *testing.T) {
+	data := buildNvmeSmartLog(318, 320)
+	_, err := parseNvmeSmartLog(data, 3, "/dev/nvme0n1")
+	assert.EqualError(t, err, "NVMe temperature sensor 3 not implemented by /dev/nvme0n1")
+}
+
+func TestParseNvmeSmartLog_InvalidSensor(t *testing.T) {
+	data := buildNvmeSmartLog(318)
+	_, err := parseNvmeSmartLog(data, 9, "/dev/nvme0n1")
+	assert.EqualError(t, err, "invalid NVMe temperature sensor 9 for /dev/nvme0n1, must be in range [0..8]")
+}
+
+func TestParseNvmeSmartLog_TruncatedData(t *testing.T) {
+	data := make([]byte, 100)
+	_, err := parseNvmeSmartLog(data, 1, "/dev/nvme0n1")
+	assert.EqualError(t, err, "NVMe SMART log of /dev/nvme0n1 is too short")
+}
+
+func TestNvmePassthruCmd_Size(t *testing.T) {
+	// must match sizeof(struct nvme_passthru_cmd), which is encoded in NVME_IOCTL_ADMIN_CMD
+	assert.Equal(t, uintptr(72), unsafe.Sizeof(nvmePassthruCmd{}))
+}
+
 // --- DiskSensor metadata tests ---
 
 func newDiskSensor(device string) *DiskSensor {
@@ -222,3 +275,101 @@ func TestDiskSensor_GetValue_DeviceNotFound(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to resolve")
 }
+
+// --- Standby handling tests ---
+
+func newDiskSensorAt(t *testing.T, sysBase string, standbyValue *float64) *DiskSensor {
+	t.Helper()
+	device := filepath.Join(t.TempDir(), "sda")
+	require.NoError(t, os.WriteFile(device, nil, 0o644))
+	return &DiskSensor{
+		Config: configuration.SensorConfig{
+			ID:   "disk_test",
+			Disk: &configuration.DiskSensorConfig{Device: device, StandbyValue: standbyValue},
+		},
+		sysBase: sysBase,
+	}
+}
+
+func TestParseAtaPowerMode(t *testing.T) {
+	assert.True(t, parseAtaPowerMode([]byte{0x50, 0x00, 0x00, 0x00}))
+	assert.False(t, parseAtaPowerMode([]byte{0x50, 0x00, 0xff, 0x00}))
+	assert.False(t, parseAtaPowerMode([]byte{0x50, 0x00, 0x80, 0x00}))
+	assert.False(t, parseAtaPowerMode([]byte{0x50}))
+}
+
+func TestIsRuntimeSuspendedAt(t *testing.T) {
+	tmp := t.TempDir()
+	writeTempFile(t, tmp, "class/block/sda/device/power/runtime_status", "suspended\n")
+	writeTempFile(t, tmp, "class/block/sdb/device/power/runtime_status", "active\n")
+
+	assert.True(t, isRuntimeSuspendedAt(tmp, "sda"))
+	assert.False(t, isRuntimeSuspendedAt(tmp, "sdb"))
+	assert.False(t, isRuntimeSuspendedAt(tmp, "sdc"))
+}
+
+func TestDiskSensor_GetValue_Active(t *testing.T) {
+	tmp := t.TempDir()
+	writeTempFile(t, tmp, "class/block/sda/device/hwmon/hwmon1/temp1_input", "38000\n")
+	s := newDiskSensorAt(t, tmp, nil)
+
+	temp, err := s.GetValue()
+	require.NoError(t, err)
+	assert.Equal(t, float64(38000), temp)
+	assert.False(t, s.InStandby)
+}
+
+func TestDiskSensor_GetValue_StandbyReportsLastValue(t *testing.T) {
+	tmp := t.TempDir()
+	writeTempFile(t, tmp, "class/block/sda/device/hwmon/hwmon1/temp1_input", "38000\n")
+	s := newDiskSensorAt(t, tmp, nil)
+	_, err := s.GetValue()
+	require.NoError(t, err)
+
+	writeTempFile(t, tmp, "class/block/sda/device/power/runtime_status", "suspended\n")
+	writeTempFile(t, tmp, "class/block/sda/device/hwmon/hwmon1/temp1_input", "99000\n")
+
+	temp, err := s.GetValue()
+	require.NoError(t, err)
+	assert.Equal(t, float64(38000), temp)
+	assert.True(t, s.InStandby)
+}
+
+func TestDiskSensor_GetValue_StandbyReportsStandbyValue(t *testing.T) {
+	tmp := t.TempDir()
+	writeTempFile(t, tmp, "class/block/sda/device/power/runtime_status", "suspended\n")
+	standbyValue := 25.0
+	s := newDiskSensorAt(t, tmp, &standbyValue)
+
+	temp, err := s.GetValue()
+	require.NoError(t, err)
+	assert.Equal(t, float64(25000), temp)
+}
+
+func TestDiskSensor_GetValue_StandbyWithoutKnownValue(t *testing.T) {
+	tmp := t.TempDir()
+	writeTempFile(t, tmp, "class/block/sda/device/power/runtime_status", "suspended\n")
+	s := newDiskSensorAt(t, tmp, nil)
+
+	_, err := s.GetValue()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is in standby")
+}
+
+func TestDiskSensor_GetValue_CachesResolvedDevice(t *testing.T) {
+	tmp := t.TempDir()
+	writeTempFile(t, tmp, "class/block/sda/device/hwmon/hwmon1/temp1_input", "38000\n")
+	s := newDiskSensorAt(t, tmp, nil)
+
+	_, err := s.GetValue()
+	require.NoError(t, err)
+	resolved := s.resolvedDevice
+	require.NotEmpty(t, resolved)
+
+	// removing the device node doesn't matter as long as reads succeed
+	require.NoError(t, os.Remove(s.Config.Disk.Device))
+	temp, err := s.GetValue()
+	require.NoError(t, err)
+	assert.Equal(t, float64(38000), temp)
+	assert.Equal(t, resolved, s.resolvedDevice)
+}
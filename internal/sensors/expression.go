@@ -0,0 +1,90 @@
+package sensors
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// ExpressionSensor computes its value from the moving averages of other sensors
+// using a formula like "max(cpu, gpu - 10)".
+type ExpressionSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+	FaultState
+
+	expression *util.Expression
+	registry   RegistryReader
+
+	mu sync.Mutex
+}
+
+func CreateExpressionSensor(config configuration.SensorConfig) (Sensor, error) {
+	expression, err := util.ParseExpression(config.Expression.Formula)
+	if err != nil {
+		return nil, fmt.Errorf("invalid formula '%s': %w", config.Expression.Formula, err)
+	}
+	return &ExpressionSensor{
+		Config:     config,
+		expression: expression,
+
+		mu: sync.Mutex{},
+	}, nil
+}
+
+func (sensor *ExpressionSensor) BindRegistry(registry RegistryReader) {
+	sensor.registry = registry
+}
+
+func (sensor *ExpressionSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *ExpressionSensor) GetLabel() string {
+	return "Expression Sensor " + sensor.Config.ID
+}
+
+func (sensor *ExpressionSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+// GetValue evaluates the formula of this sensor using the moving averages of the referenced sensors
+func (sensor *ExpressionSensor) GetValue() (float64, error) {
+	if sensor.registry == nil {
+		return 0, fmt.Errorf("no registry bound to expression sensor '%s'", sensor.Config.ID)
+	}
+
+	vars := map[string]float64{}
+	for _, sensorId := range sensor.expression.Variables() {
+		s, exists := sensor.registry.GetSensor(sensorId)
+		if !exists || s == nil {
+			return 0, fmt.Errorf("sensor %s: referenced sensor not found with id '%s'", sensor.Config.ID, sensorId)
+		}
+		if s.IsFaulted() {
+			return 0, fmt.Errorf("sensor %s: referenced sensor '%s': %w", sensor.Config.ID, sensorId, ErrSensorFaulted)
+		}
+		// formulas are written in the unit of the sensors (f.ex. degrees), sensor values are in milli-units
+		vars[sensorId] = s.GetMovingAvg() / configuration.UnitScale(s.GetConfig().GetUnit())
+	}
+
+	result, err := sensor.expression.Evaluate(vars)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %w", sensor.Config.ID, err)
+	}
+
+	return result * configuration.UnitScale(sensor.Config.GetUnit()), nil
+}
+
+func (sensor *ExpressionSensor) GetMovingAvg() (avg float64) {
+	sensor.mu.Lock()
+	defer sensor.mu.Unlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *ExpressionSensor) SetMovingAvg(avg float64) {
+	sensor.mu.Lock()
+	defer sensor.mu.Unlock()
+	sensor.MovingAvg = avg
+}
@@ -0,0 +1,113 @@
+package sensors
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRegistry struct {
+	sensors map[string]Sensor
+}
+
+func (r *mockRegistry) GetSensor(id string) (Sensor, bool) {
+	s, ok := r.sensors[id]
+	return s, ok
+}
+
+func createExpressionSensorWithRegistry(t *testing.T, formula string, sensors ...Sensor) Sensor {
+	reg := &mockRegistry{sensors: map[string]Sensor{}}
+	for _, s := range sensors {
+		reg.sensors[s.GetId()] = s
+	}
+
+	sensor, err := NewSensor(configuration.SensorConfig{
+		ID:         "expression",
+		Expression: &configuration.ExpressionSensorConfig{Formula: formula},
+	})
+	require.NoError(t, err)
+	sensor.(*ExpressionSensor).BindRegistry(reg)
+	return sensor
+}
+
+func TestExpressionSensor_GetValue(t *testing.T) {
+	// GIVEN
+	cpu := CreateSensor("cpu", configuration.HwMonSensorConfig{}, 60000)
+	gpu := CreateSensor("gpu", configuration.HwMonSensorConfig{}, 75000)
+	sensor := createExpressionSensorWithRegistry(t, "max(cpu, gpu - 10)", cpu, gpu)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 65000.0, value)
+}
+
+func TestExpressionSensor_GetValue_WeightedAverage(t *testing.T) {
+	// GIVEN
+	cpu := CreateSensor("cpu", configuration.HwMonSensorConfig{}, 60000)
+	ambient := CreateSensor("ambient", configuration.HwMonSensorConfig{}, 25000)
+	sensor := createExpressionSensorWithRegistry(t, "0.7*cpu + 0.3*ambient", cpu, ambient)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, err)
+	assert.InDelta(t, 49500.0, value, 0.0001)
+}
+
+func TestExpressionSensor_GetValue_MissingSensor(t *testing.T) {
+	// GIVEN
+	cpu := CreateSensor("cpu", configuration.HwMonSensorConfig{}, 60000)
+	sensor := createExpressionSensorWithRegistry(t, "max(cpu, gpu)", cpu)
+
+	// WHEN
+	_, err := sensor.GetValue()
+
+	// THEN
+	assert.EqualError(t, err, "sensor expression: referenced sensor not found with id 'gpu'")
+}
+
+func TestExpressionSensor_GetValue_FaultedSensor(t *testing.T) {
+	// GIVEN
+	cpu := CreateSensor("cpu", configuration.HwMonSensorConfig{}, 60000)
+	gpu := CreateSensor("gpu", configuration.HwMonSensorConfig{}, 75000)
+	gpu.SetFault("3 consecutive read errors")
+	sensor := createExpressionSensorWithRegistry(t, "max(cpu, gpu)", cpu, gpu)
+
+	// WHEN
+	_, err := sensor.GetValue()
+
+	// THEN
+	assert.ErrorIs(t, err, ErrSensorFaulted)
+}
+
+func TestExpressionSensor_GetValue_NoRegistry(t *testing.T) {
+	// GIVEN
+	sensor, err := NewSensor(configuration.SensorConfig{
+		ID:         "expression",
+		Expression: &configuration.ExpressionSensorConfig{Formula: "cpu"},
+	})
+	require.NoError(t, err)
+
+	// WHEN
+	_, err = sensor.GetValue()
+
+	// THEN
+	assert.EqualError(t, err, "no registry bound to expression sensor 'expression'")
+}
+
+func TestExpressionSensor_InvalidFormula(t *testing.T) {
+	// WHEN
+	_, err := NewSensor(configuration.SensorConfig{
+		ID:         "expression",
+		Expression: &configuration.ExpressionSensorConfig{Formula: "max(cpu"},
+	})
+
+	// THEN
+	assert.Error(t, err)
+}
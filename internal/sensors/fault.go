@@ -0,0 +1,37 @@
+package sensors
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrSensorFaulted is returned when a value is requested from a sensor that is currently faulted
+var ErrSensorFaulted = errors.New("sensor is faulted")
+
+// FaultState holds the fault status of a sensor, as determined by its sensor monitor.
+// It is embedded into sensor implementations to satisfy the fault related methods of the Sensor interface.
+type FaultState struct {
+	Faulted     bool   `json:"faulted"`
+	FaultReason string `json:"faultReason,omitempty"`
+
+	faultMu sync.RWMutex
+}
+
+func (s *FaultState) IsFaulted() bool {
+	s.faultMu.RLock()
+	defer s.faultMu.RUnlock()
+	return s.Faulted
+}
+
+func (s *FaultState) GetFaultReason() string {
+	s.faultMu.RLock()
+	defer s.faultMu.RUnlock()
+	return s.FaultReason
+}
+
+func (s *FaultState) SetFault(reason string) {
+	s.faultMu.Lock()
+	defer s.faultMu.Unlock()
+	s.Faulted = len(reason) > 0
+	s.FaultReason = reason
+}
@@ -13,6 +13,7 @@ import (
 type FileSensor struct {
 	Config    configuration.SensorConfig `json:"configuration"`
 	MovingAvg float64                    `json:"movingAvg"`
+	FaultState
 
 	mu sync.Mutex
 }
@@ -41,13 +42,12 @@ func (sensor *FileSensor) GetValue() (float64, error) {
 		filePath = filepath.Join(currentUser.HomeDir, filePath[1:])
 	}
 
-	integer, err := util.ReadIntFromFile(filePath)
+	result, err := util.ReadFloatFromFile(filePath)
 	if err != nil {
-		ui.Warning("Unable to read int from file sensor: %s", filePath)
+		ui.Warning("Unable to read value from file sensor: %s", filePath)
 		return 0, nil
 	}
 
-	result := float64(integer)
 	return result, nil
 }
 
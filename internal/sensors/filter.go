@@ -0,0 +1,88 @@
+package sensors
+
+import (
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// Filter smooths consecutive readings of a sensor
+type Filter interface {
+	// Apply computes the new filtered value of a sensor, based on the previously filtered value and a new reading
+	Apply(lastValue float64, value float64) float64
+}
+
+// NewFilter creates the filter configured for the given sensor.
+// defaultWindowSize is used for window based filters that don't specify a window size.
+func NewFilter(config *configuration.SensorFilterConfig, defaultWindowSize int) Filter {
+	switch {
+	case config == nil:
+		return &SmaFilter{WindowSize: defaultWindowSize}
+	case config.Ema != nil:
+		return &EmaFilter{Alpha: config.Ema.Alpha}
+	case config.Median != nil:
+		windowSize := config.Median.WindowSize
+		if windowSize <= 0 {
+			windowSize = defaultWindowSize
+		}
+		return &MedianFilter{WindowSize: windowSize}
+	case config.Kalman != nil:
+		return &KalmanFilter{
+			Config: util.KalmanConfig{
+				ProcessNoise:     config.Kalman.ProcessNoise,
+				MeasurementNoise: config.Kalman.MeasurementNoise,
+			},
+		}
+	case config.Sma != nil && config.Sma.WindowSize > 0:
+		return &SmaFilter{WindowSize: config.Sma.WindowSize}
+	default:
+		return &SmaFilter{WindowSize: defaultWindowSize}
+	}
+}
+
+// SmaFilter approximates a simple moving average over the last WindowSize readings
+type SmaFilter struct {
+	WindowSize int
+}
+
+func (f *SmaFilter) Apply(lastValue float64, value float64) float64 {
+	return util.UpdateSimpleMovingAvg(lastValue, max(f.WindowSize, 1), value)
+}
+
+// EmaFilter computes an exponential moving average, weighting a new reading with Alpha
+type EmaFilter struct {
+	Alpha float64
+}
+
+func (f *EmaFilter) Apply(lastValue float64, value float64) float64 {
+	return lastValue + f.Alpha*(value-lastValue)
+}
+
+// MedianFilter computes the median of the last WindowSize readings, which removes short spikes entirely
+type MedianFilter struct {
+	WindowSize int
+
+	window []float64
+}
+
+func (f *MedianFilter) Apply(_ float64, value float64) float64 {
+	f.window = append(f.window, value)
+	if len(f.window) > max(f.WindowSize, 1) {
+		f.window = f.window[len(f.window)-max(f.WindowSize, 1):]
+	}
+	return util.MedianFloat64(f.window)
+}
+
+// KalmanFilter smooths readings using a one-dimensional Kalman filter,
+// which is initialized with the previously filtered value on first use
+type KalmanFilter struct {
+	Config util.KalmanConfig
+
+	filter *util.KalmanFilter
+}
+
+func (f *KalmanFilter) Apply(lastValue float64, value float64) float64 {
+	if f.filter == nil {
+		f.filter = util.NewKalmanFilter(f.Config, lastValue)
+	}
+	return f.filter.Update(value)
+}
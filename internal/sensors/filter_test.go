@@ -0,0 +1,91 @@
+package sensors
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFilter_DefaultsToSma(t *testing.T) {
+	// WHEN
+	filter := NewFilter(nil, 10)
+
+	// THEN
+	assert.Equal(t, &SmaFilter{WindowSize: 10}, filter)
+}
+
+func TestNewFilter_WindowSizeFallsBackToDefault(t *testing.T) {
+	// GIVEN
+	config := &configuration.SensorFilterConfig{
+		Median: &configuration.MedianSensorFilterConfig{},
+	}
+
+	// WHEN
+	filter := NewFilter(config, 5)
+
+	// THEN
+	assert.Equal(t, &MedianFilter{WindowSize: 5}, filter)
+}
+
+func TestSmaFilter_Apply(t *testing.T) {
+	// GIVEN
+	filter := NewFilter(&configuration.SensorFilterConfig{
+		Sma: &configuration.SmaSensorFilterConfig{WindowSize: 4},
+	}, 10)
+
+	// WHEN
+	result := filter.Apply(40000, 48000)
+
+	// THEN
+	assert.Equal(t, 42000.0, result)
+}
+
+func TestEmaFilter_Apply(t *testing.T) {
+	// GIVEN
+	filter := NewFilter(&configuration.SensorFilterConfig{
+		Ema: &configuration.EmaSensorFilterConfig{Alpha: 0.25},
+	}, 10)
+
+	// WHEN
+	first := filter.Apply(40000, 48000)
+	second := filter.Apply(first, 48000)
+
+	// THEN
+	assert.Equal(t, 42000.0, first)
+	assert.Equal(t, 43500.0, second)
+}
+
+func TestMedianFilter_Apply_IgnoresSpikes(t *testing.T) {
+	// GIVEN
+	filter := NewFilter(&configuration.SensorFilterConfig{
+		Median: &configuration.MedianSensorFilterConfig{WindowSize: 3},
+	}, 10)
+
+	// WHEN
+	var results []float64
+	last := 0.0
+	for _, value := range []float64{40000, 41000, 95000, 42000, 43000} {
+		last = filter.Apply(last, value)
+		results = append(results, last)
+	}
+
+	// THEN
+	assert.Equal(t, []float64{40000, 40500, 41000, 42000, 43000}, results)
+}
+
+func TestKalmanFilter_Apply_StartsAtLastValue(t *testing.T) {
+	// GIVEN
+	filter := NewFilter(&configuration.SensorFilterConfig{
+		Kalman: &configuration.KalmanSensorFilterConfig{},
+	}, 10)
+
+	// WHEN
+	unchanged := filter.Apply(40000, 40000)
+	increased := filter.Apply(unchanged, 50000)
+
+	// THEN
+	assert.Equal(t, 40000.0, unchanged)
+	assert.Greater(t, increased, 40000.0)
+	assert.Less(t, increased, 50000.0)
+}
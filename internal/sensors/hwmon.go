@@ -11,11 +11,13 @@ type HwmonSensor struct {
 	Label     string                     `json:"label"`
 	Index     int                        `json:"index"`
 	Channel   int                        `json:"channel"`
+	Type      string                     `json:"type,omitempty"`
 	Input     string                     `json:"string"`
 	Max       int                        `json:"max"`
 	Min       int                        `json:"min"`
 	Config    configuration.SensorConfig `json:"configuration"`
 	MovingAvg float64                    `json:"movingAvg"`
+	FaultState
 
 	mu sync.Mutex
 }
@@ -29,15 +31,38 @@ func (sensor *HwmonSensor) GetLabel() string {
 }
 
 func (sensor *HwmonSensor) GetConfig() configuration.SensorConfig {
+	sensor.mu.Lock()
+	defer sensor.mu.Unlock()
 	return sensor.Config
 }
 
+// GetInput returns the sysfs path of the input of this sensor
+func (sensor *HwmonSensor) GetInput() string {
+	sensor.mu.Lock()
+	defer sensor.mu.Unlock()
+	return sensor.Input
+}
+
+// SetInput changes the sysfs path of the input of this sensor, f.ex. after its hwmon device was renumbered
+func (sensor *HwmonSensor) SetInput(input string) {
+	sensor.mu.Lock()
+	defer sensor.mu.Unlock()
+	hwMonConfig := *sensor.Config.HwMon
+	hwMonConfig.TempInput = input
+	sensor.Config.HwMon = &hwMonConfig
+	sensor.Input = input
+}
+
 func (sensor *HwmonSensor) GetValue() (result float64, err error) {
-	integer, err := util.ReadIntFromFile(sensor.Input)
+	integer, err := util.ReadIntFromFile(sensor.GetInput())
 	if err != nil {
 		return 0, err
 	}
 	result = float64(integer)
+	if sensor.Type == configuration.HwMonSensorTypePower {
+		// power inputs are reported in micro-watts, sensor values are in milli-units
+		result /= 1000
+	}
 	return result, err
 }
 
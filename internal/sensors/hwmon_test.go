@@ -0,0 +1,29 @@
+package sensors
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHwmonSensor_GetValue_PowerInMilliWatts(t *testing.T) {
+	// GIVEN
+	input := createTempInputs(t, "45250000")[0]
+	sensor, err := NewSensor(configuration.SensorConfig{
+		ID: "gpu_power",
+		HwMon: &configuration.HwMonSensorConfig{
+			Type:      configuration.HwMonSensorTypePower,
+			TempInput: input,
+		},
+	})
+	require.NoError(t, err)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 45250.0, value)
+}
@@ -20,6 +20,7 @@ type NvidiaSensor struct {
 	Min       int                        `json:"min"`
 	Config    configuration.SensorConfig `json:"configuration"`
 	MovingAvg float64                    `json:"movingAvg"`
+	FaultState
 
 	device         nvml.Device
 	nvidiaSensorId nvml.TemperatureSensors
@@ -0,0 +1,208 @@
+package sensors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+const raplSysBase = "/sys"
+
+// raplSample is a reading of the energy counter of a RAPL domain
+type raplSample struct {
+	time   time.Time
+	energy uint64
+}
+
+// findRaplDomain returns the sysfs path of the first powercap zone with the given name
+func findRaplDomain(sysBase string, domain string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(sysBase, "class", "powercap", "intel-rapl:*"))
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(matches)
+
+	for _, zonePath := range matches {
+		name, err := os.ReadFile(filepath.Join(zonePath, "name"))
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(string(name)) == domain {
+			return zonePath, nil
+		}
+	}
+	return "", fmt.Errorf("no RAPL domain with name '%s' found", domain)
+}
+
+func readUint64FromFile(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// computeRaplPower returns the average power between two samples in milli-watts,
+// taking an overflow of the energy counter into account
+func computeRaplPower(last raplSample, current raplSample, maxEnergyRange uint64) float64 {
+	seconds := current.time.Sub(last.time).Seconds()
+	if seconds <= 0 {
+		return 0
+	}
+	energy := current.energy - last.energy
+	if current.energy < last.energy {
+		energy = maxEnergyRange - last.energy + current.energy
+	}
+	// micro-joules per second = micro-watts
+	return float64(energy) / seconds / 1000
+}
+
+// RaplSensor reports the power draw of a RAPL (Running Average Power Limit) domain between the last two
+// samples in milli-watts
+type RaplSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+	FaultState
+
+	// sysBase is the sysfs mount point, "/sys" if empty
+	sysBase string
+	// zonePath is the resolved sysfs path of the domain
+	zonePath string
+	// last is the previous sample, nil until the baseline was taken
+	last *raplSample
+	// now returns the current time, replaceable for tests
+	now func() time.Time
+	// value and err are the result of the last sample
+	value float64
+	err   error
+
+	mu sync.Mutex
+	// sampleMu serializes samples
+	sampleMu sync.Mutex
+}
+
+// CreateRaplSensor creates a RaplSensor and takes the baseline sample, since the power is computed
+// from the difference between two readings of an energy counter
+func CreateRaplSensor(config configuration.SensorConfig) *RaplSensor {
+	sensor := &RaplSensor{
+		Config: config,
+		mu:     sync.Mutex{},
+	}
+	_ = sensor.Sample()
+	return sensor
+}
+
+func (s *RaplSensor) GetId() string {
+	return s.Config.ID
+}
+
+func (s *RaplSensor) GetLabel() string {
+	return fmt.Sprintf("RAPL (%s)", s.domain())
+}
+
+func (s *RaplSensor) GetConfig() configuration.SensorConfig {
+	return s.Config
+}
+
+// GetValue returns the average power draw computed by the last sample
+func (s *RaplSensor) GetValue() (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value, s.err
+}
+
+// Sample reads the energy counter and computes the average power draw since the previous sample.
+// The first sample only takes the baseline and results in a power draw of 0.
+func (s *RaplSensor) Sample() error {
+	s.sampleMu.Lock()
+	defer s.sampleMu.Unlock()
+
+	value, err := s.sample()
+	if err != nil {
+		err = fmt.Errorf("sensor %s: %w", s.Config.ID, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.value = value
+	s.err = err
+	return err
+}
+
+func (s *RaplSensor) sample() (float64, error) {
+	if len(s.zonePath) <= 0 {
+		sysBase := s.sysBase
+		if len(sysBase) <= 0 {
+			sysBase = raplSysBase
+		}
+		zonePath, err := findRaplDomain(sysBase, s.domain())
+		if err != nil {
+			return 0, err
+		}
+		s.zonePath = zonePath
+	}
+
+	if s.last != nil {
+		waitForMinSampleInterval(s.last.time, s.currentTime())
+	}
+
+	sample, err := s.readSample()
+	if err != nil {
+		// the domain might have disappeared, resolve it again on the next sample
+		s.zonePath = ""
+		s.last = nil
+		return 0, err
+	}
+	if s.last == nil {
+		s.last = &sample
+		return 0, nil
+	}
+	maxEnergyRange, err := readUint64FromFile(filepath.Join(s.zonePath, "max_energy_range_uj"))
+	if err != nil {
+		return 0, err
+	}
+
+	power := computeRaplPower(*s.last, sample, maxEnergyRange)
+	s.last = &sample
+	return power, nil
+}
+
+func (s *RaplSensor) currentTime() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+func (s *RaplSensor) readSample() (raplSample, error) {
+	energy, err := readUint64FromFile(filepath.Join(s.zonePath, "energy_uj"))
+	if err != nil {
+		return raplSample{}, err
+	}
+	return raplSample{time: s.currentTime(), energy: energy}, nil
+}
+
+func (s *RaplSensor) domain() string {
+	if len(s.Config.Rapl.Domain) <= 0 {
+		return "package-0"
+	}
+	return s.Config.Rapl.Domain
+}
+
+func (s *RaplSensor) GetMovingAvg() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.MovingAvg
+}
+
+func (s *RaplSensor) SetMovingAvg(avg float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MovingAvg = avg
+}
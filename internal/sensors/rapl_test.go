@@ -0,0 +1,96 @@
+package sensors
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createRaplDomain creates a fake /sys/class/powercap/<zone> directory below sysBase and returns its path
+func createRaplDomain(t *testing.T, sysBase string, zone string, name string, energy uint64) string {
+	zonePath := filepath.Join(sysBase, "class", "powercap", zone)
+	require.NoError(t, os.MkdirAll(zonePath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(zonePath, "name"), []byte(name+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(zonePath, "max_energy_range_uj"), []byte("262143328850\n"), 0644))
+	writeRaplEnergy(t, zonePath, energy)
+	return zonePath
+}
+
+func writeRaplEnergy(t *testing.T, zonePath string, energy uint64) {
+	require.NoError(t, os.WriteFile(filepath.Join(zonePath, "energy_uj"), []byte(strconv.FormatUint(energy, 10)+"\n"), 0644))
+}
+
+func TestFindRaplDomain(t *testing.T) {
+	// GIVEN
+	sysBase := t.TempDir()
+	createRaplDomain(t, sysBase, "intel-rapl:0", "package-0", 0)
+	createRaplDomain(t, sysBase, "intel-rapl:0:0", "core", 0)
+	createRaplDomain(t, sysBase, "intel-rapl:1", "package-1", 0)
+
+	// WHEN
+	core, errCore := findRaplDomain(sysBase, "core")
+	_, errMissing := findRaplDomain(sysBase, "dram")
+
+	// THEN
+	require.NoError(t, errCore)
+	assert.Equal(t, filepath.Join(sysBase, "class", "powercap", "intel-rapl:0:0"), core)
+	assert.EqualError(t, errMissing, "no RAPL domain with name 'dram' found")
+}
+
+func TestComputeRaplPower(t *testing.T) {
+	// GIVEN
+	start := time.Now()
+	last := raplSample{time: start, energy: 1_000_000}
+	current := raplSample{time: start.Add(2 * time.Second), energy: 51_000_000}
+	beforeOverflow := raplSample{time: start, energy: 262143328850 - 10_000_000}
+	afterOverflow := raplSample{time: start.Add(time.Second), energy: 15_000_000}
+
+	// WHEN
+	power := computeRaplPower(last, current, 262143328850)
+	overflowPower := computeRaplPower(beforeOverflow, afterOverflow, 262143328850)
+
+	// THEN
+	assert.InDelta(t, 25000.0, power, 0.0001)
+	assert.InDelta(t, 25000.0, overflowPower, 0.0001)
+}
+
+func TestRaplSensor_Sample(t *testing.T) {
+	// GIVEN
+	sysBase := t.TempDir()
+	zonePath := createRaplDomain(t, sysBase, "intel-rapl:0", "package-0", 1_000_000)
+
+	now := time.Now()
+	sensor := &RaplSensor{
+		Config: configuration.SensorConfig{
+			ID:   "cpu_power",
+			Rapl: &configuration.RaplSensorConfig{Domain: "package-0"},
+		},
+		sysBase: sysBase,
+		now:     func() time.Time { return now },
+	}
+
+	// WHEN
+	errBaseline := sensor.Sample()
+	baseline, _ := sensor.GetValue()
+	now = now.Add(time.Second)
+	writeRaplEnergy(t, zonePath, 66_000_000)
+	errSecond := sensor.Sample()
+	second, _ := sensor.GetValue()
+	// reading the value again doesn't start a new interval
+	now = now.Add(time.Millisecond)
+	again, errAgain := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, errBaseline)
+	assert.Equal(t, 0.0, baseline)
+	require.NoError(t, errSecond)
+	assert.InDelta(t, 65000.0, second, 0.0001)
+	require.NoError(t, errAgain)
+	assert.Equal(t, second, again)
+}
@@ -0,0 +1,178 @@
+package sensors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+// maxRemoteResponseSize limits the size of a response body read by a remote sensor
+const maxRemoteResponseSize = 1 << 20
+
+// RemoteSensor polls a value from a URL returning JSON, f.ex. the API of another fan2go instance
+type RemoteSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+	FaultState
+
+	client *http.Client
+	// lastValue is the last value received from the remote, used while it can't be reached
+	lastValue  float64
+	lastUpdate time.Time
+	// unreachable is true while the remote can't be reached, so it is only logged once
+	unreachable bool
+	// now returns the current time, replaceable for tests
+	now func() time.Time
+
+	mu sync.Mutex
+	// readMu serializes reads, since the sensor is read by the monitor as well as the statistics collector
+	readMu sync.Mutex
+}
+
+func CreateRemoteSensor(config configuration.SensorConfig) *RemoteSensor {
+	return &RemoteSensor{
+		Config: config,
+		client: &http.Client{
+			Timeout: config.Remote.Timeout,
+		},
+		mu: sync.Mutex{},
+	}
+}
+
+func (sensor *RemoteSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *RemoteSensor) GetLabel() string {
+	return fmt.Sprintf("Remote (%s)", sensor.Config.Remote.Url)
+}
+
+func (sensor *RemoteSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+// GetValue requests the current value from the remote. If the remote can't be reached, the last received
+// value is returned until it is older than the configured maxAge.
+func (sensor *RemoteSensor) GetValue() (float64, error) {
+	sensor.readMu.Lock()
+	defer sensor.readMu.Unlock()
+
+	now := time.Now
+	if sensor.now != nil {
+		now = sensor.now
+	}
+
+	value, err := sensor.fetch()
+	if errors.Is(err, ErrSensorFaulted) {
+		// the remote knows its value is bogus, don't fall back to an older one
+		return 0, fmt.Errorf("sensor %s: %w", sensor.Config.ID, err)
+	}
+	if err != nil {
+		age := now().Sub(sensor.lastUpdate)
+		if sensor.lastUpdate.IsZero() || age > sensor.Config.Remote.MaxAge {
+			return 0, fmt.Errorf("sensor %s: %w", sensor.Config.ID, err)
+		}
+		if !sensor.unreachable {
+			sensor.unreachable = true
+			ui.Warning("Sensor %s: %v, using the last value received %s ago for up to %s", sensor.Config.ID, err, age.Truncate(time.Millisecond), sensor.Config.Remote.MaxAge)
+		}
+		return sensor.lastValue, nil
+	}
+
+	if sensor.unreachable {
+		sensor.unreachable = false
+		ui.Info("Sensor %s: remote is reachable again", sensor.Config.ID)
+	}
+	sensor.lastValue = value
+	sensor.lastUpdate = now()
+	return value, nil
+}
+
+func (sensor *RemoteSensor) fetch() (float64, error) {
+	client := sensor.client
+	if client == nil {
+		client = &http.Client{Timeout: sensor.Config.Remote.Timeout}
+	}
+	response, err := client.Get(sensor.Config.Remote.Url)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected response status from %s: %s", sensor.Config.Remote.Url, response.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxRemoteResponseSize))
+	if err != nil {
+		return 0, err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return 0, fmt.Errorf("invalid JSON response from %s: %w", sensor.Config.Remote.Url, err)
+	}
+	// sensors of another fan2go instance report whether they are faulted
+	if object, ok := data.(map[string]interface{}); ok && object["faulted"] == true {
+		return 0, fmt.Errorf("remote %w: %v", ErrSensorFaulted, object["faultReason"])
+	}
+	return selectJsonValue(data, sensor.Config.Remote.JsonPath)
+}
+
+// selectJsonValue returns the number found at the given dot separated path, f.ex. "data.temps.0",
+// where numeric path elements select an element of an array
+func selectJsonValue(data interface{}, jsonPath string) (float64, error) {
+	current := data
+	if len(jsonPath) > 0 {
+		for _, element := range strings.Split(jsonPath, ".") {
+			switch node := current.(type) {
+			case map[string]interface{}:
+				value, ok := node[element]
+				if !ok {
+					return 0, fmt.Errorf("field '%s' of path '%s' not found", element, jsonPath)
+				}
+				current = value
+			case []interface{}:
+				index, err := strconv.Atoi(element)
+				if err != nil || index < 0 || index >= len(node) {
+					return 0, fmt.Errorf("invalid array index '%s' of path '%s'", element, jsonPath)
+				}
+				current = node[index]
+			default:
+				return 0, fmt.Errorf("element '%s' of path '%s' is neither an object nor an array", element, jsonPath)
+			}
+		}
+	}
+
+	switch value := current.(type) {
+	case float64:
+		return value, nil
+	case string:
+		if result, err := strconv.ParseFloat(value, 64); err == nil {
+			return result, nil
+		}
+	}
+	return 0, fmt.Errorf("value at path '%s' is not a number", jsonPath)
+}
+
+func (sensor *RemoteSensor) GetMovingAvg() (avg float64) {
+	sensor.mu.Lock()
+	defer sensor.mu.Unlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *RemoteSensor) SetMovingAvg(avg float64) {
+	sensor.mu.Lock()
+	defer sensor.mu.Unlock()
+	sensor.MovingAvg = avg
+}
@@ -0,0 +1,147 @@
+package sensors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createRemoteSensor creates a remote sensor polling the given server, with a manually advanced clock
+func createRemoteSensor(server *httptest.Server, jsonPath string) (*RemoteSensor, *time.Time) {
+	sensor := CreateRemoteSensor(configuration.SensorConfig{
+		ID: "remote_cpu",
+		Remote: &configuration.RemoteSensorConfig{
+			Url:      server.URL + "/sensor/cpu_package/",
+			JsonPath: jsonPath,
+			Timeout:  time.Second,
+			MaxAge:   30 * time.Second,
+		},
+	})
+	now := time.Now()
+	sensor.now = func() time.Time { return now }
+	return sensor, &now
+}
+
+func TestSelectJsonValue(t *testing.T) {
+	// GIVEN
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"data": {"temps": [41.5, "42.5"]}, "name": "cpu"}`), &data))
+
+	// WHEN
+	first, errFirst := selectJsonValue(data, "data.temps.0")
+	second, errSecond := selectJsonValue(data, "data.temps.1")
+	_, errMissing := selectJsonValue(data, "data.fans")
+	_, errIndex := selectJsonValue(data, "data.temps.2")
+	_, errType := selectJsonValue(data, "name")
+
+	// THEN
+	require.NoError(t, errFirst)
+	assert.Equal(t, 41.5, first)
+	require.NoError(t, errSecond)
+	assert.Equal(t, 42.5, second)
+	assert.EqualError(t, errMissing, "field 'fans' of path 'data.fans' not found")
+	assert.EqualError(t, errIndex, "invalid array index '2' of path 'data.temps.2'")
+	assert.EqualError(t, errType, "value at path 'name' is not a number")
+}
+
+func TestRemoteSensor_GetValue_Fan2goApi(t *testing.T) {
+	// GIVEN
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sensor/cpu_package/", r.URL.Path)
+		_, _ = w.Write([]byte(`{"configuration": {"id": "cpu_package"}, "movingAvg": 52000, "faulted": false}`))
+	}))
+	defer server.Close()
+	sensor, _ := createRemoteSensor(server, "movingAvg")
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 52000.0, value)
+}
+
+func TestRemoteSensor_GetValue_UsesLastValueUntilStale(t *testing.T) {
+	// GIVEN
+	available := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"movingAvg": 52000}`))
+	}))
+	defer server.Close()
+	sensor, now := createRemoteSensor(server, "movingAvg")
+	_, err := sensor.GetValue()
+	require.NoError(t, err)
+	available = false
+
+	// WHEN
+	*now = now.Add(20 * time.Second)
+	recent, errRecent := sensor.GetValue()
+	*now = now.Add(20 * time.Second)
+	_, errStale := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, errRecent)
+	assert.Equal(t, 52000.0, recent)
+	assert.ErrorContains(t, errStale, "sensor remote_cpu: unexpected response status from")
+	assert.True(t, sensor.unreachable)
+
+	// WHEN
+	available = true
+	recovered, errRecovered := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, errRecovered)
+	assert.Equal(t, 52000.0, recovered)
+	assert.False(t, sensor.unreachable)
+}
+
+func TestRemoteSensor_GetValue_Timeout(t *testing.T) {
+	// GIVEN
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"movingAvg": 52000}`))
+	}))
+	defer server.Close()
+	sensor, _ := createRemoteSensor(server, "movingAvg")
+	sensor.client.Timeout = 50 * time.Millisecond
+
+	// WHEN
+	_, err := sensor.GetValue()
+
+	// THEN
+	assert.ErrorContains(t, err, "sensor remote_cpu: ")
+}
+
+func TestRemoteSensor_GetValue_RemoteFaulted(t *testing.T) {
+	// GIVEN
+	faulted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if faulted {
+			_, _ = w.Write([]byte(`{"movingAvg": 52000, "faulted": true, "faultReason": "sensor reported invalid value 127.000"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"movingAvg": 52000}`))
+	}))
+	defer server.Close()
+	sensor, _ := createRemoteSensor(server, "movingAvg")
+	_, err := sensor.GetValue()
+	require.NoError(t, err)
+	faulted = true
+
+	// WHEN
+	_, err = sensor.GetValue()
+
+	// THEN
+	assert.ErrorIs(t, err, ErrSensorFaulted)
+	assert.EqualError(t, err, "sensor remote_cpu: remote sensor is faulted: sensor reported invalid value 127.000")
+}
@@ -1,7 +1,13 @@
 package sensors
 
 import (
+	"os"
+	"path/filepath"
+	"testing"
+
 	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func CreateSensor(
@@ -18,3 +24,60 @@ func CreateSensor(
 	}
 	return sensor
 }
+
+func TestReadValue_AppliesCorrection(t *testing.T) {
+	var tests = []struct {
+		tn       string
+		config   configuration.SensorConfig
+		raw      string
+		expected float64
+	}{{
+		tn:       "millicelsius",
+		config:   configuration.SensorConfig{},
+		raw:      "45000",
+		expected: 45000,
+	}, {
+		tn:       "celsius with offset",
+		config:   configuration.SensorConfig{InputUnit: configuration.SensorInputUnitCelsius, Offset: -5},
+		raw:      "45.5",
+		expected: 40500,
+	}, {
+		tn:       "fahrenheit",
+		config:   configuration.SensorConfig{InputUnit: configuration.SensorInputUnitFahrenheit},
+		raw:      "113",
+		expected: 45000,
+	}, {
+		tn:       "kelvin",
+		config:   configuration.SensorConfig{InputUnit: configuration.SensorInputUnitKelvin},
+		raw:      "318.15",
+		expected: 45000,
+	}, {
+		tn:       "scale",
+		config:   configuration.SensorConfig{Scale: 0.5, Offset: 2},
+		raw:      "90000",
+		expected: 47000,
+	}, {
+		tn:       "rpm offset",
+		config:   configuration.SensorConfig{Unit: configuration.SensorUnitRpm, Offset: 100},
+		raw:      "1200",
+		expected: 1300,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.tn, func(t *testing.T) {
+			// GIVEN
+			config := tt.config
+			config.ID = "sensor"
+			config.File = &configuration.FileSensorConfig{Path: filepath.Join(t.TempDir(), "value")}
+			require.NoError(t, os.WriteFile(config.File.Path, []byte(tt.raw+"\n"), 0644))
+			sensor, err := NewSensor(config)
+			require.NoError(t, err)
+
+			// WHEN
+			value, err := ReadValue(sensor)
+
+			// THEN
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expected, value, 0.001)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package sensors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+const thermalZoneSysBase = "/sys"
+
+// ThermalZone is a thermal zone exposed by the kernel under /sys/class/thermal
+type ThermalZone struct {
+	// Number is the N in thermal_zoneN, which is not stable across kernels or boots
+	Number int
+	// Type is the type string of the zone, f.ex. "x86_pkg_temp" or "acpitz"
+	Type string
+	// Path is the sysfs path of the zone directory
+	Path string
+}
+
+// GetThermalZones returns all thermal zones of the system, sorted by their number
+func GetThermalZones() []ThermalZone {
+	return getThermalZonesAt(thermalZoneSysBase)
+}
+
+func getThermalZonesAt(sysBase string) []ThermalZone {
+	matches, err := filepath.Glob(filepath.Join(sysBase, "class", "thermal", "thermal_zone*"))
+	if err != nil {
+		return nil
+	}
+
+	var zones []ThermalZone
+	for _, zonePath := range matches {
+		number, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(zonePath), "thermal_zone"))
+		if err != nil {
+			continue
+		}
+		zoneType, err := os.ReadFile(filepath.Join(zonePath, "type"))
+		if err != nil {
+			continue
+		}
+		zones = append(zones, ThermalZone{
+			Number: number,
+			Type:   strings.TrimSpace(string(zoneType)),
+			Path:   zonePath,
+		})
+	}
+
+	sort.Slice(zones, func(i, j int) bool {
+		return zones[i].Number < zones[j].Number
+	})
+	return zones
+}
+
+// GetValue returns the current temperature of this zone in milli-degrees
+func (z ThermalZone) GetValue() (float64, error) {
+	value, err := util.ReadIntFromFile(filepath.Join(z.Path, "temp"))
+	if err != nil {
+		return 0, err
+	}
+	return float64(value), nil
+}
+
+// findThermalZone returns the index-th (1-based) zone with the given type
+func findThermalZone(zones []ThermalZone, zoneType string, index int) (ThermalZone, error) {
+	if index <= 0 {
+		index = 1
+	}
+	count := 0
+	for _, zone := range zones {
+		if zone.Type != zoneType {
+			continue
+		}
+		count++
+		if count == index {
+			return zone, nil
+		}
+	}
+	if count == 0 {
+		return ThermalZone{}, fmt.Errorf("no thermal zone with type '%s' found", zoneType)
+	}
+	return ThermalZone{}, fmt.Errorf("only %d thermal zone(s) with type '%s' found, index %d requested", count, zoneType, index)
+}
+
+type ThermalZoneSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+	FaultState
+
+	sysBase string
+	// zone is resolved lazily by type, since zone numbers may change between boots
+	zone *ThermalZone
+
+	mu sync.Mutex
+}
+
+func (s *ThermalZoneSensor) GetId() string {
+	return s.Config.ID
+}
+
+func (s *ThermalZoneSensor) GetLabel() string {
+	return fmt.Sprintf("Thermal Zone (%s)", s.Config.ThermalZone.Type)
+}
+
+func (s *ThermalZoneSensor) GetConfig() configuration.SensorConfig {
+	return s.Config
+}
+
+func (s *ThermalZoneSensor) GetValue() (float64, error) {
+	zone, err := s.resolveZone()
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %w", s.Config.ID, err)
+	}
+
+	value, err := zone.GetValue()
+	if err != nil {
+		// the zone might have disappeared, resolve it again on the next read
+		s.mu.Lock()
+		s.zone = nil
+		s.mu.Unlock()
+		return 0, fmt.Errorf("sensor %s: %w", s.Config.ID, err)
+	}
+	return value, nil
+}
+
+func (s *ThermalZoneSensor) resolveZone() (ThermalZone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.zone != nil {
+		return *s.zone, nil
+	}
+
+	sysBase := s.sysBase
+	if len(sysBase) <= 0 {
+		sysBase = thermalZoneSysBase
+	}
+	zone, err := findThermalZone(getThermalZonesAt(sysBase), s.Config.ThermalZone.Type, s.Config.ThermalZone.Index)
+	if err != nil {
+		return ThermalZone{}, err
+	}
+	s.zone = &zone
+	return zone, nil
+}
+
+func (s *ThermalZoneSensor) GetMovingAvg() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.MovingAvg
+}
+
+func (s *ThermalZoneSensor) SetMovingAvg(avg float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MovingAvg = avg
+}
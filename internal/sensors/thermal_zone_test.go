@@ -0,0 +1,104 @@
+package sensors
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createThermalZone creates a fake /sys/class/thermal/thermal_zoneN directory below sysBase
+func createThermalZone(t *testing.T, sysBase string, number int, zoneType string, temp string) {
+	zonePath := filepath.Join(sysBase, "class", "thermal", "thermal_zone"+strconv.Itoa(number))
+	require.NoError(t, os.MkdirAll(zonePath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(zonePath, "type"), []byte(zoneType+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(zonePath, "temp"), []byte(temp+"\n"), 0644))
+}
+
+func TestGetThermalZones_SortedByNumber(t *testing.T) {
+	// GIVEN
+	sysBase := t.TempDir()
+	createThermalZone(t, sysBase, 10, "iwlwifi_1", "38000")
+	createThermalZone(t, sysBase, 2, "x86_pkg_temp", "52000")
+	createThermalZone(t, sysBase, 0, "acpitz", "27800")
+
+	// WHEN
+	zones := getThermalZonesAt(sysBase)
+
+	// THEN
+	require.Len(t, zones, 3)
+	assert.Equal(t, 0, zones[0].Number)
+	assert.Equal(t, "acpitz", zones[0].Type)
+	assert.Equal(t, 2, zones[1].Number)
+	assert.Equal(t, "x86_pkg_temp", zones[1].Type)
+	assert.Equal(t, 10, zones[2].Number)
+}
+
+func TestFindThermalZone_ByTypeAndIndex(t *testing.T) {
+	// GIVEN
+	zones := []ThermalZone{
+		{Number: 0, Type: "acpitz"},
+		{Number: 1, Type: "x86_pkg_temp"},
+		{Number: 2, Type: "acpitz"},
+	}
+
+	// WHEN
+	first, errFirst := findThermalZone(zones, "acpitz", 0)
+	second, errSecond := findThermalZone(zones, "acpitz", 2)
+	_, errMissing := findThermalZone(zones, "iwlwifi_1", 1)
+	_, errIndex := findThermalZone(zones, "acpitz", 3)
+
+	// THEN
+	require.NoError(t, errFirst)
+	assert.Equal(t, 0, first.Number)
+	require.NoError(t, errSecond)
+	assert.Equal(t, 2, second.Number)
+	assert.EqualError(t, errMissing, "no thermal zone with type 'iwlwifi_1' found")
+	assert.EqualError(t, errIndex, "only 2 thermal zone(s) with type 'acpitz' found, index 3 requested")
+}
+
+func TestThermalZoneSensor_GetValue(t *testing.T) {
+	// GIVEN
+	sysBase := t.TempDir()
+	createThermalZone(t, sysBase, 0, "acpitz", "27800")
+	createThermalZone(t, sysBase, 1, "x86_pkg_temp", "52000")
+
+	sensor := &ThermalZoneSensor{
+		Config: configuration.SensorConfig{
+			ID:          "cpu",
+			ThermalZone: &configuration.ThermalZoneSensorConfig{Type: "x86_pkg_temp"},
+		},
+		sysBase: sysBase,
+	}
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 52000.0, value)
+}
+
+func TestThermalZoneSensor_GetValue_ZoneNotFound(t *testing.T) {
+	// GIVEN
+	sysBase := t.TempDir()
+	createThermalZone(t, sysBase, 0, "acpitz", "27800")
+
+	sensor := &ThermalZoneSensor{
+		Config: configuration.SensorConfig{
+			ID:          "cpu",
+			ThermalZone: &configuration.ThermalZoneSensorConfig{Type: "x86_pkg_temp"},
+		},
+		sysBase: sysBase,
+	}
+
+	// WHEN
+	_, err := sensor.GetValue()
+
+	// THEN
+	assert.EqualError(t, err, "sensor cpu: no thermal zone with type 'x86_pkg_temp' found")
+}
@@ -13,6 +13,8 @@ type ControllerCollector struct {
 	unexpectedPwmValueCount *prometheus.Desc
 	increasedMinPwmCount    *prometheus.Desc
 	minPwmOffset            *prometheus.Desc
+	restartCount            *prometheus.Desc
+	curveDrift              *prometheus.Desc
 }
 
 func NewControllerCollector(controllers []controller.FanController) *ControllerCollector {
@@ -30,12 +32,22 @@ func NewControllerCollector(controllers []controller.FanController) *ControllerC
 			"Offset applied to the original minPwm of the fan due to a stalling fan",
 			[]string{"id"}, nil,
 		),
+		restartCount: prometheus.NewDesc(prometheus.BuildFQName(namespace, controllerSubsystem, "restart_count"),
+			"Counter for number of restarts of this controller after it panicked or stopped unexpectedly",
+			[]string{"id"}, nil,
+		),
+		curveDrift: prometheus.NewDesc(prometheus.BuildFQName(namespace, controllerSubsystem, "curve_drift"),
+			"Relative RPM loss of the refined fan curve data compared to the initial measurement",
+			[]string{"id"}, nil,
+		),
 	}
 }
 
 func (collector *ControllerCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- collector.unexpectedPwmValueCount
 	ch <- collector.increasedMinPwmCount
+	ch <- collector.restartCount
+	ch <- collector.curveDrift
 }
 
 // Collect implements required collect function for all prometheus collectors
@@ -47,6 +59,8 @@ func (collector *ControllerCollector) Collect(ch chan<- prometheus.Metric) {
 			ch <- prometheus.MustNewConstMetric(collector.unexpectedPwmValueCount, prometheus.CounterValue, float64(contr.GetStatistics().UnexpectedPwmValueCount), fanId)
 			ch <- prometheus.MustNewConstMetric(collector.increasedMinPwmCount, prometheus.CounterValue, float64(contr.GetStatistics().IncreasedMinPwmCount), fanId)
 			ch <- prometheus.MustNewConstMetric(collector.minPwmOffset, prometheus.GaugeValue, float64(contr.GetStatistics().MinPwmOffset), fanId)
+			ch <- prometheus.MustNewConstMetric(collector.restartCount, prometheus.CounterValue, float64(contr.GetStatistics().RestartCount), fanId)
+			ch <- prometheus.MustNewConstMetric(collector.curveDrift, prometheus.GaugeValue, contr.GetStatistics().CurveDrift, fanId)
 		}
 	}
 }
@@ -10,6 +10,7 @@ const subsystemSensor = "sensor"
 type SensorCollector struct {
 	sensors []sensors.Sensor
 	value   *prometheus.Desc
+	faulted *prometheus.Desc
 }
 
 func NewSensorCollector(sensors []sensors.Sensor) *SensorCollector {
@@ -19,18 +20,32 @@ func NewSensorCollector(sensors []sensors.Sensor) *SensorCollector {
 			"Current value of the sensor",
 			[]string{"id"}, nil,
 		),
+		faulted: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystemSensor, "faulted"),
+			"Whether the sensor is currently considered faulted (1) or not (0)",
+			[]string{"id"}, nil,
+		),
 	}
 }
 
 func (collector *SensorCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- collector.value
+	ch <- collector.faulted
 }
 
 // Collect implements required collect function for all prometheus collectors
 func (collector *SensorCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, sensor := range collector.sensors {
 		sensorId := sensor.GetId()
-		value, _ := sensor.GetValue()
-		ch <- prometheus.MustNewConstMetric(collector.value, prometheus.GaugeValue, value, sensorId)
+		// don't block the scrape waiting for a value, skip the sample if there is none
+		value, err := sensors.ReadLastValue(sensor)
+		if err == nil {
+			ch <- prometheus.MustNewConstMetric(collector.value, prometheus.GaugeValue, value, sensorId)
+		}
+
+		faulted := 0.0
+		if sensor.IsFaulted() {
+			faulted = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(collector.faulted, prometheus.GaugeValue, faulted, sensorId)
 	}
 }
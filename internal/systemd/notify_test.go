@@ -0,0 +1,80 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listenNotifySocket creates a unixgram socket and points NOTIFY_SOCKET to it
+func listenNotifySocket(t *testing.T) *net.UnixConn {
+	socketPath := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	t.Setenv("NOTIFY_SOCKET", socketPath)
+	return conn
+}
+
+func TestNotify(t *testing.T) {
+	// GIVEN
+	conn := listenNotifySocket(t)
+
+	// WHEN
+	sent, err := Notify(StateReady + "\n" + Status("Controlling 2 fans"))
+
+	// THEN
+	require.NoError(t, err)
+	assert.True(t, sent)
+	buf := make([]byte, 256)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "READY=1\nSTATUS=Controlling 2 fans", string(buf[:n]))
+}
+
+func TestNotify_NoSocket(t *testing.T) {
+	// GIVEN
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	// WHEN
+	sent, err := Notify(StateReady)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.False(t, sent)
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	// GIVEN
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+
+	// WHEN
+	interval, err := WatchdogInterval()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, interval)
+}
+
+func TestWatchdogInterval_OtherProcess(t *testing.T) {
+	// GIVEN
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+
+	// WHEN
+	interval, err := WatchdogInterval()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), interval)
+}
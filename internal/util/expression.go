@@ -0,0 +1,395 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression is a parsed arithmetic expression over named variables, f.ex. "max(cpu, gpu - 10)".
+//
+// Supported syntax:
+//   - numbers (f.ex. 10, 0.7, 1e3)
+//   - variables (letters, digits and '_', must not start with a digit)
+//   - binary operators +, -, *, / and unary -
+//   - parentheses
+//   - functions: min(a, b, ...), max(a, b, ...), avg(a, b, ...), abs(a), clamp(value, min, max)
+type Expression struct {
+	source string
+	root   expressionNode
+}
+
+type expressionNode interface {
+	evaluate(vars map[string]float64) (float64, error)
+	collectVariables(result map[string]struct{})
+}
+
+type expressionNumber float64
+
+type expressionVariable string
+
+type expressionUnary struct {
+	operand expressionNode
+}
+
+type expressionBinary struct {
+	operator byte
+	left     expressionNode
+	right    expressionNode
+}
+
+type expressionFunction struct {
+	name string
+	args []expressionNode
+}
+
+// expressionFunctionArity maps supported function names to their number of arguments (-1: at least one)
+var expressionFunctionArity = map[string]int{
+	"min":   -1,
+	"max":   -1,
+	"avg":   -1,
+	"abs":   1,
+	"clamp": 3,
+}
+
+// ParseExpression parses the given expression string
+func ParseExpression(source string) (*Expression, error) {
+	p := &expressionParser{source: source}
+	p.next()
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.token.kind != tokenEnd {
+		return nil, fmt.Errorf("unexpected '%s' at position %d", p.token.text, p.token.pos)
+	}
+	return &Expression{
+		source: source,
+		root:   root,
+	}, nil
+}
+
+// String returns the source string of this expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Variables returns the sorted, distinct names of all variables referenced in this expression
+func (e *Expression) Variables() []string {
+	set := map[string]struct{}{}
+	e.root.collectVariables(set)
+	var result []string
+	for name := range set {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Evaluate computes the value of this expression using the given variable values.
+// Returns an error if a referenced variable is missing or the result is not a finite number.
+func (e *Expression) Evaluate(vars map[string]float64) (float64, error) {
+	result, err := e.root.evaluate(vars)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, fmt.Errorf("expression '%s' did not evaluate to a finite number", e.source)
+	}
+	return result, nil
+}
+
+func (n expressionNumber) evaluate(map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+func (n expressionNumber) collectVariables(map[string]struct{}) {}
+
+func (n expressionVariable) evaluate(vars map[string]float64) (float64, error) {
+	value, ok := vars[string(n)]
+	if !ok {
+		return 0, fmt.Errorf("unknown variable '%s'", string(n))
+	}
+	return value, nil
+}
+
+func (n expressionVariable) collectVariables(result map[string]struct{}) {
+	result[string(n)] = struct{}{}
+}
+
+func (n expressionUnary) evaluate(vars map[string]float64) (float64, error) {
+	value, err := n.operand.evaluate(vars)
+	return -value, err
+}
+
+func (n expressionUnary) collectVariables(result map[string]struct{}) {
+	n.operand.collectVariables(result)
+}
+
+func (n expressionBinary) evaluate(vars map[string]float64) (float64, error) {
+	left, err := n.left.evaluate(vars)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.evaluate(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch n.operator {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	case '/':
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return left / right, nil
+	default:
+		return 0, fmt.Errorf("unknown operator '%c'", n.operator)
+	}
+}
+
+func (n expressionBinary) collectVariables(result map[string]struct{}) {
+	n.left.collectVariables(result)
+	n.right.collectVariables(result)
+}
+
+func (n expressionFunction) evaluate(vars map[string]float64) (float64, error) {
+	var values []float64
+	for _, arg := range n.args {
+		value, err := arg.evaluate(vars)
+		if err != nil {
+			return 0, err
+		}
+		values = append(values, value)
+	}
+	switch n.name {
+	case "min":
+		return MinValOrElse(values, values[0]), nil
+	case "max":
+		return MaxValOrElse(values, values[0]), nil
+	case "avg":
+		return Avg(values), nil
+	case "abs":
+		return math.Abs(values[0]), nil
+	case "clamp":
+		return Coerce(values[0], values[1], values[2]), nil
+	default:
+		return 0, fmt.Errorf("unknown function '%s'", n.name)
+	}
+}
+
+func (n expressionFunction) collectVariables(result map[string]struct{}) {
+	for _, arg := range n.args {
+		arg.collectVariables(result)
+	}
+}
+
+type expressionTokenKind int
+
+const (
+	tokenEnd expressionTokenKind = iota
+	tokenNumber
+	tokenIdentifier
+	tokenOperator
+	tokenInvalid
+)
+
+type expressionToken struct {
+	kind expressionTokenKind
+	text string
+	pos  int
+}
+
+type expressionParser struct {
+	source string
+	pos    int
+	token  expressionToken
+}
+
+// next advances the parser to the next token
+func (p *expressionParser) next() {
+	for p.pos < len(p.source) && unicode.IsSpace(rune(p.source[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.source) {
+		p.token = expressionToken{kind: tokenEnd, text: "end of expression", pos: start}
+		return
+	}
+
+	c := p.source[p.pos]
+	switch {
+	case isExpressionDigit(c) || c == '.':
+		for p.pos < len(p.source) && (isExpressionDigit(p.source[p.pos]) || p.source[p.pos] == '.') {
+			p.pos++
+		}
+		// scientific notation, f.ex. 1e3 or 2.5E-2
+		if p.pos < len(p.source) && (p.source[p.pos] == 'e' || p.source[p.pos] == 'E') {
+			end := p.pos + 1
+			if end < len(p.source) && (p.source[end] == '+' || p.source[end] == '-') {
+				end++
+			}
+			if end < len(p.source) && isExpressionDigit(p.source[end]) {
+				for end < len(p.source) && isExpressionDigit(p.source[end]) {
+					end++
+				}
+				p.pos = end
+			}
+		}
+		p.token = expressionToken{kind: tokenNumber, text: p.source[start:p.pos], pos: start}
+	case isExpressionLetter(c):
+		for p.pos < len(p.source) && (isExpressionLetter(p.source[p.pos]) || isExpressionDigit(p.source[p.pos])) {
+			p.pos++
+		}
+		p.token = expressionToken{kind: tokenIdentifier, text: p.source[start:p.pos], pos: start}
+	case strings.ContainsRune("+-*/(),", rune(c)):
+		p.pos++
+		p.token = expressionToken{kind: tokenOperator, text: string(c), pos: start}
+	default:
+		p.pos++
+		p.token = expressionToken{kind: tokenInvalid, text: string(c), pos: start}
+	}
+}
+
+func (p *expressionParser) isOperator(op string) bool {
+	return p.token.kind == tokenOperator && p.token.text == op
+}
+
+// parseSum parses: product (('+' | '-') product)*
+func (p *expressionParser) parseSum() (expressionNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+") || p.isOperator("-") {
+		operator := p.token.text[0]
+		p.next()
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = expressionBinary{operator: operator, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseProduct parses: unary (('*' | '/') unary)*
+func (p *expressionParser) parseProduct() (expressionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*") || p.isOperator("/") {
+		operator := p.token.text[0]
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = expressionBinary{operator: operator, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses: ('-' | '+') unary | primary
+func (p *expressionParser) parseUnary() (expressionNode, error) {
+	if p.isOperator("-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return expressionUnary{operand: operand}, nil
+	}
+	if p.isOperator("+") {
+		p.next()
+		return p.parseUnary()
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses: number | variable | function '(' args ')' | '(' sum ')'
+func (p *expressionParser) parsePrimary() (expressionNode, error) {
+	token := p.token
+	switch token.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s' at position %d", token.text, token.pos)
+		}
+		p.next()
+		return expressionNumber(value), nil
+	case tokenIdentifier:
+		p.next()
+		if !p.isOperator("(") {
+			return expressionVariable(token.text), nil
+		}
+		return p.parseFunction(token)
+	case tokenOperator:
+		if token.text == "(" {
+			p.next()
+			inner, err := p.parseSum()
+			if err != nil {
+				return nil, err
+			}
+			if !p.isOperator(")") {
+				return nil, fmt.Errorf("expected ')' at position %d", p.token.pos)
+			}
+			p.next()
+			return inner, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected '%s' at position %d", token.text, token.pos)
+}
+
+func (p *expressionParser) parseFunction(name expressionToken) (expressionNode, error) {
+	arity, ok := expressionFunctionArity[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function '%s' at position %d", name.text, name.pos)
+	}
+
+	// skip '('
+	p.next()
+	var args []expressionNode
+	if !p.isOperator(")") {
+		for {
+			arg, err := p.parseSum()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.isOperator(",") {
+				break
+			}
+			p.next()
+		}
+	}
+	if !p.isOperator(")") {
+		return nil, fmt.Errorf("expected ')' at position %d", p.token.pos)
+	}
+	p.next()
+
+	if arity < 0 && len(args) == 0 {
+		return nil, fmt.Errorf("function '%s' requires at least one argument", name.text)
+	}
+	if arity >= 0 && len(args) != arity {
+		return nil, fmt.Errorf("function '%s' requires exactly %d argument(s), got %d", name.text, arity, len(args))
+	}
+
+	return expressionFunction{name: name.text, args: args}, nil
+}
+
+func isExpressionDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isExpressionLetter(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
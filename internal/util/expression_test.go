@@ -0,0 +1,96 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpression_Evaluate(t *testing.T) {
+	vars := map[string]float64{
+		"cpu":     60,
+		"gpu":     75,
+		"ambient": 25,
+	}
+
+	expectedResults := map[string]float64{
+		"42":                      42,
+		"cpu":                     60,
+		"cpu + gpu":               135,
+		"cpu - gpu":               -15,
+		"2 * cpu + 1":             121,
+		"2 * (cpu + 1)":           122,
+		"cpu / 2":                 30,
+		"-cpu + 100":              40,
+		"max(cpu, gpu - 10)":      65,
+		"min(cpu, gpu, ambient)":  25,
+		"avg(cpu, gpu)":           67.5,
+		"abs(ambient - cpu)":      35,
+		"clamp(gpu, 30, 70)":      70,
+		"0.7*cpu + 0.3*ambient":   49.5,
+		"1e1 + 2.5E-1":            10.25,
+		"max(cpu, min(gpu, 70)) ": 70,
+	}
+
+	for source, expected := range expectedResults {
+		// WHEN
+		expression, err := ParseExpression(source)
+
+		// THEN
+		assert.NoError(t, err, source)
+		result, err := expression.Evaluate(vars)
+		assert.NoError(t, err, source)
+		assert.InDelta(t, expected, result, 0.000001, source)
+	}
+}
+
+func TestParseExpression_Variables(t *testing.T) {
+	// GIVEN
+	expression, err := ParseExpression("max(cpu, gpu - 10) + 0.1 * cpu + abs(ambient)")
+	assert.NoError(t, err)
+
+	// WHEN
+	result := expression.Variables()
+
+	// THEN
+	assert.Equal(t, []string{"ambient", "cpu", "gpu"}, result)
+}
+
+func TestParseExpression_Invalid(t *testing.T) {
+	expectedErrors := map[string]string{
+		"":               "unexpected 'end of expression' at position 0",
+		"cpu +":          "unexpected 'end of expression' at position 5",
+		"(cpu":           "expected ')' at position 4",
+		"cpu gpu":        "unexpected 'gpu' at position 4",
+		"foo(cpu)":       "unknown function 'foo' at position 0",
+		"max()":          "function 'max' requires at least one argument",
+		"abs(cpu, gpu)":  "function 'abs' requires exactly 1 argument(s), got 2",
+		"clamp(cpu, 10)": "function 'clamp' requires exactly 3 argument(s), got 2",
+		"cpu % 2":        "unexpected '%' at position 4",
+		"1.2.3":          "invalid number '1.2.3' at position 0",
+	}
+
+	for source, expected := range expectedErrors {
+		// WHEN
+		_, err := ParseExpression(source)
+
+		// THEN
+		assert.EqualError(t, err, expected, source)
+	}
+}
+
+func TestExpression_EvaluateErrors(t *testing.T) {
+	// GIVEN
+	missingVariable, _ := ParseExpression("cpu + gpu")
+	divisionByZero, _ := ParseExpression("cpu / (gpu - gpu)")
+	vars := map[string]float64{"cpu": 50}
+
+	// WHEN
+	_, errMissing := missingVariable.Evaluate(vars)
+	vars["gpu"] = 10
+	_, errDivision := divisionByZero.Evaluate(vars)
+
+	// THEN
+	assert.EqualError(t, errMissing, "unknown variable 'gpu'")
+	assert.EqualError(t, errDivision, "division by zero")
+}
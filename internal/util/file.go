@@ -67,6 +67,19 @@ func ReadIntFromFile(path string) (value int, err error) {
 	return value, err
 }
 
+// ReadFloatFromFile reads a single floating point number, f.ex. "45.5", from a file
+func ReadFloatFromFile(path string) (value float64, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return -1, err
+	}
+	text := strings.TrimSpace(string(data))
+	if len(text) <= 0 {
+		return -1, fmt.Errorf("file is empty: %s", path)
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
 // WriteIntToFile write a single integer to a file.go path
 func WriteIntToFile(value int, path string) error {
 	evaluatedPath, err := resolvePath(path)
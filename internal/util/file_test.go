@@ -3,6 +3,7 @@ package util
 import (
 	"github.com/stretchr/testify/assert"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -142,6 +143,19 @@ func TestReadIntFromFile_Success(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestReadFloatFromFile_Success(t *testing.T) {
+	// GIVEN
+	filePath := filepath.Join(t.TempDir(), "temp")
+	_ = os.WriteFile(filePath, []byte("45.5\n"), 0644)
+
+	// WHEN
+	result, err := ReadFloatFromFile(filePath)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 45.5, result)
+}
+
 func TestReadIntFromFile_FileNotFound(t *testing.T) {
 	// GIVEN
 	filePath := "../../not exists"
@@ -42,6 +42,13 @@ func NewPidLoop(p, i, d, min, max float64, antiWindup, antiWinddown bool) *PidLo
 	}
 }
 
+// SetOutputLimits changes the range of the output of the loop, f.ex. when the remaining headroom of
+// a value added to the output changes. The integral stops accumulating when the output hits these limits.
+func (p *PidLoop) SetOutputLimits(min, max float64) {
+	p.outMin = min
+	p.outMax = max
+}
+
 // Loop advances the pid loop
 func (p *PidLoop) Loop(target float64, measured float64) float64 {
 	initialized := !p.lastTime.IsZero()
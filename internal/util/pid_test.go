@@ -88,3 +88,24 @@ func TestPidLoop_D(t *testing.T) {
 	// THEN
 	assert.InDelta(t, 0, output, 0.01)
 }
+
+func TestPidLoop_SetOutputLimits(t *testing.T) {
+	// GIVEN
+	p, i, d := 1.0, 1.0, 0.0
+	pidLoop := NewPidLoop(p, i, d, -255, 255, true, true)
+
+	// WHEN
+	pidLoop.SetOutputLimits(-10, 10)
+	output := pidLoop.Loop(100.0, 0.0)
+	// THEN
+	assert.Equal(t, 10.0, output)
+
+	time.Sleep(10 * time.Millisecond)
+
+	// WHEN
+	output = pidLoop.Loop(100.0, 0.0)
+	// THEN
+	assert.Equal(t, 10.0, output)
+	// the output is saturated, so the error must not be integrated
+	assert.Equal(t, 0.0, pidLoop.integral)
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"time"
+)
+
+// MinSuspendDuration is the minimum difference between the wall clock and the monotonic clock
+// between two checks of a ResumeDetector, that is considered a suspend of the system
+const MinSuspendDuration = 5 * time.Second
+
+// ResumeDetector detects that the system resumed from suspend since its last check.
+// The monotonic clock doesn't advance while the system is suspended, so the wall clock
+// jumps ahead of it when the system resumes.
+type ResumeDetector struct {
+	// the wall clock time of the last check, without monotonic clock reading
+	lastWallTime time.Time
+	// the time of the last check, including the monotonic clock reading
+	lastMonotonicTime time.Time
+}
+
+func NewResumeDetector() *ResumeDetector {
+	now := time.Now()
+	return &ResumeDetector{
+		lastWallTime:      now.Round(0),
+		lastMonotonicTime: now,
+	}
+}
+
+// Check returns true and the (approximate) duration the system was suspended for,
+// if it resumed from suspend since the last call.
+func (d *ResumeDetector) Check() (time.Duration, bool) {
+	now := time.Now()
+	suspended := now.Round(0).Sub(d.lastWallTime) - now.Sub(d.lastMonotonicTime)
+	d.lastWallTime = now.Round(0)
+	d.lastMonotonicTime = now
+	if suspended < MinSuspendDuration {
+		return 0, false
+	}
+	return suspended, true
+}
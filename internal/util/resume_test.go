@@ -0,0 +1,35 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResumeDetector_NoSuspend(t *testing.T) {
+	// GIVEN
+	detector := NewResumeDetector()
+
+	// WHEN
+	_, resumed := detector.Check()
+
+	// THEN
+	assert.False(t, resumed)
+}
+
+func TestResumeDetector_Resumed(t *testing.T) {
+	// GIVEN
+	detector := NewResumeDetector()
+	// the wall clock advanced by an hour, while the monotonic clock didn't
+	detector.lastWallTime = detector.lastWallTime.Add(-time.Hour)
+
+	// WHEN
+	suspended, resumed := detector.Check()
+	_, resumedAgain := detector.Check()
+
+	// THEN
+	assert.True(t, resumed)
+	assert.InDelta(t, time.Hour, suspended, float64(time.Second))
+	assert.False(t, resumedAgain)
+}